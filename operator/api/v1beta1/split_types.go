@@ -17,16 +17,44 @@ limitations under the License.
 package v1beta1
 
 import (
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const (
 	SplitStateRunning SplitState = "Running"
 	SplitStateError   SplitState = "Error"
+
+	// SplitStageReady is the stage of the splits with all the pieces ready, the other stages are the piece the
+	// bring-up waits for
+	SplitStageReady SplitStage = "Ready"
+
+	RATLTE RAT = "LTE"
+	RATNR  RAT = "NR"
+
+	// SecurityProfilePrivileged runs the pieces as privileged containers, passing only the privileged pod security
+	// level
+	SecurityProfilePrivileged SecurityProfile = "Privileged"
+	// SecurityProfileNetAdmin drops all the capabilities but NET_ADMIN, with the default seccomp profile and a read
+	// only root filesystem. NET_ADMIN is rejected by the baseline level, so it also needs the privileged level.
+	SecurityProfileNetAdmin SecurityProfile = "NetAdmin"
+	// SecurityProfileRestricted passes the restricted pod security level: non root, no capabilities, the default
+	// seccomp profile and a read only root filesystem. The UE softmodem of the RU needs NET_ADMIN for its TUN device,
+	// so the RU runs with the net admin profile.
+	SecurityProfileRestricted SecurityProfile = "Restricted"
 )
 
 type SplitState string
 
+// SecurityProfile defines the security context of the split pieces
+type SecurityProfile string
+
+// SplitStage is the step of the chain bring-up
+type SplitStage string
+
+// RAT is the radio access technology deployed by a split
+type RAT string
+
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
@@ -38,13 +66,123 @@ type SplitSpec struct {
 	// CoreIP refers to the IP to establish communications with the Core
 	// +kubebuilder:validation:Required
 	CoreIP string `json:"coreIP,omitempty"`
+	// RAT selects the radio access technology. LTE deploys the eNB CU, DU and RU, NR deploys the gNB CU-CP, CU-UP,
+	// DU and RU. Defaults to LTE.
+	// +kubebuilder:validation:Enum=LTE;NR
+	RAT RAT `json:"rat,omitempty"`
+	// AMFIP refers to the AMF the NR CU-CP connects to through NGAP. CoreIP is used if empty.
+	AMFIP string `json:"amfIP,omitempty"`
+	// UPFIP refers to the UPF the NR CU-UP connects to through N3. CoreIP is used if empty.
+	UPFIP string `json:"upfIP,omitempty"`
+	// CoreNode refers to the topology core node serving the chain. A scheduler placing the split pieces only
+	// considers the paths from this core.
+	CoreNode string `json:"coreNode,omitempty"`
 
 	// RUNode refers to the node where the RU should be placed
 	RUNode string `json:"ruNode,omitempty"`
 	// DUNode refers to the node where the DU should be placed
 	DUNode string `json:"duNode,omitempty"`
-	// CUNode refers to the node where the CU should be placed
+	// CUNode refers to the node where the CU should be placed, the CU-CP in NR
 	CUNode string `json:"cuNode,omitempty"`
+	// CUUPNode refers to the node where the NR CU-UP should be placed
+	CUUPNode string `json:"cuupNode,omitempty"`
+	// SchedulerName refers to the scheduler that should place the split pieces. When set, the pods are not bound to
+	// the nodes above and the scheduler chooses them.
+	SchedulerName string `json:"schedulerName,omitempty"`
+	// Constraints add the node selector and the tolerations of each role to the pods of the split pieces
+	Constraints *PlacementConstraints `json:"constraints,omitempty"`
+	// Radio sets the cell broadcast by the split. If empty, the cell of the default templates is used.
+	Radio *Radio `json:"radio,omitempty"`
+	// SecurityProfile sets the privileges of the split pieces. Defaults to Privileged.
+	// +kubebuilder:validation:Enum=Privileged;NetAdmin;Restricted
+	SecurityProfile SecurityProfile `json:"securityProfile,omitempty"`
+	// Performance sets the performance profile of each piece. The pieces without a profile request the default
+	// burstable resources.
+	Performance *PerformanceProfiles `json:"performance,omitempty"`
+	// TemplateSet refers to the OAI templates used by the split pieces. If empty, the templates of the operator
+	// namespace are used.
+	TemplateSet *TemplateSetReference `json:"templateSet,omitempty"`
+}
+
+// PerformanceProfiles defines the performance profile of each split piece
+type PerformanceProfiles struct {
+	CU *PerformanceProfile `json:"cu,omitempty"`
+	// CUUP is the profile of the NR CU-UP
+	CUUP *PerformanceProfile `json:"cuup,omitempty"`
+	DU   *PerformanceProfile `json:"du,omitempty"`
+	RU   *PerformanceProfile `json:"ru,omitempty"`
+}
+
+// PerformanceProfile tunes the resources of a piece for real time processing. Requests and limits are the same, so
+// the pod has the Guaranteed QoS class and the static CPU manager of the node pins its CPUs.
+type PerformanceProfile struct {
+	// CPUs are the whole cores exclusive to the piece
+	// +kubebuilder:validation:Minimum=1
+	CPUs int64 `json:"cpus"`
+	// Memory of the piece. Defaults to 512Mi.
+	Memory *resource.Quantity `json:"memory,omitempty"`
+	// HugePages1Gi is the memory of the piece backed by 1Gi huge pages
+	HugePages1Gi *resource.Quantity `json:"hugePages1Gi,omitempty"`
+	// HugePages2Mi is the memory of the piece backed by 2Mi huge pages
+	HugePages2Mi *resource.Quantity `json:"hugePages2Mi,omitempty"`
+	// RuntimeClassName selects the runtime class of the piece pod, such as one running on real time kernel nodes
+	RuntimeClassName string `json:"runtimeClassName,omitempty"`
+}
+
+// TemplateSetReference refers to a versioned set of OAI templates. The set is the config map <name>-<version>, keeping
+// the template of each piece in the keys cu, cuup, du and ru.
+type TemplateSetReference struct {
+	// Name of the template set
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+	// Version of the template set
+	// +kubebuilder:validation:MinLength=1
+	Version string `json:"version"`
+	// Namespace of the template set config map, either the split namespace or the operator namespace. Defaults to the
+	// split namespace.
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// Radio defines the cell identity and carrier of a split
+type Radio struct {
+	// PLMNs broadcast by the cell
+	// +kubebuilder:validation:MinItems=1
+	PLMNs []PLMN `json:"plmns"`
+	// CellID identifies the eNB/gNB of the cell, it must be unique across the splits
+	// +kubebuilder:validation:Minimum=0
+	CellID int64 `json:"cellID"`
+	// PCI is the physical cell ID, up to 503 in LTE and 1007 in NR. Neighbour cells must use different PCIs.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=1007
+	// +optional
+	PCI int32 `json:"pci,omitempty"`
+	// TAC is the tracking area code, 0x0000 and 0xfffe are reserved values
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65533
+	TAC int32 `json:"tac"`
+	// Band is the E-UTRA band in LTE and the NR band in NR
+	// +kubebuilder:validation:Minimum=1
+	Band int32 `json:"band"`
+	// Bandwidth is the number of downlink resource blocks of the carrier
+	// +kubebuilder:validation:Minimum=6
+	// +kubebuilder:validation:Maximum=275
+	Bandwidth int32 `json:"bandwidth"`
+	// EARFCN is the downlink carrier, in NR it is the NR-ARFCN of the SSB
+	// +kubebuilder:validation:Minimum=0
+	EARFCN int64 `json:"earfcn"`
+	// TxGain is the transmission gain of the carrier, in dB
+	// +optional
+	TxGain int32 `json:"txGain,omitempty"`
+}
+
+// PLMN is a public land mobile network
+type PLMN struct {
+	// MCC is the mobile country code
+	// +kubebuilder:validation:Pattern=`^[0-9]{3}$`
+	MCC string `json:"mcc"`
+	// MNC is the mobile network code
+	// +kubebuilder:validation:Pattern=`^[0-9]{2,3}$`
+	MNC string `json:"mnc"`
 }
 
 // SplitStatus defines the observed state of Split
@@ -53,6 +191,10 @@ type SplitStatus struct {
 	CUNode string `json:"cuNode,omitempty"`
 	// CUIP refers to the IP of the CU pod
 	CUIP string `json:"cuIP,omitempty"`
+	// CUUPNode refers to the node where the NR CU-UP is placed
+	CUUPNode string `json:"cuupNode,omitempty"`
+	// CUUPIP refers to the IP of the NR CU-UP pod
+	CUUPIP string `json:"cuupIP,omitempty"`
 	// DUNode refers to the node where the DU is placed
 	DUNode string `json:"duNode,omitempty"`
 	// DUIP refers to the IP of the DU pod
@@ -63,6 +205,9 @@ type SplitStatus struct {
 	RUIP string `json:"ruIP,omitempty"`
 	// State shows the current state of the split according to the pods state
 	State SplitState `json:"state,omitempty"`
+	// Stage shows the piece the chain bring-up is blocked on: the pieces are started in the order CU, CU-UP, DU and
+	// RU, each one once the previous is ready. It is Ready when all the pieces are ready.
+	Stage SplitStage `json:"stage,omitempty"`
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
 }
@@ -73,6 +218,7 @@ type SplitStatus struct {
 // +kubebuilder:printcolumn:name="DU NODE",type=string,JSONPath=`.status.duNode`
 // +kubebuilder:printcolumn:name="RU NODE",type=string,JSONPath=`.status.ruNode`
 // +kubebuilder:printcolumn:name="STATUS",type=string,JSONPath=`.status.state`
+// +kubebuilder:printcolumn:name="STAGE",type=string,JSONPath=`.status.stage`
 // Split is the Schema for the splits API
 type Split struct {
 	metav1.TypeMeta   `json:",inline"`
@@ -17,12 +17,15 @@ limitations under the License.
 package v1beta1
 
 import (
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const (
 	PlacerStateFinished = "Finished"
 	PlacerStateError    = "Error"
+	PlacerStatePlanned  = "Planned"
 
 	RRC  DisaggregationProtocolStack = "RRC"
 	PDCP DisaggregationProtocolStack = "PDCP"
@@ -32,6 +35,13 @@ const (
 	MACL DisaggregationProtocolStack = "MACL"
 	PHYH DisaggregationProtocolStack = "PHYH"
 	PHYL DisaggregationProtocolStack = "PHYL"
+
+	CoreSelectionNearest     CoreSelection = "Nearest"
+	CoreSelectionLeastLoaded CoreSelection = "LeastLoaded"
+
+	PlacementRoleCU   PlacementRole = "cu"
+	PlacementRoleCUUP PlacementRole = "cuup"
+	PlacementRoleDU   PlacementRole = "du"
 )
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
@@ -40,6 +50,13 @@ const (
 type SplitsPlacerState string
 type DisaggregationProtocolStack string
 
+// CoreSelection defines how the core of each chain is chosen when the topology has several core nodes
+type CoreSelection string
+
+// PlacementRole is the piece of the chain placed by the splits placer
+// +kubebuilder:validation:Enum=cu;cuup;du
+type PlacementRole string
+
 // SplitsPlacerSpec defines the desired state of SplitsPlacer
 type SplitsPlacerSpec struct {
 	// INSERT ADDITIONAL SPEC FIELDS - desired state of cluster
@@ -48,13 +65,60 @@ type SplitsPlacerSpec struct {
 	// RUs
 	// +kubebuilder:validation:Required
 	RUs []*ChainPosition `json:"rus,omitempty"`
-	// CoreIP to where the splits created will point to.
-	// +kubebuilder:validation:Required
+	// CoreIP to where the splits created will point to when the core node chosen for the chain does not define its
+	// own coreIP in the topology.
 	CoreIP string `json:"coreIP,omitempty"`
+	// CoreSelection chooses the core of the chains not pinned to a core node. Nearest prefers the shortest path to
+	// the RU, LeastLoaded the core serving fewer chains. Defaults to Nearest.
+	// +kubebuilder:validation:Enum=Nearest;LeastLoaded
+	CoreSelection CoreSelection `json:"coreSelection,omitempty"`
+	// RAT of the splits created. NR chains place the CU-UP between the CU-CP and the DU, which requires the E1
+	// segment in the disaggregation. Defaults to LTE.
+	// +kubebuilder:validation:Enum=LTE;NR
+	RAT RAT `json:"rat,omitempty"`
+	// Constraints restrict the nodes eligible for each piece of the chains
+	Constraints *PlacementConstraints `json:"constraints,omitempty"`
+	// Performance sets the performance profile of the pieces of the splits created. The placement reserves the
+	// exclusive CPUs and huge pages of the profiles.
+	Performance *PerformanceProfiles `json:"performance,omitempty"`
+	// SecurityProfile sets the privileges of the splits created
+	// +kubebuilder:validation:Enum=Privileged;NetAdmin;Restricted
+	SecurityProfile SecurityProfile `json:"securityProfile,omitempty"`
+	// TemplateSet refers to the OAI templates used by the splits created
+	TemplateSet *TemplateSetReference `json:"templateSet,omitempty"`
 	// Topology refers to the config map name where the topology is described
 	TopologyConfig string `json:"topologyConfig,omitempty"`
 	// Retrigger placement
 	Retrigger bool `json:"retrigger,omitempty"`
+	// SchedulerName delegates the placement of the splits to the given scheduler instead of placing them in the
+	// reconcile.
+	SchedulerName string `json:"schedulerName,omitempty"`
+	// Preemption places the running chains in the priority order too, so chains of higher priority evict the ones of
+	// lower priority from the resources they need. The evicted chains are placed again in other nodes or left
+	// unallocated.
+	Preemption bool `json:"preemption,omitempty"`
+	// DryRun only computes the placement and writes it to the status, no split is created and no resource is
+	// reserved. Disabling it places the splits in the planned positions, or plans again and enables it back if the
+	// plan does not fit anymore.
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// PlacementConstraints defines the nodes eligible for each role of the chains
+type PlacementConstraints struct {
+	CU *RoleConstraints `json:"cu,omitempty"`
+	// CUUP constrains the NR CU-UP
+	CUUP *RoleConstraints `json:"cuup,omitempty"`
+	DU   *RoleConstraints `json:"du,omitempty"`
+	// AntiAffinity lists the roles whose pieces of different chains are never placed in the same node
+	AntiAffinity []PlacementRole `json:"antiAffinity,omitempty"`
+}
+
+// RoleConstraints defines the nodes eligible for one role
+type RoleConstraints struct {
+	// NodeSelector are the labels the nodes must have to host the role
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Tolerations of the role. The nodes with NoSchedule or NoExecute taints not tolerated are not eligible.
+	Tolerations []v1.Toleration `json:"tolerations,omitempty"`
 }
 
 // ChainPosition defines the position and the name of the RU from one service chain. Based on this definition a Split
@@ -62,14 +126,57 @@ type SplitsPlacerSpec struct {
 type ChainPosition struct {
 	SplitName string `json:"splitName,omitempty"`
 	RUNode    string `json:"ruNode,omitempty"`
-	// CUNode will be fulfilled by the split placer algorithm
+	// CoreNode pins the chain to one of the core nodes of the topology. If empty it will be fulfilled by the split
+	// placer algorithm
+	CoreNode string `json:"coreNode,omitempty"`
+	// CUNode will be fulfilled by the split placer algorithm. Use constraints to pin it.
 	CUNode string `json:"cuNode,omitempty"`
-	// DUNode will be fulfilled by the split placer algorithm
+	// CUUPNode will be fulfilled by the split placer algorithm in NR chains. Use constraints to pin it.
+	CUUPNode string `json:"cuupNode,omitempty"`
+	// DUNode will be fulfilled by the split placer algorithm. Use constraints to pin it.
 	DUNode string `json:"duNode,omitempty"`
 	// Path will be fulfilled by the split placer algorithm
 	Path []string `json:"path,omitempty"`
 	// Disaggregation will be fulfilled by the split placer algorithm
 	Disaggregation string `json:"disaggregation,omitempty"`
+	// Radio sets the cell of the split created for the RU
+	Radio *Radio `json:"radio,omitempty"`
+	// Constraints restrict the placement of the chain, the placer only chooses the pieces not pinned
+	Constraints *ChainConstraints `json:"constraints,omitempty"`
+	// Priority of the chain, the chains with higher priority are placed first. Defaults to 0.
+	Priority int32 `json:"priority,omitempty"`
+}
+
+// ChainConstraints defines the nodes and the network requirements of one chain
+type ChainConstraints struct {
+	// CUNode pins the CU, the CU-CP in NR, to the node. It must be the first node after the core in the path.
+	CUNode string `json:"cuNode,omitempty"`
+	// CUUPNode pins the NR CU-UP to the node
+	CUUPNode string `json:"cuupNode,omitempty"`
+	// DUNode pins the DU to the node
+	DUNode string `json:"duNode,omitempty"`
+	// AllowedNodes, if set, are the only nodes eligible for the pieces not pinned
+	AllowedNodes []string `json:"allowedNodes,omitempty"`
+	// ForbiddenNodes never host the pieces not pinned
+	ForbiddenNodes []string `json:"forbiddenNodes,omitempty"`
+	// MaxHops limits the links of the path from the core to the RU
+	// +kubebuilder:validation:Minimum=1
+	MaxHops int `json:"maxHops,omitempty"`
+	// Latency overrides the latency budget of the disaggregation segments for the chain
+	Latency *LatencyBudget `json:"latency,omitempty"`
+}
+
+// LatencyBudget defines the latency of each segment of a chain, in the unit of the topology link delays. The segments
+// without a value keep the budget of the disaggregation.
+type LatencyBudget struct {
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	Backhaul string `json:"backhaul,omitempty"`
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	Midhaul string `json:"midhaul,omitempty"`
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	E1 string `json:"e1,omitempty"`
+	// +kubebuilder:validation:Pattern=`^[0-9]+(\.[0-9]+)?$`
+	Fronthaul string `json:"fronthaul,omitempty"`
 }
 
 // SplitsPlacerStatus defines the observed state of SplitsPlacer
@@ -78,6 +185,91 @@ type SplitsPlacerStatus struct {
 	RemainingBandwidth map[string]string `json:"remainingBandwidth,omitempty"`
 	AllocatedRUs       int               `json:"allocatedRUs,omitempty"`
 	AllocationTime     string            `json:"allocationTime,omitempty"`
+	// Plan is the placement proposed in dry run mode
+	Plan *PlacementPlan `json:"plan,omitempty"`
+	// Explanations tells why each RU not allocated could not be placed
+	Explanations []*RUExplanation `json:"explanations,omitempty"`
+	// Evictions lists the chains evicted by chains of higher priority in the last placement
+	Evictions []*Eviction `json:"evictions,omitempty"`
+	// AllocationMilliseconds is the time taken by the last placement
+	AllocationMilliseconds int64 `json:"allocationMilliseconds,omitempty"`
+	// Links has the bandwidth of every link of the topology after the placement, sorted by name
+	Links []*LinkUtilisation `json:"links,omitempty"`
+	// Nodes has the resources reserved by the chains of the placer in each node, sorted by name
+	Nodes []*NodeUtilisation `json:"nodes,omitempty"`
+	// Hops summarises the hops from the core to the RU of the chains allocated
+	Hops *HopStatistics `json:"hops,omitempty"`
+	// Chains has the hops and the latency of each chain allocated
+	Chains []*ChainStatistics `json:"chains,omitempty"`
+}
+
+// LinkUtilisation defines the bandwidth of one link. Used includes the bandwidth reserved by other placements.
+type LinkUtilisation struct {
+	Name      string            `json:"name"`
+	Capacity  resource.Quantity `json:"capacity"`
+	Used      resource.Quantity `json:"used"`
+	Remaining resource.Quantity `json:"remaining"`
+}
+
+// NodeUtilisation defines the resources reserved in one node by the pieces of the placer
+type NodeUtilisation struct {
+	Name   string            `json:"name"`
+	CPU    resource.Quantity `json:"cpu"`
+	Memory resource.Quantity `json:"memory"`
+}
+
+// HopStatistics defines the number of links from the core to the RU of the chains allocated
+type HopStatistics struct {
+	Total   int32             `json:"total"`
+	Max     int32             `json:"max"`
+	Average resource.Quantity `json:"average"`
+}
+
+// ChainStatistics defines the hops and the latency of the segments of one chain allocated
+type ChainStatistics struct {
+	SplitName string          `json:"splitName"`
+	Hops      int32           `json:"hops"`
+	Latency   *SegmentLatency `json:"latency,omitempty"`
+}
+
+// SegmentLatency defines the latency in milliseconds of each segment of a chain. E1 is only set if the CU-UP is
+// split from the CU.
+type SegmentLatency struct {
+	Backhaul  *resource.Quantity `json:"backhaul,omitempty"`
+	Midhaul   *resource.Quantity `json:"midhaul,omitempty"`
+	E1        *resource.Quantity `json:"e1,omitempty"`
+	Fronthaul *resource.Quantity `json:"fronthaul,omitempty"`
+}
+
+// Eviction records a chain evicted to place a chain of higher priority
+type Eviction struct {
+	SplitName string `json:"splitName,omitempty"`
+	// PreemptedBy is the split name of the chain placed in its resources
+	PreemptedBy string `json:"preemptedBy,omitempty"`
+	// Reallocated is true if the evicted chain was placed again
+	Reallocated bool `json:"reallocated,omitempty"`
+}
+
+// RUExplanation lists the candidate paths of one RU and why each of them was rejected
+type RUExplanation struct {
+	SplitName  string           `json:"splitName,omitempty"`
+	RUNode     string           `json:"ruNode,omitempty"`
+	Rejections []*PathRejection `json:"rejections,omitempty"`
+}
+
+// PathRejection defines why the chain could not be placed in the path. The path is empty if the RU node is not
+// reachable from the core.
+type PathRejection struct {
+	Path   []string `json:"path,omitempty"`
+	Reason string   `json:"reason,omitempty"`
+}
+
+// PlacementPlan defines the placement the algorithm proposes for the RUs without applying it
+type PlacementPlan struct {
+	// Positions has the CU, DU and path proposed for each RU allocated
+	Positions []*ChainPosition `json:"positions,omitempty"`
+	// UnallocatedRUs has the name of the splits whose RU could not be allocated
+	UnallocatedRUs []string `json:"unallocatedRUs,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -106,10 +298,44 @@ type Topology struct {
 	Links map[string]*Link `json:"links,omitempty"`
 }
 
+// TopologyReservations is the ledger of the resources reserved in a topology by every placement. It is kept in the
+// topology config map so all the splits placers using the same topology share it.
+type TopologyReservations struct {
+	// Chains key is the chain reserving the resources, formatted as <namespace>/<splits placer>/<split name>
+	Chains map[string]*ChainReservation `json:"chains,omitempty"`
+}
+
+// ChainReservation defines the resources reserved by one service chain
+type ChainReservation struct {
+	// Core is the core node serving the chain
+	Core string `json:"core,omitempty"`
+	// Links key is the link name and the value the bandwidth reserved
+	Links map[string]float32 `json:"links,omitempty"`
+	// Nodes key is the node name
+	Nodes map[string]*NodeReservation `json:"nodes,omitempty"`
+}
+
+// NodeReservation defines the resources reserved by a chain in one node
+type NodeReservation struct {
+	// CPU is shared with the other pods of the node
+	CPU    resource.Quantity `json:"cpu,omitempty"`
+	Memory resource.Quantity `json:"memory,omitempty"`
+	// ExclusiveCPUs are whole cores pinned to the pieces, on top of the shared CPU
+	ExclusiveCPUs int64             `json:"exclusiveCPUs,omitempty"`
+	HugePages1Gi  resource.Quantity `json:"hugePages1Gi,omitempty"`
+	HugePages2Mi  resource.Quantity `json:"hugePages2Mi,omitempty"`
+}
+
 type Node struct {
 	Interfaces []string `json:"interfaces,omitempty"`
 	Core       bool     `json:"core,omitempty"`
-	Hops       int      `json:"hops,omitempty"`
+	// CoreIP is the address of the core network functions running in a core node
+	CoreIP string `json:"coreIP,omitempty"`
+	// AMFIP is the address of the AMF running in a core node, used by NR chains instead of coreIP
+	AMFIP string `json:"amfIP,omitempty"`
+	// UPFIP is the address of the UPF running in a core node, used by NR chains instead of coreIP
+	UPFIP string `json:"upfIP,omitempty"`
+	Hops  int    `json:"hops,omitempty"`
 }
 
 type Link struct {
@@ -128,7 +354,9 @@ type Disaggregation struct {
 	ProtocolStack ProtocolStack        `json:"protocolStack,omitempty"`
 	Backhaul      *NetworkRequirements `json:"backhaul,omitempty"`
 	Midhaul       *NetworkRequirements `json:"midhaul,omitempty"`
-	Fronthaul     *NetworkRequirements `json:"fronthaul,omitempty"`
+	// E1 is the segment between the CU-CP and the CU-UP of NR chains
+	E1        *NetworkRequirements `json:"e1,omitempty"`
+	Fronthaul *NetworkRequirements `json:"fronthaul,omitempty"`
 }
 
 type ProtocolStack struct {
@@ -21,9 +21,40 @@ limitations under the License.
 package v1beta1
 
 import (
+	"k8s.io/api/core/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChainConstraints) DeepCopyInto(out *ChainConstraints) {
+	*out = *in
+	if in.AllowedNodes != nil {
+		in, out := &in.AllowedNodes, &out.AllowedNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ForbiddenNodes != nil {
+		in, out := &in.ForbiddenNodes, &out.ForbiddenNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Latency != nil {
+		in, out := &in.Latency, &out.Latency
+		*out = new(LatencyBudget)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChainConstraints.
+func (in *ChainConstraints) DeepCopy() *ChainConstraints {
+	if in == nil {
+		return nil
+	}
+	out := new(ChainConstraints)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ChainPosition) DeepCopyInto(out *ChainPosition) {
 	*out = *in
@@ -32,6 +63,16 @@ func (in *ChainPosition) DeepCopyInto(out *ChainPosition) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Radio != nil {
+		in, out := &in.Radio, &out.Radio
+		*out = new(Radio)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Constraints != nil {
+		in, out := &in.Constraints, &out.Constraints
+		*out = new(ChainConstraints)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChainPosition.
@@ -44,6 +85,63 @@ func (in *ChainPosition) DeepCopy() *ChainPosition {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChainReservation) DeepCopyInto(out *ChainReservation) {
+	*out = *in
+	if in.Links != nil {
+		in, out := &in.Links, &out.Links
+		*out = make(map[string]float32, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make(map[string]*NodeReservation, len(*in))
+		for key, val := range *in {
+			var outVal *NodeReservation
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = new(NodeReservation)
+				(*in).DeepCopyInto(*out)
+			}
+			(*out)[key] = outVal
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChainReservation.
+func (in *ChainReservation) DeepCopy() *ChainReservation {
+	if in == nil {
+		return nil
+	}
+	out := new(ChainReservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChainStatistics) DeepCopyInto(out *ChainStatistics) {
+	*out = *in
+	if in.Latency != nil {
+		in, out := &in.Latency, &out.Latency
+		*out = new(SegmentLatency)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChainStatistics.
+func (in *ChainStatistics) DeepCopy() *ChainStatistics {
+	if in == nil {
+		return nil
+	}
+	out := new(ChainStatistics)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Connection) DeepCopyInto(out *Connection) {
 	*out = *in
@@ -73,6 +171,11 @@ func (in *Disaggregation) DeepCopyInto(out *Disaggregation) {
 		*out = new(NetworkRequirements)
 		**out = **in
 	}
+	if in.E1 != nil {
+		in, out := &in.E1, &out.E1
+		*out = new(NetworkRequirements)
+		**out = **in
+	}
 	if in.Fronthaul != nil {
 		in, out := &in.Fronthaul, &out.Fronthaul
 		*out = new(NetworkRequirements)
@@ -90,6 +193,52 @@ func (in *Disaggregation) DeepCopy() *Disaggregation {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Eviction) DeepCopyInto(out *Eviction) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Eviction.
+func (in *Eviction) DeepCopy() *Eviction {
+	if in == nil {
+		return nil
+	}
+	out := new(Eviction)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HopStatistics) DeepCopyInto(out *HopStatistics) {
+	*out = *in
+	out.Average = in.Average.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HopStatistics.
+func (in *HopStatistics) DeepCopy() *HopStatistics {
+	if in == nil {
+		return nil
+	}
+	out := new(HopStatistics)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LatencyBudget) DeepCopyInto(out *LatencyBudget) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LatencyBudget.
+func (in *LatencyBudget) DeepCopy() *LatencyBudget {
+	if in == nil {
+		return nil
+	}
+	out := new(LatencyBudget)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Link) DeepCopyInto(out *Link) {
 	*out = *in
@@ -107,6 +256,24 @@ func (in *Link) DeepCopy() *Link {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LinkUtilisation) DeepCopyInto(out *LinkUtilisation) {
+	*out = *in
+	out.Capacity = in.Capacity.DeepCopy()
+	out.Used = in.Used.DeepCopy()
+	out.Remaining = in.Remaining.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LinkUtilisation.
+func (in *LinkUtilisation) DeepCopy() *LinkUtilisation {
+	if in == nil {
+		return nil
+	}
+	out := new(LinkUtilisation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NetworkRequirements) DeepCopyInto(out *NetworkRequirements) {
 	*out = *in
@@ -142,6 +309,208 @@ func (in *Node) DeepCopy() *Node {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeReservation) DeepCopyInto(out *NodeReservation) {
+	*out = *in
+	out.CPU = in.CPU.DeepCopy()
+	out.Memory = in.Memory.DeepCopy()
+	out.HugePages1Gi = in.HugePages1Gi.DeepCopy()
+	out.HugePages2Mi = in.HugePages2Mi.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeReservation.
+func (in *NodeReservation) DeepCopy() *NodeReservation {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeReservation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeUtilisation) DeepCopyInto(out *NodeUtilisation) {
+	*out = *in
+	out.CPU = in.CPU.DeepCopy()
+	out.Memory = in.Memory.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeUtilisation.
+func (in *NodeUtilisation) DeepCopy() *NodeUtilisation {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeUtilisation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PLMN) DeepCopyInto(out *PLMN) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PLMN.
+func (in *PLMN) DeepCopy() *PLMN {
+	if in == nil {
+		return nil
+	}
+	out := new(PLMN)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PathRejection) DeepCopyInto(out *PathRejection) {
+	*out = *in
+	if in.Path != nil {
+		in, out := &in.Path, &out.Path
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PathRejection.
+func (in *PathRejection) DeepCopy() *PathRejection {
+	if in == nil {
+		return nil
+	}
+	out := new(PathRejection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PerformanceProfile) DeepCopyInto(out *PerformanceProfile) {
+	*out = *in
+	if in.Memory != nil {
+		in, out := &in.Memory, &out.Memory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.HugePages1Gi != nil {
+		in, out := &in.HugePages1Gi, &out.HugePages1Gi
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.HugePages2Mi != nil {
+		in, out := &in.HugePages2Mi, &out.HugePages2Mi
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PerformanceProfile.
+func (in *PerformanceProfile) DeepCopy() *PerformanceProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(PerformanceProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PerformanceProfiles) DeepCopyInto(out *PerformanceProfiles) {
+	*out = *in
+	if in.CU != nil {
+		in, out := &in.CU, &out.CU
+		*out = new(PerformanceProfile)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CUUP != nil {
+		in, out := &in.CUUP, &out.CUUP
+		*out = new(PerformanceProfile)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DU != nil {
+		in, out := &in.DU, &out.DU
+		*out = new(PerformanceProfile)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RU != nil {
+		in, out := &in.RU, &out.RU
+		*out = new(PerformanceProfile)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PerformanceProfiles.
+func (in *PerformanceProfiles) DeepCopy() *PerformanceProfiles {
+	if in == nil {
+		return nil
+	}
+	out := new(PerformanceProfiles)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlacementConstraints) DeepCopyInto(out *PlacementConstraints) {
+	*out = *in
+	if in.CU != nil {
+		in, out := &in.CU, &out.CU
+		*out = new(RoleConstraints)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CUUP != nil {
+		in, out := &in.CUUP, &out.CUUP
+		*out = new(RoleConstraints)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DU != nil {
+		in, out := &in.DU, &out.DU
+		*out = new(RoleConstraints)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AntiAffinity != nil {
+		in, out := &in.AntiAffinity, &out.AntiAffinity
+		*out = make([]PlacementRole, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlacementConstraints.
+func (in *PlacementConstraints) DeepCopy() *PlacementConstraints {
+	if in == nil {
+		return nil
+	}
+	out := new(PlacementConstraints)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PlacementPlan) DeepCopyInto(out *PlacementPlan) {
+	*out = *in
+	if in.Positions != nil {
+		in, out := &in.Positions, &out.Positions
+		*out = make([]*ChainPosition, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(ChainPosition)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.UnallocatedRUs != nil {
+		in, out := &in.UnallocatedRUs, &out.UnallocatedRUs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlacementPlan.
+func (in *PlacementPlan) DeepCopy() *PlacementPlan {
+	if in == nil {
+		return nil
+	}
+	out := new(PlacementPlan)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ProtocolStack) DeepCopyInto(out *ProtocolStack) {
 	*out = *in
@@ -172,12 +541,122 @@ func (in *ProtocolStack) DeepCopy() *ProtocolStack {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RUExplanation) DeepCopyInto(out *RUExplanation) {
+	*out = *in
+	if in.Rejections != nil {
+		in, out := &in.Rejections, &out.Rejections
+		*out = make([]*PathRejection, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(PathRejection)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RUExplanation.
+func (in *RUExplanation) DeepCopy() *RUExplanation {
+	if in == nil {
+		return nil
+	}
+	out := new(RUExplanation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Radio) DeepCopyInto(out *Radio) {
+	*out = *in
+	if in.PLMNs != nil {
+		in, out := &in.PLMNs, &out.PLMNs
+		*out = make([]PLMN, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Radio.
+func (in *Radio) DeepCopy() *Radio {
+	if in == nil {
+		return nil
+	}
+	out := new(Radio)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoleConstraints) DeepCopyInto(out *RoleConstraints) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoleConstraints.
+func (in *RoleConstraints) DeepCopy() *RoleConstraints {
+	if in == nil {
+		return nil
+	}
+	out := new(RoleConstraints)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SegmentLatency) DeepCopyInto(out *SegmentLatency) {
+	*out = *in
+	if in.Backhaul != nil {
+		in, out := &in.Backhaul, &out.Backhaul
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Midhaul != nil {
+		in, out := &in.Midhaul, &out.Midhaul
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.E1 != nil {
+		in, out := &in.E1, &out.E1
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Fronthaul != nil {
+		in, out := &in.Fronthaul, &out.Fronthaul
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SegmentLatency.
+func (in *SegmentLatency) DeepCopy() *SegmentLatency {
+	if in == nil {
+		return nil
+	}
+	out := new(SegmentLatency)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Split) DeepCopyInto(out *Split) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	out.Status = in.Status
 }
 
@@ -234,6 +713,26 @@ func (in *SplitList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SplitSpec) DeepCopyInto(out *SplitSpec) {
 	*out = *in
+	if in.Constraints != nil {
+		in, out := &in.Constraints, &out.Constraints
+		*out = new(PlacementConstraints)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Radio != nil {
+		in, out := &in.Radio, &out.Radio
+		*out = new(Radio)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Performance != nil {
+		in, out := &in.Performance, &out.Performance
+		*out = new(PerformanceProfiles)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TemplateSet != nil {
+		in, out := &in.TemplateSet, &out.TemplateSet
+		*out = new(TemplateSetReference)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SplitSpec.
@@ -334,6 +833,21 @@ func (in *SplitsPlacerSpec) DeepCopyInto(out *SplitsPlacerSpec) {
 			}
 		}
 	}
+	if in.Constraints != nil {
+		in, out := &in.Constraints, &out.Constraints
+		*out = new(PlacementConstraints)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Performance != nil {
+		in, out := &in.Performance, &out.Performance
+		*out = new(PerformanceProfiles)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TemplateSet != nil {
+		in, out := &in.TemplateSet, &out.TemplateSet
+		*out = new(TemplateSetReference)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SplitsPlacerSpec.
@@ -356,6 +870,71 @@ func (in *SplitsPlacerStatus) DeepCopyInto(out *SplitsPlacerStatus) {
 			(*out)[key] = val
 		}
 	}
+	if in.Plan != nil {
+		in, out := &in.Plan, &out.Plan
+		*out = new(PlacementPlan)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Explanations != nil {
+		in, out := &in.Explanations, &out.Explanations
+		*out = make([]*RUExplanation, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(RUExplanation)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.Evictions != nil {
+		in, out := &in.Evictions, &out.Evictions
+		*out = make([]*Eviction, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(Eviction)
+				**out = **in
+			}
+		}
+	}
+	if in.Links != nil {
+		in, out := &in.Links, &out.Links
+		*out = make([]*LinkUtilisation, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(LinkUtilisation)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]*NodeUtilisation, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(NodeUtilisation)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.Hops != nil {
+		in, out := &in.Hops, &out.Hops
+		*out = new(HopStatistics)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Chains != nil {
+		in, out := &in.Chains, &out.Chains
+		*out = make([]*ChainStatistics, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(ChainStatistics)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SplitsPlacerStatus.
@@ -368,6 +947,21 @@ func (in *SplitsPlacerStatus) DeepCopy() *SplitsPlacerStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateSetReference) DeepCopyInto(out *TemplateSetReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateSetReference.
+func (in *TemplateSetReference) DeepCopy() *TemplateSetReference {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateSetReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Topology) DeepCopyInto(out *Topology) {
 	*out = *in
@@ -412,3 +1006,33 @@ func (in *Topology) DeepCopy() *Topology {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopologyReservations) DeepCopyInto(out *TopologyReservations) {
+	*out = *in
+	if in.Chains != nil {
+		in, out := &in.Chains, &out.Chains
+		*out = make(map[string]*ChainReservation, len(*in))
+		for key, val := range *in {
+			var outVal *ChainReservation
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				in, out := &val, &outVal
+				*out = new(ChainReservation)
+				(*in).DeepCopyInto(*out)
+			}
+			(*out)[key] = outVal
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopologyReservations.
+func (in *TopologyReservations) DeepCopy() *TopologyReservations {
+	if in == nil {
+		return nil
+	}
+	out := new(TopologyReservations)
+	in.DeepCopyInto(out)
+	return out
+}
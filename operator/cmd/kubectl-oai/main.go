@@ -0,0 +1,146 @@
+/*
+Copyright 2020 Julio Renner.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	oaiv1beta1 "github.com/juliorenner/oai-k8s/operator/api/v1beta1"
+	"github.com/juliorenner/oai-k8s/operator/inspect"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const usage = `kubectl oai inspects the RAN chains placed by a splits placer.
+
+Usage:
+  kubectl oai [flags] chains <splits placer>        Chain of each RU from the RU to the core, with nodes and IPs.
+  kubectl oai [flags] utilisation <splits placer>   Bandwidth of the links and resources reserved in the nodes.
+  kubectl oai [flags] why <splits placer> [split]   Why the RUs were not placed and the chains evicted.
+  kubectl oai [flags] replace <splits placer>       Places the RUs again and moves their splits. -fresh forgets the paths.
+
+Flags:
+`
+
+type options struct {
+	kubeconfig string
+	context    string
+	namespace  string
+	fresh      bool
+}
+
+func main() {
+	opts := &options{}
+	flag.StringVar(&opts.kubeconfig, "kubeconfig", "", "Path to the kubeconfig file. The default loading rules "+
+		"are used if not set.")
+	flag.StringVar(&opts.context, "context", "", "Kubeconfig context to use.")
+	flag.StringVar(&opts.namespace, "n", "", "Namespace of the splits placer. The context namespace is used if not set.")
+	flag.BoolVar(&opts.fresh, "fresh", false, "Clear the positions of the RUs before replacing them.")
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if err := run(opts, flag.Args()); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(opts *options, args []string) error {
+	if len(args) < 2 {
+		flag.Usage()
+		return errors.New("a command and a splits placer are required")
+	}
+	command, name := args[0], args[1]
+	splitName := ""
+	if len(args) > 2 {
+		splitName = args[2]
+	}
+
+	c, namespace, err := newClient(opts)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	placement, err := inspect.GetPlacement(ctx, c, types.NamespacedName{Namespace: namespace, Name: name})
+	if err != nil {
+		return err
+	}
+
+	switch command {
+	case "chains":
+		return inspect.WriteChains(os.Stdout, placement)
+	case "utilisation":
+		return inspect.WriteUtilisation(os.Stdout, placement.SplitsPlacer)
+	case "why":
+		return inspect.WriteExplanations(os.Stdout, placement.SplitsPlacer, splitName)
+	case "replace":
+		if err := inspect.RequestReplacement(ctx, c, placement.SplitsPlacer, opts.fresh); err != nil {
+			return err
+		}
+		fmt.Printf("splits placer %s/%s will be placed again\n", namespace, name)
+		return nil
+	default:
+		return fmt.Errorf("unknown command '%s'", command)
+	}
+}
+
+// newClient returns a client for the cluster of the kubeconfig and the namespace to use
+func newClient(opts *options) (client.Client, string, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = opts.kubeconfig
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules,
+		&clientcmd.ConfigOverrides{CurrentContext: opts.context})
+
+	config, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, "", fmt.Errorf("error loading kubeconfig: %w", err)
+	}
+
+	namespace := opts.namespace
+	if namespace == "" {
+		if namespace, _, err = clientConfig.Namespace(); err != nil {
+			return nil, "", fmt.Errorf("error reading the context namespace: %w", err)
+		}
+	}
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		return nil, "", err
+	}
+	if err := oaiv1beta1.AddToScheme(scheme); err != nil {
+		return nil, "", err
+	}
+
+	c, err := client.New(config, client.Options{Scheme: scheme})
+	if err != nil {
+		return nil, "", fmt.Errorf("error creating client: %w", err)
+	}
+
+	return c, namespace, nil
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2020 Julio Renner.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"os"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	oaiv1beta1 "github.com/juliorenner/oai-k8s/operator/api/v1beta1"
+	"github.com/juliorenner/oai-k8s/operator/scheduler"
+)
+
+var (
+	scheme   = runtime.NewScheme()
+	setupLog = ctrl.Log.WithName("setup")
+)
+
+func init() {
+	_ = clientgoscheme.AddToScheme(scheme)
+
+	_ = oaiv1beta1.AddToScheme(scheme)
+}
+
+func main() {
+	var metricsAddr string
+	var schedulerName string
+	var enableLeaderElection bool
+	flag.StringVar(&metricsAddr, "metrics-addr", ":8081", "The address the metric endpoint binds to.")
+	flag.StringVar(&schedulerName, "scheduler-name", scheduler.Name,
+		"The schedulerName of the pods placed by this scheduler.")
+	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
+		"Enable leader election for the scheduler. "+
+			"Enabling this will ensure there is only one active scheduler.")
+	flag.Parse()
+
+	ctrl.SetLogger(zap.New(zap.UseDevMode(true)))
+
+	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+		Scheme:             scheme,
+		MetricsBindAddress: metricsAddr,
+		LeaderElection:     enableLeaderElection,
+		LeaderElectionID:   "scheduler.afbdf42b.unisinos",
+	})
+	if err != nil {
+		setupLog.Error(err, "unable to start manager")
+		os.Exit(1)
+	}
+
+	log := ctrl.Log.WithName("scheduler")
+	if err = (&scheduler.Binder{
+		Client:        mgr.GetClient(),
+		Log:           log,
+		Recorder:      mgr.GetEventRecorderFor(schedulerName),
+		SchedulerName: schedulerName,
+		Placement:     scheduler.NewRANPlacement(mgr.GetClient(), log.WithName("RANPlacement")),
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create binder")
+		os.Exit(1)
+	}
+
+	setupLog.Info("starting scheduler", "name", schedulerName)
+	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+		setupLog.Error(err, "problem running scheduler")
+		os.Exit(1)
+	}
+}
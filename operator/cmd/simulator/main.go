@@ -0,0 +1,157 @@
+/*
+Copyright 2020 Julio Renner.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/juliorenner/oai-k8s/operator/controllers"
+	"github.com/juliorenner/oai-k8s/operator/controllers/utils"
+	"github.com/juliorenner/oai-k8s/operator/simulator"
+	"k8s.io/apimachinery/pkg/api/resource"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+type options struct {
+	topologyPath        string
+	disaggregationsPath string
+	rusPath             string
+	nodesPath           string
+	nodeCPU             string
+	nodeMemory          string
+	splitCPU            string
+	splitMemory         string
+	strategies          string
+	executions          int
+	format              string
+	outputPath          string
+	verbose             bool
+}
+
+func main() {
+	opts := &options{}
+	flag.StringVar(&opts.topologyPath, "topology", "", "Topology JSON file or config map manifest.")
+	flag.StringVar(&opts.disaggregationsPath, "disaggregations", "config/oai/disaggregations.yaml",
+		"Disaggregation catalog JSON file or config map manifest.")
+	flag.StringVar(&opts.rusPath, "rus", "", "RU list JSON file or SplitsPlacer manifest.")
+	flag.StringVar(&opts.nodesPath, "nodes", "", "JSON file with the cpu and memory of each node, by node name. "+
+		"Nodes not described use the default capacity.")
+	flag.StringVar(&opts.nodeCPU, "node-cpu", "4", "Default CPU capacity of the nodes.")
+	flag.StringVar(&opts.nodeMemory, "node-memory", "8Gi", "Default memory capacity of the nodes.")
+	flag.StringVar(&opts.splitCPU, "split-cpu", controllers.SplitCPURequestValue, "CPU requested by each split piece.")
+	flag.StringVar(&opts.splitMemory, "split-memory", controllers.SplitMemoryRequestValue,
+		"Memory requested by each split piece.")
+	flag.StringVar(&opts.strategies, "strategies", "bfs", "Comma separated placement strategies to simulate.")
+	flag.IntVar(&opts.executions, "executions", 1, "Number of executions of each strategy.")
+	flag.StringVar(&opts.format, "format", "csv", "Output format, csv or json.")
+	flag.StringVar(&opts.outputPath, "output", "", "Output file. The standard output is used if not set.")
+	flag.BoolVar(&opts.verbose, "verbose", false, "Log the placement algorithm decisions.")
+	flag.Parse()
+
+	if err := run(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(opts *options) error {
+	if opts.topologyPath == "" || opts.rusPath == "" {
+		return errors.New("topology and rus are required")
+	}
+
+	topology, err := simulator.ReadTopology(opts.topologyPath)
+	if err != nil {
+		return err
+	}
+
+	disaggregations, err := simulator.ReadDisaggregations(opts.disaggregationsPath)
+	if err != nil {
+		return err
+	}
+
+	rus, err := simulator.ReadRUs(opts.rusPath)
+	if err != nil {
+		return err
+	}
+
+	nodes := map[string]*simulator.NodeCapacity{}
+	if opts.nodesPath != "" {
+		if nodes, err = simulator.ReadNodes(opts.nodesPath); err != nil {
+			return err
+		}
+	}
+
+	cpu, err := resource.ParseQuantity(opts.splitCPU)
+	if err != nil {
+		return fmt.Errorf("invalid split cpu: %w", err)
+	}
+	memory, err := resource.ParseQuantity(opts.splitMemory)
+	if err != nil {
+		return fmt.Errorf("invalid split memory: %w", err)
+	}
+
+	defaultCapacity := &simulator.NodeCapacity{CPU: opts.nodeCPU, Memory: opts.nodeMemory}
+	in := &simulator.Input{
+		Topology:           topology,
+		Disaggregations:    disaggregations,
+		RUs:                rus,
+		Nodes:              simulator.SyntheticNodes(topology, nodes, defaultCapacity),
+		RequestedResources: &utils.RequestedResources{CPU: cpu, Memory: memory},
+	}
+
+	var log logr.Logger = ctrllog.NullLogger{}
+	if opts.verbose {
+		log = zap.New(zap.UseDevMode(true), zap.WriteTo(os.Stderr))
+	}
+
+	var results []*simulator.Result
+	for _, strategy := range strings.Split(opts.strategies, ",") {
+		for n := 0; n < opts.executions; n++ {
+			result, err := simulator.Run(strings.TrimSpace(strategy), n, in, log)
+			if err != nil {
+				return err
+			}
+			results = append(results, result)
+		}
+	}
+
+	var output io.Writer = os.Stdout
+	if opts.outputPath != "" {
+		file, err := os.Create(opts.outputPath)
+		if err != nil {
+			return fmt.Errorf("error creating output file: %w", err)
+		}
+		defer file.Close()
+		output = file
+	}
+
+	switch opts.format {
+	case "csv":
+		return simulator.WriteCSV(output, results)
+	case "json":
+		return simulator.WriteJSON(output, results)
+	default:
+		return fmt.Errorf("invalid format '%s'", opts.format)
+	}
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2020 Julio Renner.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	oaiv1beta1 "github.com/juliorenner/oai-k8s/operator/api/v1beta1"
+	"github.com/juliorenner/oai-k8s/operator/controllers"
+	"github.com/juliorenner/oai-k8s/operator/controllers/utils"
+	"github.com/juliorenner/oai-k8s/operator/export"
+	"github.com/juliorenner/oai-k8s/operator/simulator"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+type options struct {
+	topologyPath        string
+	rusPath             string
+	disaggregationsPath string
+	place               bool
+	nodeCPU             string
+	nodeMemory          string
+	format              string
+	outputPath          string
+}
+
+func main() {
+	opts := &options{}
+	flag.StringVar(&opts.topologyPath, "topology", "", "Topology JSON file or config map manifest. The bandwidth "+
+		"reserved in the ledger of the config map is annotated as residual bandwidth.")
+	flag.StringVar(&opts.rusPath, "rus", "", "RU list JSON file or SplitsPlacer manifest with the chains to draw.")
+	flag.BoolVar(&opts.place, "place", false, "Place the RUs with the BFS placement before drawing them.")
+	flag.StringVar(&opts.disaggregationsPath, "disaggregations", "config/oai/disaggregations.yaml",
+		"Disaggregation catalog JSON file or config map manifest, used to place the RUs.")
+	flag.StringVar(&opts.nodeCPU, "node-cpu", "4", "CPU capacity of the nodes, used to place the RUs.")
+	flag.StringVar(&opts.nodeMemory, "node-memory", "8Gi", "Memory capacity of the nodes, used to place the RUs.")
+	flag.StringVar(&opts.format, "format", string(export.FormatDOT), "Output format, dot or graphml.")
+	flag.StringVar(&opts.outputPath, "output", "", "Output file. The standard output is used if not set.")
+	flag.Parse()
+
+	if err := run(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(opts *options) error {
+	if opts.topologyPath == "" {
+		return errors.New("topology is required")
+	}
+	if opts.place && opts.rusPath == "" {
+		return errors.New("rus are required to place them")
+	}
+
+	topology, err := simulator.ReadTopology(opts.topologyPath)
+	if err != nil {
+		return err
+	}
+
+	graph := &export.Graph{Topology: topology}
+	reservations, err := simulator.ReadReservations(opts.topologyPath)
+	if err != nil {
+		return err
+	}
+	if reservations != nil {
+		graph.Reserved = export.ReservedBandwidth(reservations)
+	}
+
+	if opts.rusPath != "" {
+		if graph.Chains, err = simulator.ReadRUs(opts.rusPath); err != nil {
+			return err
+		}
+	}
+
+	if opts.place {
+		if err := place(opts, graph); err != nil {
+			return err
+		}
+	}
+
+	var output io.Writer = os.Stdout
+	if opts.outputPath != "" {
+		file, err := os.Create(opts.outputPath)
+		if err != nil {
+			return fmt.Errorf("error creating output file: %w", err)
+		}
+		defer file.Close()
+		output = file
+	}
+
+	return export.Write(output, export.Format(opts.format), graph)
+}
+
+// place replaces the chains of the graph by the ones the BFS placement finds, and the bandwidth reserved by the one
+// they use
+func place(opts *options, graph *export.Graph) error {
+	disaggregations, err := simulator.ReadDisaggregations(opts.disaggregationsPath)
+	if err != nil {
+		return err
+	}
+
+	in := &simulator.Input{
+		Topology:        graph.Topology,
+		Disaggregations: disaggregations,
+		RUs:             graph.Chains,
+		Nodes: simulator.SyntheticNodes(graph.Topology, nil,
+			&simulator.NodeCapacity{CPU: opts.nodeCPU, Memory: opts.nodeMemory}),
+		RequestedResources: &utils.RequestedResources{
+			CPU:    *utils.NewQuantity(controllers.SplitCPURequestValue),
+			Memory: *utils.NewQuantity(controllers.SplitMemoryRequestValue),
+		},
+	}
+
+	result, err := simulator.Run("bfs", 0, in, ctrllog.NullLogger{})
+	if err != nil {
+		return err
+	}
+	if result.State == simulator.StateError {
+		return fmt.Errorf("error placing the RUs: %s", result.Error)
+	}
+
+	graph.Chains = nil
+	for _, ru := range result.RUs {
+		graph.Chains = append(graph.Chains, &oaiv1beta1.ChainPosition{SplitName: ru.SplitName, RUNode: ru.RUNode,
+			CoreNode: ru.CoreNode, CUNode: ru.CUNode, CUUPNode: ru.CUUPNode, DUNode: ru.DUNode, Path: ru.Path})
+	}
+
+	graph.Reserved = make(map[string]float32)
+	for _, link := range result.Links {
+		graph.Reserved[link.Name] = link.Capacity - link.Remaining
+	}
+
+	return nil
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2020 Julio Renner.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	oaiv1beta1 "github.com/juliorenner/oai-k8s/operator/api/v1beta1"
+	"github.com/juliorenner/oai-k8s/operator/generator"
+)
+
+const configMapTemplate = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: %s
+  namespace: %s
+  labels:
+    "topology": "true"
+data:
+  topology: |
+%s
+`
+
+type options struct {
+	generator.Options
+	shape      string
+	capacity   string
+	delay      string
+	format     string
+	name       string
+	namespace  string
+	outputPath string
+	rus        int
+	rusPath    string
+}
+
+func main() {
+	opts := &options{}
+	flag.StringVar(&opts.shape, "shape", string(generator.Tree), "Topology shape: tree, ring, mesh or fat-tree.")
+	flag.IntVar(&opts.Depth, "depth", 3, "Levels below the core in tree and fat-tree shapes.")
+	flag.IntVar(&opts.FanOut, "fan-out", 2, "Children of each node in tree and fat-tree shapes.")
+	flag.IntVar(&opts.Nodes, "nodes", 10, "Number of nodes, including the core, in ring and mesh shapes.")
+	flag.Float64Var(&opts.MeshDegree, "mesh-degree", 0.2, "Probability of linking each pair of nodes in the mesh shape.")
+	flag.StringVar(&opts.capacity, "capacity", "constant:1200", "Link capacity distribution: constant:<value>, "+
+		"uniform:<min>:<max> or normal:<mean>:<stddev>.")
+	flag.StringVar(&opts.delay, "delay", "uniform:0.25:1", "Link delay distribution, same format of capacity.")
+	flag.Int64Var(&opts.Seed, "seed", 1, "Seed of the random values.")
+	flag.StringVar(&opts.NodePrefix, "node-prefix", "node", "Prefix of the node names.")
+	flag.StringVar(&opts.format, "format", "json", "Output format, json or configmap.")
+	flag.StringVar(&opts.name, "name", "generated-topology", "Config map name when format is configmap.")
+	flag.StringVar(&opts.namespace, "namespace", "oai", "Config map namespace when format is configmap.")
+	flag.StringVar(&opts.outputPath, "output", "", "Output file. The standard output is used if not set.")
+	flag.IntVar(&opts.rus, "rus", 0, "Number of RUs to generate in the edge nodes.")
+	flag.StringVar(&opts.rusPath, "rus-output", "rus.json", "File where the generated RUs are written.")
+	flag.Parse()
+
+	if err := run(opts); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(opts *options) error {
+	var err error
+	opts.Shape = generator.Shape(opts.shape)
+	if opts.Capacity, err = generator.ParseDistribution(opts.capacity); err != nil {
+		return fmt.Errorf("invalid capacity: %w", err)
+	}
+	if opts.Delay, err = generator.ParseDistribution(opts.delay); err != nil {
+		return fmt.Errorf("invalid delay: %w", err)
+	}
+
+	topology, err := generator.Generate(&opts.Options)
+	if err != nil {
+		return fmt.Errorf("error generating topology: %w", err)
+	}
+
+	data, err := json.MarshalIndent(topology, "", "    ")
+	if err != nil {
+		return fmt.Errorf("error marshaling topology: %w", err)
+	}
+
+	switch opts.format {
+	case "json":
+	case "configmap":
+		data = []byte(fmt.Sprintf(configMapTemplate, opts.name, opts.namespace, indent(string(data), "    ")))
+	default:
+		return fmt.Errorf("invalid format '%s'", opts.format)
+	}
+
+	if err := write(opts.outputPath, data); err != nil {
+		return err
+	}
+
+	if opts.rus > 0 {
+		return writeRUs(topology, opts.rus, opts.rusPath)
+	}
+
+	return nil
+}
+
+// writeRUs spreads the RUs over the edge nodes of the topology
+func writeRUs(topology *oaiv1beta1.Topology, count int, path string) error {
+	edgeNodes := generator.EdgeNodes(topology)
+	if len(edgeNodes) == 0 {
+		return errors.New("topology has no node far enough from the core to host RUs")
+	}
+
+	rus := make([]*oaiv1beta1.ChainPosition, count)
+	for i := range rus {
+		rus[i] = &oaiv1beta1.ChainPosition{
+			SplitName: strconv.Itoa(i + 1),
+			RUNode:    edgeNodes[i%len(edgeNodes)],
+		}
+	}
+
+	data, err := json.MarshalIndent(rus, "", "    ")
+	if err != nil {
+		return fmt.Errorf("error marshaling RUs: %w", err)
+	}
+
+	return write(path, data)
+}
+
+func write(path string, data []byte) error {
+	if path == "" {
+		_, err := os.Stdout.Write(append(data, '\n'))
+		return err
+	}
+
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("error writing '%s': %w", path, err)
+	}
+
+	return nil
+}
+
+func indent(value string, prefix string) string {
+	lines := strings.Split(value, "\n")
+	for i, line := range lines {
+		lines[i] = prefix + line
+	}
+
+	return strings.Join(lines, "\n")
+}
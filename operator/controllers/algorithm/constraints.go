@@ -0,0 +1,122 @@
+package algorithm
+
+import (
+	"fmt"
+	"strings"
+
+	oaiv1beta1 "github.com/juliorenner/oai-k8s/operator/api/v1beta1"
+	"github.com/juliorenner/oai-k8s/operator/controllers/utils"
+	v1 "k8s.io/api/core/v1"
+)
+
+// constraints enforces the node selectors, tolerations and anti-affinity of the roles of the chains
+type constraints struct {
+	placement *oaiv1beta1.PlacementConstraints
+	// piecesNodes key is the piece, the value counts the chains with the piece in each node
+	piecesNodes map[string]map[string]int
+}
+
+func newConstraints() *constraints {
+	return &constraints{piecesNodes: make(map[string]map[string]int)}
+}
+
+// rejection returns why the node is not eligible for the piece, empty if it is eligible
+func (c *constraints) rejection(piece string, node *utils.Node) string {
+	name := strings.ToUpper(piece)
+	if role := c.role(piece); role != nil {
+		for key, value := range role.NodeSelector {
+			if nodeValue, exists := node.Labels[key]; !exists || nodeValue != value {
+				return fmt.Sprintf("%s node '%s' does not match the node selector %s=%s", name, node.NodeName, key,
+					value)
+			}
+		}
+
+		for i := range node.Taints {
+			if taint := &node.Taints[i]; !tolerates(role.Tolerations, taint) {
+				return fmt.Sprintf("%s node '%s' has the taint %s not tolerated", name, node.NodeName,
+					taint.ToString())
+			}
+		}
+	} else if taint := firstRepellingTaint(node.Taints); taint != nil {
+		return fmt.Sprintf("%s node '%s' has the taint %s not tolerated", name, node.NodeName, taint.ToString())
+	}
+
+	if c.antiAffinity(piece) && c.piecesNodes[piece][node.NodeName] > 0 {
+		return fmt.Sprintf("%s node '%s' already hosts the %s of another chain", name, node.NodeName, name)
+	}
+
+	return ""
+}
+
+// add records the piece of one chain placed in the node
+func (c *constraints) add(piece, nodeName string) {
+	if _, exists := c.piecesNodes[piece]; !exists {
+		c.piecesNodes[piece] = make(map[string]int)
+	}
+	c.piecesNodes[piece][nodeName]++
+}
+
+// remove forgets the piece of one chain placed in the node
+func (c *constraints) remove(piece, nodeName string) {
+	if c.piecesNodes[piece][nodeName] <= 1 {
+		delete(c.piecesNodes[piece], nodeName)
+		return
+	}
+	c.piecesNodes[piece][nodeName]--
+}
+
+func (c *constraints) role(piece string) *oaiv1beta1.RoleConstraints {
+	if c.placement == nil {
+		return nil
+	}
+
+	switch piece {
+	case PieceCU:
+		return c.placement.CU
+	case PieceCUUP:
+		return c.placement.CUUP
+	case PieceDU:
+		return c.placement.DU
+	}
+
+	return nil
+}
+
+func (c *constraints) antiAffinity(piece string) bool {
+	if c.placement == nil {
+		return false
+	}
+
+	for _, role := range c.placement.AntiAffinity {
+		if string(role) == piece {
+			return true
+		}
+	}
+
+	return false
+}
+
+// tolerates returns true if the taint does not repel pods or one of the tolerations tolerates it
+func tolerates(tolerations []v1.Toleration, taint *v1.Taint) bool {
+	if taint.Effect == v1.TaintEffectPreferNoSchedule {
+		return true
+	}
+
+	for i := range tolerations {
+		if tolerations[i].ToleratesTaint(taint) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func firstRepellingTaint(taints []v1.Taint) *v1.Taint {
+	for i := range taints {
+		if !tolerates(nil, &taints[i]) {
+			return &taints[i]
+		}
+	}
+
+	return nil
+}
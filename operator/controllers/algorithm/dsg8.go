@@ -3,56 +3,92 @@ package algorithm
 import (
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 
-	pkgqueue "github.com/Workiva/go-datastructures/queue"
 	"github.com/go-logr/logr"
 	oaiv1beta1 "github.com/juliorenner/oai-k8s/operator/api/v1beta1"
 	"github.com/juliorenner/oai-k8s/operator/controllers/utils"
 )
 
 type disaggregation8 struct {
-	nodes               map[string]*utils.Node
-	requestedResources  *utils.RequestedResources
+	key                string
+	nodes              map[string]*utils.Node
+	requestedResources *utils.RequestedResources
+	// piecesResources key is the piece whose requested resources differ from the default ones
+	piecesResources     map[string]*utils.RequestedResources
+	constraints         *constraints
 	networkRequirements *oaiv1beta1.Disaggregation
 	log                 logr.Logger
 }
 
-func NewDsg8(nodes map[string]*utils.Node, requestedResources *utils.RequestedResources,
+func NewDsg8(key string, nodes map[string]*utils.Node, requestedResources *utils.RequestedResources,
 	networkRequirements *oaiv1beta1.Disaggregation, log logr.Logger) *disaggregation8 {
 	return &disaggregation8{
+		key:                 key,
 		nodes:               nodes,
 		requestedResources:  requestedResources,
 		networkRequirements: networkRequirements,
+		constraints:         newConstraints(),
 		log:                 log,
 	}
 }
 
-func (d *disaggregation8) Validate(ru *oaiv1beta1.ChainPosition, paths [][]string) (bool, *position) {
+// Validate returns the first position of the chain that fulfills the nodes and network requirements. If there is
+// none, the reason each path was rejected is returned.
+func (d *disaggregation8) Validate(ru *oaiv1beta1.ChainPosition, paths [][]string) (bool, *position,
+	[]*oaiv1beta1.PathRejection) {
 	validation := &pathsValidation{
 		paths:     paths,
 		ruNode:    ru.RUNode,
 		positions: map[int]*position{},
 	}
 
+	if len(paths) == 0 {
+		return false, nil, []*oaiv1beta1.PathRejection{{Reason: "no path from the core to the RU node"}}
+	}
+
+	rejections := make([]*oaiv1beta1.PathRejection, len(paths))
 	for i, path := range paths {
+		if reason := d.pathRejection(ru, path); reason != "" {
+			rejections[i] = &oaiv1beta1.PathRejection{Path: path, Reason: reason}
+			continue
+		}
+
 		v := &position{
-			disaggregationKey: dsg8Key,
+			disaggregationKey: d.key,
+			latency:           chainConstraints(ru).Latency,
 		}
 
 		// First get the nearest node from the core to place the CU
 		v.cuNodeName = path[1]
 
-		// check if the node has resources to run the CU
-		cuNode := d.nodes[v.cuNodeName]
-		if resourcesAvailable := cuNode.HasResources(d.requestedResources.Memory,
-			d.requestedResources.CPU); !resourcesAvailable {
+		// check if the node can run the CU
+		if reason := d.nodeRejection(ru, PieceCU, v.cuNodeName); reason != "" {
+			rejections[i] = &oaiv1beta1.PathRejection{Path: path, Reason: reason}
 			continue
 		}
 
-		for j := 2; j < len(path)-1; j++ {
-			duNode := d.nodes[path[j]]
-			if resourcesAvailable := duNode.HasResources(d.requestedResources.Memory,
-				d.requestedResources.CPU); !resourcesAvailable {
+		duStart := 2
+		if d.splitsUserPlane() {
+			for j := 2; j < len(path)-2; j++ {
+				if d.eligible(ru, PieceCUUP, path[j]) {
+					v.cuupNodeName = path[j]
+					duStart = j + 1
+					break
+				}
+			}
+
+			if v.cuupNodeName == "" {
+				rejections[i] = &oaiv1beta1.PathRejection{Path: path,
+					Reason: fmt.Sprintf("no CU-UP candidate meeting the constraints with resources between CU-CP "+
+						"node '%s' and RU node '%s'", v.cuNodeName, ru.RUNode)}
+				continue
+			}
+		}
+
+		for j := duStart; j < len(path)-1; j++ {
+			if !d.eligible(ru, PieceDU, path[j]) {
 				continue
 			}
 
@@ -60,34 +96,365 @@ func (d *disaggregation8) Validate(ru *oaiv1beta1.ChainPosition, paths [][]strin
 			break
 		}
 
+		if v.duNodeName == "" {
+			rejections[i] = &oaiv1beta1.PathRejection{Path: path,
+				Reason: fmt.Sprintf("no DU candidate meeting the constraints with resources between CU node "+
+					"'%s' and RU node '%s'", v.cuNodeName, ru.RUNode)}
+			continue
+		}
+
 		v.path = path
 		validation.positions[i] = v
 	}
 
 	if len(validation.positions) == 0 {
 		d.log.Error(errors.New("not enough resources remaining"), "no nodes with available resources")
-		return false, nil
+		return false, nil, compactRejections(rejections)
 	}
 
-	// validate network resources
-	for p, candidate := range validation.positions {
-		if isValid, _ := d.validateNetwork(paths[p], candidate.cuNodeName, candidate.duNodeName,
-			false); isValid {
+	// validate network resources following the paths order, so the preferred core and shortest paths are tried first
+	for p := range paths {
+		candidate, exists := validation.positions[p]
+		if !exists {
+			continue
+		}
+
+		isValid, err := d.validateNetwork(candidate, nil)
+		if isValid {
 			d.log.Info("found possible allocation", "path", candidate.path)
-			return true, candidate
+			return true, candidate, nil
 		}
+
+		rejections[p] = &oaiv1beta1.PathRejection{Path: paths[p], Reason: err.Error()}
 	}
 
 	d.log.Info("no nodes with network requirements", "ru", ru.SplitName)
-	return false, nil
+	return false, nil, compactRejections(rejections)
+}
+
+// resourcesRejection describes which resource the node is missing to run the piece
+func (d *disaggregation8) resourcesRejection(node *utils.Node, piece string) string {
+	requested := d.pieceResources(piece)
+	name := strings.ToUpper(piece)
+	available := node.Resources
+
+	cpu := requested.TotalCPU()
+	if available.CPUAvailable.MilliValue() <= cpu.MilliValue() {
+		return fmt.Sprintf("%s node '%s' out of CPU: %dm available, %dm requested", name, node.NodeName,
+			available.CPUAvailable.MilliValue(), cpu.MilliValue())
+	}
+
+	if available.MemoryAvailable.Value() <= requested.Memory.Value() {
+		return fmt.Sprintf("%s node '%s' out of memory: %d available, %d requested", name, node.NodeName,
+			available.MemoryAvailable.Value(), requested.Memory.Value())
+	}
+
+	cores := (available.CPUAvailable.MilliValue() - requested.CPU.MilliValue()) / 1000
+	if cores < requested.ExclusiveCPUs {
+		return fmt.Sprintf("%s node '%s' out of exclusive CPUs: %d whole cores available, %d requested", name,
+			node.NodeName, cores, requested.ExclusiveCPUs)
+	}
+
+	return fmt.Sprintf("%s node '%s' out of huge pages: %s of 1Gi and %s of 2Mi requested", name, node.NodeName,
+		requested.HugePages1Gi.String(), requested.HugePages2Mi.String())
+}
+
+func compactRejections(rejections []*oaiv1beta1.PathRejection) []*oaiv1beta1.PathRejection {
+	var compacted []*oaiv1beta1.PathRejection
+	for _, rejection := range rejections {
+		if rejection != nil {
+			compacted = append(compacted, rejection)
+		}
+	}
+
+	return compacted
+}
+
+// candidates returns every position of the chain that fulfills the nodes and network requirements, following the
+// order of the paths. Nodes already set in the RU are kept and their resources are not checked again.
+func (d *disaggregation8) candidates(ru *oaiv1beta1.ChainPosition, paths [][]string) []*position {
+	var positions []*position
+	for _, path := range paths {
+		if d.pathRejection(ru, path) != "" {
+			continue
+		}
+
+		cuNodeName := path[1]
+		if !d.fits(ru, PieceCU, ru.CUNode, cuNodeName) {
+			continue
+		}
+
+		// without the user plane split the DU search starts right after the CU
+		cuupIndexes := []int{1}
+		if d.splitsUserPlane() {
+			cuupIndexes = nil
+			for j := 2; j < len(path)-2; j++ {
+				cuupIndexes = append(cuupIndexes, j)
+			}
+		}
+
+		for _, j := range cuupIndexes {
+			cuupNodeName := ""
+			if d.splitsUserPlane() {
+				cuupNodeName = path[j]
+				if !d.fits(ru, PieceCUUP, ru.CUUPNode, cuupNodeName) {
+					continue
+				}
+			}
+
+			for k := j + 1; k < len(path)-1; k++ {
+				duNodeName := path[k]
+				if !d.fits(ru, PieceDU, ru.DUNode, duNodeName) {
+					continue
+				}
+
+				candidate := &position{
+					cuNodeName:        cuNodeName,
+					cuupNodeName:      cuupNodeName,
+					duNodeName:        duNodeName,
+					path:              path,
+					disaggregationKey: d.key,
+					latency:           chainConstraints(ru).Latency,
+				}
+				if isValid, _ := d.validateNetwork(candidate, nil); !isValid {
+					continue
+				}
+
+				positions = append(positions, candidate)
+			}
+		}
+	}
+
+	return positions
+}
+
+// keep returns the previous position of the RU if the chain still fits it: the same path, with the pieces in the same
+// nodes. Otherwise, the reason it does not fit is returned.
+func (d *disaggregation8) keep(ru *oaiv1beta1.ChainPosition) (*position, string) {
+	path := ru.Path
+	if reason := d.pathRejection(ru, path); reason != "" {
+		return nil, reason
+	}
+
+	// the pieces follow the path order from the core: CU, CU-UP and DU
+	if path[1] != ru.CUNode {
+		return nil, fmt.Sprintf("CU node '%s' is not the first node of the path", ru.CUNode)
+	}
+	duStart := 2
+	pieces := []string{PieceCU, PieceDU}
+	nodeNames := []string{ru.CUNode, ru.DUNode}
+	if d.splitsUserPlane() {
+		index := indexOf(path, ru.CUUPNode)
+		if index < 2 || index >= len(path)-2 {
+			return nil, fmt.Sprintf("CU-UP node '%s' out of the path", ru.CUUPNode)
+		}
+		duStart = index + 1
+		pieces = append(pieces, PieceCUUP)
+		nodeNames = append(nodeNames, ru.CUUPNode)
+	}
+	if index := indexOf(path, ru.DUNode); index < duStart || index >= len(path)-1 {
+		return nil, fmt.Sprintf("DU node '%s' out of the path", ru.DUNode)
+	}
+
+	for i, piece := range pieces {
+		if reason := d.nodeRejection(ru, piece, nodeNames[i]); reason != "" {
+			return nil, reason
+		}
+	}
+
+	pos := &position{
+		cuNodeName:        ru.CUNode,
+		duNodeName:        ru.DUNode,
+		path:              path,
+		disaggregationKey: d.key,
+		latency:           chainConstraints(ru).Latency,
+	}
+	if d.splitsUserPlane() {
+		pos.cuupNodeName = ru.CUUPNode
+	}
+	if isValid, err := d.validateNetwork(pos, nil); !isValid {
+		return nil, err.Error()
+	}
+
+	return pos, ""
+}
+
+// indexOf returns the index of the node in the path, -1 if it is not in the path
+func indexOf(path []string, nodeName string) int {
+	for i, name := range path {
+		if name == nodeName {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// fits returns true if the node can host the piece: the node the piece is already placed in, or any node eligible
+// for the piece if it is not placed yet
+func (d *disaggregation8) fits(ru *oaiv1beta1.ChainPosition, piece, placedNodeName, nodeName string) bool {
+	if placedNodeName != "" {
+		return placedNodeName == nodeName
+	}
+
+	return d.eligible(ru, piece, nodeName)
+}
+
+// eligible returns true if the node can host the piece of the chain
+func (d *disaggregation8) eligible(ru *oaiv1beta1.ChainPosition, piece, nodeName string) bool {
+	return d.nodeRejection(ru, piece, nodeName) == ""
+}
+
+// nodeRejection returns why the node cannot host the piece of the chain, empty if it can. A piece pinned by the chain
+// constraints only goes to its node, which skips the node lists and the constraints of the role but must have the
+// resources to run it.
+func (d *disaggregation8) nodeRejection(ru *oaiv1beta1.ChainPosition, piece, nodeName string) string {
+	name := strings.ToUpper(piece)
+	node := d.nodes[nodeName]
+	chain := chainConstraints(ru)
+
+	if pinned := pinnedNode(chain, piece); pinned != "" {
+		if pinned != nodeName {
+			return fmt.Sprintf("%s pinned to node '%s', not '%s'", name, pinned, nodeName)
+		}
+	} else {
+		if len(chain.AllowedNodes) > 0 && !utils.ContainsString(chain.AllowedNodes, nodeName) {
+			return fmt.Sprintf("%s node '%s' is not allowed for the chain", name, nodeName)
+		}
+		if utils.ContainsString(chain.ForbiddenNodes, nodeName) {
+			return fmt.Sprintf("%s node '%s' is forbidden for the chain", name, nodeName)
+		}
+		if reason := d.constraints.rejection(piece, node); reason != "" {
+			return reason
+		}
+	}
+
+	if !node.HasResources(d.pieceResources(piece)) {
+		return d.resourcesRejection(node, piece)
+	}
+
+	return ""
+}
+
+// pathRejection returns why the path cannot take the chain regardless of the nodes resources, empty if it can
+func (d *disaggregation8) pathRejection(ru *oaiv1beta1.ChainPosition, path []string) string {
+	// the chain needs at least the core, the CU, the DU and the RU nodes, plus the CU-UP node in NR
+	if len(path) < d.minPathLength() {
+		return d.shortPathRejection()
+	}
+
+	chain := chainConstraints(ru)
+	if hops := len(path) - 1; chain.MaxHops > 0 && hops > chain.MaxHops {
+		return fmt.Sprintf("path has %d hops, over the chain limit of %d", hops, chain.MaxHops)
+	}
+
+	// the pinned pieces must be in the path between the core and the RU
+	for _, piece := range []string{PieceCU, PieceCUUP, PieceDU} {
+		pinned := pinnedNode(chain, piece)
+		if pinned != "" && !utils.ContainsString(path[1:len(path)-1], pinned) {
+			return fmt.Sprintf("%s pinned to node '%s' out of the path", strings.ToUpper(piece), pinned)
+		}
+	}
+
+	return ""
 }
 
-func (d *disaggregation8) validateNetwork(path []string, cuNode, duNode string, allocateResources bool) (bool,
-	error) {
-	disaggregationQueue := pkgqueue.New(3)
-	disaggregationQueue.Put(d.networkRequirements.Backhaul, d.networkRequirements.Midhaul, d.networkRequirements.Fronthaul)
+// chainConstraints returns the constraints of the chain, empty if it has none
+func chainConstraints(ru *oaiv1beta1.ChainPosition) *oaiv1beta1.ChainConstraints {
+	if ru.Constraints == nil {
+		return &oaiv1beta1.ChainConstraints{}
+	}
 
-	placementNodes := utils.NewStringSet(path[0], cuNode, duNode)
+	return ru.Constraints
+}
+
+func pinnedNode(chain *oaiv1beta1.ChainConstraints, piece string) string {
+	switch piece {
+	case PieceCU:
+		return chain.CUNode
+	case PieceCUUP:
+		return chain.CUUPNode
+	case PieceDU:
+		return chain.DUNode
+	}
+
+	return ""
+}
+
+// pieceResources returns the resources requested by the piece
+func (d *disaggregation8) pieceResources(piece string) *utils.RequestedResources {
+	if requestedResources, exists := d.piecesResources[piece]; exists {
+		return requestedResources
+	}
+
+	return d.requestedResources
+}
+
+// splitsUserPlane returns true if the disaggregation places the CU-UP apart from the CU-CP, as NR chains do
+func (d *disaggregation8) splitsUserPlane() bool {
+	return d.networkRequirements.E1 != nil
+}
+
+func (d *disaggregation8) minPathLength() int {
+	if d.splitsUserPlane() {
+		return 5
+	}
+
+	return 4
+}
+
+func (d *disaggregation8) shortPathRejection() string {
+	if d.splitsUserPlane() {
+		return "path too short to place CU-CP, CU-UP, DU and RU"
+	}
+
+	return "path too short to place CU, DU and RU"
+}
+
+// segments returns the nodes starting each segment of the chain and the requirements of the segment, from the core
+// to the DU. The latency budget of the chain overrides the one of the disaggregation.
+func (d *disaggregation8) segments(pos *position) ([]string, []string, []*oaiv1beta1.NetworkRequirements) {
+	latency := pos.latency
+	if latency == nil {
+		latency = &oaiv1beta1.LatencyBudget{}
+	}
+
+	if d.splitsUserPlane() {
+		return []string{pos.path[0], pos.cuNodeName, pos.cuupNodeName, pos.duNodeName},
+			[]string{"backhaul", "e1", "midhaul", "fronthaul"},
+			[]*oaiv1beta1.NetworkRequirements{withLatency(d.networkRequirements.Backhaul, latency.Backhaul),
+				withLatency(d.networkRequirements.E1, latency.E1),
+				withLatency(d.networkRequirements.Midhaul, latency.Midhaul),
+				withLatency(d.networkRequirements.Fronthaul, latency.Fronthaul)}
+	}
+
+	return []string{pos.path[0], pos.cuNodeName, pos.duNodeName},
+		[]string{"backhaul", "midhaul", "fronthaul"},
+		[]*oaiv1beta1.NetworkRequirements{withLatency(d.networkRequirements.Backhaul, latency.Backhaul),
+			withLatency(d.networkRequirements.Midhaul, latency.Midhaul),
+			withLatency(d.networkRequirements.Fronthaul, latency.Fronthaul)}
+}
+
+// withLatency returns the requirements with the latency given, or the requirements unchanged if the latency is not a
+// valid number
+func withLatency(requirements *oaiv1beta1.NetworkRequirements, latency string) *oaiv1beta1.NetworkRequirements {
+	value, err := strconv.ParseFloat(latency, 32)
+	if requirements == nil || latency == "" || err != nil {
+		return requirements
+	}
+
+	return &oaiv1beta1.NetworkRequirements{Latency: float32(value), Bandwidth: requirements.Bandwidth}
+}
+
+// validateNetwork checks the links of the path have the bandwidth and latency required by each segment of the chain,
+// or allocates the bandwidth in the transaction if one is given. When only checking, the error tells why the path is
+// not valid.
+func (d *disaggregation8) validateNetwork(pos *position, tx *transaction) (bool, error) {
+	path := pos.path
+	segmentNodes, segments, requirements := d.segments(pos)
+	segment := -1
+
+	placementNodes := utils.NewStringSet(segmentNodes...)
 
 	d.log.Info("validating network for path", "path", path)
 
@@ -96,8 +463,8 @@ func (d *disaggregation8) validateNetwork(path []string, cuNode, duNode string,
 	// Check if links have the required resources
 	for i, nodeName := range path {
 		if placementNodes.Has(nodeName) {
-			r, _ := disaggregationQueue.Get(1)
-			requirement = r[0].(*oaiv1beta1.NetworkRequirements)
+			segment++
+			requirement = requirements[segment]
 			totalLatency = 0
 		}
 
@@ -111,15 +478,19 @@ func (d *disaggregation8) validateNetwork(path []string, cuNode, duNode string,
 				"required bandwidth", requirement.Bandwidth,
 				"total latency", totalLatency, "required latency", requirement.Latency, "node", nodeName,
 				"nextNodeName", nextNodeName)
-			if allocateResources {
-				if err := link.AllocateResources(requirement.Bandwidth); err != nil {
+			if tx != nil {
+				if err := tx.allocateLink(link, requirement.Bandwidth); err != nil {
 					return false, fmt.Errorf("error allocating resources: %w", err)
 				}
 				d.log.Info("remaining link bandwidth", "link", link.LinkName, "bandwidth", link.AvailableBandwidth)
-			} else if !link.HasBandwidth(requirement.Bandwidth) ||
-				(requirement.Latency > 0 && totalLatency > requirement.Latency) {
+			} else if !link.HasBandwidth(requirement.Bandwidth) {
+				d.log.Info("error: link without required resources", "node", nodeName, "next node", nextNodeName)
+				return false, fmt.Errorf("link '%s' short of bandwidth by %f", link.LinkName,
+					requirement.Bandwidth-link.AvailableBandwidth)
+			} else if requirement.Latency > 0 && totalLatency > requirement.Latency {
 				d.log.Info("error: link without required resources", "node", nodeName, "next node", nextNodeName)
-				return false, nil
+				return false, fmt.Errorf("%s latency exceeded by %f at link '%s'", segments[segment],
+					totalLatency-requirement.Latency, link.LinkName)
 			}
 		}
 	}
@@ -127,22 +498,111 @@ func (d *disaggregation8) validateNetwork(path []string, cuNode, duNode string,
 	return true, nil
 }
 
+// AllocateResources reserves the nodes and the bandwidth of the chain. If any reservation fails, the ones already made
+// are rolled back.
 func (d *disaggregation8) AllocateResources(ru *oaiv1beta1.ChainPosition) error {
+	tx := &transaction{}
+	if err := d.allocateChain(ru, tx); err != nil {
+		tx.rollback()
+		return err
+	}
+
+	return nil
+}
+
+func (d *disaggregation8) allocateChain(ru *oaiv1beta1.ChainPosition, tx *transaction) error {
 	// allocate resources from nodes
-	du := d.nodes[ru.DUNode]
-	if err := du.AllocateResources(d.requestedResources.Memory, d.requestedResources.CPU, d.log); err != nil {
+	if err := tx.allocateNode(d.nodes[ru.DUNode], d.pieceResources(PieceDU), d.log); err != nil {
 		return fmt.Errorf("error allocating du resources: %w", err)
 	}
 
-	cu := d.nodes[ru.CUNode]
-	if err := cu.AllocateResources(d.requestedResources.Memory, d.requestedResources.CPU, d.log); err != nil {
+	if err := tx.allocateNode(d.nodes[ru.CUNode], d.pieceResources(PieceCU), d.log); err != nil {
 		return fmt.Errorf("error allocating cu resources: %w", err)
 	}
 
+	if ru.CUUPNode != "" {
+		if err := tx.allocateNode(d.nodes[ru.CUUPNode], d.pieceResources(PieceCUUP), d.log); err != nil {
+			return fmt.Errorf("error allocating cu-up resources: %w", err)
+		}
+	}
+
+	pieces := map[string]string{PieceCU: ru.CUNode, PieceDU: ru.DUNode, PieceCUUP: ru.CUUPNode}
+	for piece, nodeName := range pieces {
+		if nodeName == "" {
+			continue
+		}
+		piece, nodeName := piece, nodeName
+		d.constraints.add(piece, nodeName)
+		tx.onRollback(func() {
+			d.constraints.remove(piece, nodeName)
+		})
+	}
+
 	// allocate bandwidth
-	if success, err := d.validateNetwork(ru.Path, ru.CUNode, ru.DUNode, true); err != nil || !success {
+	if success, err := d.validateNetwork(positionOf(ru), tx); err != nil || !success {
 		return fmt.Errorf("error allocating network resources: %w", err)
 	}
 
 	return nil
 }
+
+func (d *disaggregation8) ReleaseNetwork(ru *oaiv1beta1.ChainPosition) {
+	bandwidth := d.linksBandwidth(ru)
+	for i := 0; i+1 < len(ru.Path); i++ {
+		link := d.nodes[ru.Path[i]].Links[ru.Path[i+1]]
+		link.ReleaseResources(bandwidth[link.LinkName])
+	}
+}
+
+// linksBandwidth returns the bandwidth the RU chain requires from each link of its path. The key is the link name.
+func (d *disaggregation8) linksBandwidth(ru *oaiv1beta1.ChainPosition) map[string]float32 {
+	segmentNodes, _, requirements := d.segments(positionOf(ru))
+	placementNodes := utils.NewStringSet(segmentNodes...)
+
+	bandwidth := make(map[string]float32)
+	segment := -1
+	for i := 0; i+1 < len(ru.Path); i++ {
+		if placementNodes.Has(ru.Path[i]) {
+			segment++
+		}
+
+		link := d.nodes[ru.Path[i]].Links[ru.Path[i+1]]
+		bandwidth[link.LinkName] = requirements[segment].Bandwidth
+	}
+
+	return bandwidth
+}
+
+// segmentsLatency returns the latency of each segment of the RU chain. The key is the segment name.
+func (d *disaggregation8) segmentsLatency(ru *oaiv1beta1.ChainPosition) map[string]float32 {
+	segmentNodes, segments, _ := d.segments(positionOf(ru))
+	placementNodes := utils.NewStringSet(segmentNodes...)
+
+	latency := make(map[string]float32)
+	for _, segment := range segments {
+		latency[segment] = 0
+	}
+
+	segment := -1
+	for i := 0; i+1 < len(ru.Path); i++ {
+		if placementNodes.Has(ru.Path[i]) {
+			segment++
+		}
+
+		latency[segments[segment]] += d.nodes[ru.Path[i]].Links[ru.Path[i+1]].Latency
+	}
+
+	return latency
+}
+
+// positionOf returns the position the RU chain was placed in
+func positionOf(ru *oaiv1beta1.ChainPosition) *position {
+	return &position{
+		cuNodeName:        ru.CUNode,
+		cuupNodeName:      ru.CUUPNode,
+		duNodeName:        ru.DUNode,
+		path:              ru.Path,
+		disaggregationKey: ru.Disaggregation,
+		latency:           chainConstraints(ru).Latency,
+	}
+}
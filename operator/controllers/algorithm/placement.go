@@ -3,17 +3,27 @@ package algorithm
 import (
 	"errors"
 	"fmt"
+	"sort"
 
 	pkgqueue "github.com/Workiva/go-datastructures/queue"
 	"github.com/go-logr/logr"
 	oaiv1beta1 "github.com/juliorenner/oai-k8s/operator/api/v1beta1"
 	"github.com/juliorenner/oai-k8s/operator/controllers/utils"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 const (
 	logSplitKey = "split"
 	dsg8Key     = "1"
+	// dsg8NRKey is the disaggregation of NR chains, with the E1 segment between the CU-CP and the CU-UP
+	dsg8NRKey = "5"
+
+	// PieceCU, PieceCUUP, PieceDU and PieceRU are the split pieces placed, with the names used by the split controller
+	PieceCU   = "cu"
+	PieceCUUP = "cuup"
+	PieceDU   = "du"
+	PieceRU   = "ru"
 )
 
 type Disaggregation interface {
@@ -22,14 +32,26 @@ type Disaggregation interface {
 }
 
 type PlacementBFS struct {
-	root               string
+	// cores are the core nodes of the topology sorted by name
+	cores         []string
+	coreSelection oaiv1beta1.CoreSelection
+	// coreChains key is the core node and the value the number of chains it serves
+	coreChains         map[string]int
+	disaggregationKey  string
 	topology           *oaiv1beta1.Topology
 	disaggregations    map[string]*oaiv1beta1.Disaggregation
 	requestedResources *utils.RequestedResources
+	// piecesResources key is the piece whose requested resources differ from the default ones
+	piecesResources    map[string]*utils.RequestedResources
+	constraints        *constraints
+	preemption         bool
 	remainingBandwidth map[string]*utils.Link
 	nodes              map[string]*utils.Node
 	cachePaths         map[string][][]string
-	log                logr.Logger
+	// rejections key is the split name of the RUs not allocated
+	rejections map[string][]*oaiv1beta1.PathRejection
+	evictions  []*oaiv1beta1.Eviction
+	log        logr.Logger
 }
 
 type pathsValidation struct {
@@ -40,15 +62,21 @@ type pathsValidation struct {
 
 type position struct {
 	cuNodeName        string
+	cuupNodeName      string
 	duNodeName        string
 	path              []string
 	disaggregationKey string
+	// latency overrides the latency budget of the disaggregation for the chain
+	latency *oaiv1beta1.LatencyBudget
 }
 
+// NewPlacementBFS creates the topology graph. The resources available in each node are the allocatable ones minus the
+// usage already in place in the node, the nodesUsage key is the node name.
 func NewPlacementBFS(topology *oaiv1beta1.Topology, disaggregations map[string]*oaiv1beta1.Disaggregation,
-	k8sNodes *v1.NodeList, requestedResources *utils.RequestedResources, log logr.Logger) *PlacementBFS {
+	k8sNodes *v1.NodeList, nodesUsage map[string]*utils.RequestedResources, requestedResources *utils.RequestedResources,
+	log logr.Logger) *PlacementBFS {
 	k8sNodeMap := utils.NodeListToMap(k8sNodes)
-	core := ""
+	var cores []string
 	graphNodes := make(map[string]*utils.Node)
 	for name, nodes := range topology.Nodes {
 		k8sNode := k8sNodeMap[name]
@@ -58,10 +86,23 @@ func NewPlacementBFS(topology *oaiv1beta1.Topology, disaggregations map[string]*
 			CPU:             k8sNode.Status.Capacity.Cpu(),
 			CPUAvailable:    k8sNode.Status.Allocatable.Cpu(),
 		}
+		resources.HugePages1GiAvailable = allocatable(k8sNode, utils.ResourceHugePages1Gi)
+		resources.HugePages2MiAvailable = allocatable(k8sNode, utils.ResourceHugePages2Mi)
+		if usage, exists := nodesUsage[name]; exists {
+			resources.MemoryAvailable.Sub(usage.Memory)
+			resources.CPUAvailable.Sub(usage.CPU)
+			if resources.HugePages1GiAvailable != nil {
+				resources.HugePages1GiAvailable.Sub(usage.HugePages1Gi)
+			}
+			if resources.HugePages2MiAvailable != nil {
+				resources.HugePages2MiAvailable.Sub(usage.HugePages2Mi)
+			}
+		}
 
-		graphNodes[name] = &utils.Node{NodeName: name, Links: make(map[string]*utils.Link), Resources: resources}
+		graphNodes[name] = &utils.Node{NodeName: name, Links: make(map[string]*utils.Link), Resources: resources,
+			Labels: k8sNode.Labels, Taints: k8sNode.Spec.Taints}
 		if nodes.Core {
-			core = name
+			cores = append(cores, name)
 		}
 	}
 
@@ -79,34 +120,105 @@ func NewPlacementBFS(topology *oaiv1beta1.Topology, disaggregations map[string]*
 		remainingBandwidth[linkName] = v
 	}
 
-	return &PlacementBFS{root: core, topology: topology, nodes: graphNodes, disaggregations: disaggregations,
-		requestedResources: requestedResources, remainingBandwidth: remainingBandwidth, log: log}
+	sort.Strings(cores)
+
+	return &PlacementBFS{cores: cores, coreSelection: oaiv1beta1.CoreSelectionNearest,
+		coreChains: make(map[string]int), disaggregationKey: dsg8Key, topology: topology, nodes: graphNodes,
+		disaggregations: disaggregations, requestedResources: requestedResources, remainingBandwidth: remainingBandwidth,
+		piecesResources: make(map[string]*utils.RequestedResources), constraints: newConstraints(),
+		rejections: make(map[string][]*oaiv1beta1.PathRejection), log: log}
+}
+
+// allocatable returns the allocatable quantity of the resource in the node, nil if the node does not advertise it
+func allocatable(node *v1.Node, name v1.ResourceName) *resource.Quantity {
+	value, exists := node.Status.Allocatable[name]
+	if !exists {
+		return nil
+	}
+
+	quantity := value.DeepCopy()
+	return &quantity
 }
 
 func (p *PlacementBFS) Place(rus []*oaiv1beta1.ChainPosition) (bool, error) {
+	if _, exists := p.disaggregations[p.disaggregationKey]; !exists {
+		return false, fmt.Errorf("disaggregation '%s' is not defined", p.disaggregationKey)
+	}
+
 	if err := p.allocateRUsResources(rus); err != nil {
 		return false, fmt.Errorf("validation of RU placement failed: %w", err)
 	}
 
-	dsg8 := NewDsg8(p.nodes, p.requestedResources, p.disaggregations[dsg8Key], p.log)
-	for _, ru := range rus {
-		paths := p.findPathsTo(ru.RUNode)
+	dsg8 := p.newDsg8()
+	var placed []*oaiv1beta1.ChainPosition
+	for _, ru := range p.placementOrder(rus) {
+		previous := ru.Path
+		if p.keepChain(dsg8, ru) {
+			placed = append(placed, ru)
+			continue
+		}
 
-		p.log.Info("starting ru validation", "ru name", ru.SplitName, "paths", paths)
+		if ru.CoreNode != "" && !utils.ContainsString(p.cores, ru.CoreNode) {
+			p.rejections[ru.SplitName] = []*oaiv1beta1.PathRejection{{
+				Reason: fmt.Sprintf("node '%s' is not a core of the topology", ru.CoreNode)}}
+			continue
+		}
 
-		if possible, splitPos := dsg8.Validate(ru, paths); possible {
-			fulfillRU(ru, splitPos)
+		success, err := p.placeChain(dsg8, ru)
+		if err != nil {
+			return false, err
+		}
+		if success {
+			placed = append(placed, ru)
+		}
 
-			if err := dsg8.AllocateResources(ru); err != nil {
-				return false, fmt.Errorf("error updating resources: %w", err)
-			}
-		} else {
-			p.log.Error(errors.New("disaggregation allocation not possible"),
-				"not possible to allocate using disaggregation 8", "ru", ru.SplitName)
-			//return false, nil
+		// a running chain that lost its position to a chain of higher priority was preempted by it
+		if preemptor := preemptedBy(ru, previous, placed); p.preemption && preemptor != nil {
+			p.evictions = append(p.evictions, &oaiv1beta1.Eviction{SplitName: ru.SplitName,
+				PreemptedBy: preemptor.SplitName, Reallocated: success})
 		}
 	}
 
+	p.releaseUnplacedRUs(rus)
+
+	return true, nil
+}
+
+// releaseUnplacedRUs gives back the RU node resources reserved for the RUs whose chain was not placed
+func (p *PlacementBFS) releaseUnplacedRUs(rus []*oaiv1beta1.ChainPosition) {
+	for _, ru := range rus {
+		if ru.CUNode == "" || ru.DUNode == "" {
+			p.log.Info("releasing resources of RU not placed", logSplitKey, ru.SplitName, "node", ru.RUNode)
+			p.nodes[ru.RUNode].ReleaseResources(p.pieceResources(PieceRU))
+		}
+	}
+}
+
+// placeChain places the chain of the RU in the first valid position and allocates its resources. If there is none,
+// the rejections of the RU are recorded.
+func (p *PlacementBFS) placeChain(dsg8 *disaggregation8, ru *oaiv1beta1.ChainPosition) (bool, error) {
+	paths := p.findChainPaths(ru)
+
+	p.log.Info("starting ru validation", "ru name", ru.SplitName, "paths", paths)
+
+	possible, splitPos, rejections := dsg8.Validate(ru, paths)
+	if !possible {
+		clearPosition(ru)
+		p.rejections[ru.SplitName] = rejections
+		p.log.Error(errors.New("disaggregation allocation not possible"),
+			"not possible to allocate using disaggregation 8", "ru", ru.SplitName)
+		return false, nil
+	}
+
+	delete(p.rejections, ru.SplitName)
+	fulfillRU(ru, splitPos)
+
+	if err := dsg8.AllocateResources(ru); err != nil {
+		clearPosition(ru)
+		return false, fmt.Errorf("error updating resources: %w", err)
+	}
+	p.coreChains[ru.CoreNode]++
+
 	return true, nil
 }
 
@@ -114,21 +226,208 @@ func (p *PlacementBFS) GetRemainingBandwidth() map[string]*utils.Link {
 	return p.remainingBandwidth
 }
 
+// GetRejections returns why each candidate path of the RUs not allocated was rejected. The key is the split name.
+func (p *PlacementBFS) GetRejections() map[string][]*oaiv1beta1.PathRejection {
+	return p.rejections
+}
+
+// Candidates returns every position where the chain of the RU fits, from the most to the least preferred. CU and DU
+// nodes already set in the RU are kept, only the remaining pieces are searched.
+func (p *PlacementBFS) Candidates(ru *oaiv1beta1.ChainPosition) []*oaiv1beta1.ChainPosition {
+	if _, exists := p.disaggregations[p.disaggregationKey]; !exists {
+		return nil
+	}
+
+	dsg8 := p.newDsg8()
+
+	var chains []*oaiv1beta1.ChainPosition
+	for _, pos := range dsg8.candidates(ru, p.findChainPaths(ru)) {
+		chain := ru.DeepCopy()
+		fulfillRU(chain, pos)
+		chains = append(chains, chain)
+	}
+
+	return chains
+}
+
+// SetRAT selects the disaggregation of the chains. NR chains use the disaggregation with the E1 segment and place a
+// CU-UP between the CU-CP and the DU.
+func (p *PlacementBFS) SetRAT(rat oaiv1beta1.RAT) {
+	if rat == oaiv1beta1.RATNR {
+		p.disaggregationKey = dsg8NRKey
+	} else {
+		p.disaggregationKey = dsg8Key
+	}
+}
+
+// Placed returns true if every piece of the chain has a node
+func (p *PlacementBFS) Placed(ru *oaiv1beta1.ChainPosition) bool {
+	disaggregation, exists := p.disaggregations[p.disaggregationKey]
+	splitsUserPlane := exists && disaggregation.E1 != nil
+
+	return ru.CUNode != "" && ru.DUNode != "" && (!splitsUserPlane || ru.CUUPNode != "")
+}
+
+// SetCoreSelection defines how the core of the chains not pinned to a core node is chosen.
+func (p *PlacementBFS) SetCoreSelection(coreSelection oaiv1beta1.CoreSelection) {
+	if coreSelection != "" {
+		p.coreSelection = coreSelection
+	}
+}
+
+// ReserveCores counts chains already served by the cores, considered by the LeastLoaded core selection. The key is
+// the core node and the value the number of chains.
+func (p *PlacementBFS) ReserveCores(coreChains map[string]int) {
+	for core, chains := range coreChains {
+		p.coreChains[core] += chains
+	}
+}
+
+// SetPieceResources overrides the resources requested by the piece, such as the exclusive CPUs and huge pages of a
+// DU with a performance profile.
+func (p *PlacementBFS) SetPieceResources(piece string, requestedResources *utils.RequestedResources) {
+	p.piecesResources[piece] = requestedResources
+}
+
+// SetConstraints restricts the nodes eligible for each piece of the chains.
+func (p *PlacementBFS) SetConstraints(placementConstraints *oaiv1beta1.PlacementConstraints) {
+	p.constraints.placement = placementConstraints
+}
+
+// ReserveNode allocates the resources of one split piece in the node.
+func (p *PlacementBFS) ReserveNode(piece, nodeName string) error {
+	node, exists := p.nodes[nodeName]
+	if !exists {
+		return fmt.Errorf("node '%s' is not part of the topology", nodeName)
+	}
+
+	p.constraints.add(piece, nodeName)
+	return node.AllocateResources(p.pieceResources(piece), p.log)
+}
+
+// ReleaseNode gives back the resources of one split piece reserved in the node.
+func (p *PlacementBFS) ReleaseNode(piece, nodeName string) {
+	if node, exists := p.nodes[nodeName]; exists {
+		p.constraints.remove(piece, nodeName)
+		node.ReleaseResources(p.pieceResources(piece))
+	}
+}
+
+// pieceResources returns the resources requested by the piece
+func (p *PlacementBFS) pieceResources(piece string) *utils.RequestedResources {
+	if requestedResources, exists := p.piecesResources[piece]; exists {
+		return requestedResources
+	}
+
+	return p.requestedResources
+}
+
+// ReserveNetwork allocates the bandwidth required by the chain along its path.
+func (p *PlacementBFS) ReserveNetwork(ru *oaiv1beta1.ChainPosition) error {
+	tx := &transaction{}
+	if success, err := p.newDsg8().validateNetwork(positionOf(ru), tx); err != nil || !success {
+		tx.rollback()
+		return fmt.Errorf("error allocating network resources: %w", err)
+	}
+
+	return nil
+}
+
+// ReleaseNetwork gives back the bandwidth reserved by the chain along its path.
+func (p *PlacementBFS) ReleaseNetwork(ru *oaiv1beta1.ChainPosition) {
+	p.newDsg8().ReleaseNetwork(ru)
+}
+
+// ChainLinks returns the bandwidth the chain requires from each link of its path. The key is the link name.
+func (p *PlacementBFS) ChainLinks(ru *oaiv1beta1.ChainPosition) map[string]float32 {
+	return p.newDsg8().linksBandwidth(ru)
+}
+
+// ChainLatency returns the latency of each segment of the chain path. The key is the segment name.
+func (p *PlacementBFS) ChainLatency(ru *oaiv1beta1.ChainPosition) map[string]float32 {
+	return p.newDsg8().segmentsLatency(ru)
+}
+
+// ReserveLinks deducts bandwidth already reserved from the links. The key is the link name. Links no longer part of
+// the topology are ignored.
+func (p *PlacementBFS) ReserveLinks(linksUsage map[string]float32) {
+	for linkName, bandwidth := range linksUsage {
+		link, exists := p.remainingBandwidth[linkName]
+		if !exists {
+			p.log.Info("reserved link is not part of the topology", "link", linkName)
+			continue
+		}
+
+		if err := link.AllocateResources(bandwidth); err != nil {
+			p.log.Info("link already overcommitted", "link", linkName, "bandwidth", link.AvailableBandwidth)
+		}
+	}
+}
+
+// ReleaseLinks gives back bandwidth reserved in the links. The key is the link name.
+func (p *PlacementBFS) ReleaseLinks(linksUsage map[string]float32) {
+	for linkName, bandwidth := range linksUsage {
+		if link, exists := p.remainingBandwidth[linkName]; exists {
+			link.ReleaseResources(bandwidth)
+		}
+	}
+}
+
+func (p *PlacementBFS) newDsg8() *disaggregation8 {
+	dsg8 := NewDsg8(p.disaggregationKey, p.nodes, p.requestedResources, p.disaggregations[p.disaggregationKey], p.log)
+	dsg8.piecesResources = p.piecesResources
+	dsg8.constraints = p.constraints
+
+	return dsg8
+}
+
 func fulfillRU(ru *oaiv1beta1.ChainPosition, finalPos *position) {
 	ru.DUNode = finalPos.duNodeName
 	ru.CUNode = finalPos.cuNodeName
+	ru.CUUPNode = finalPos.cuupNodeName
 	ru.Path = finalPos.path
+	ru.CoreNode = finalPos.path[0]
 	ru.Disaggregation = finalPos.disaggregationKey
 }
 
-func (p *PlacementBFS) findPathsTo(nodeToFind string) [][]string {
-	if val, ok := p.cachePaths[nodeToFind]; ok {
+// findChainPaths returns the paths from the cores to the RU node in the order they should be tried. A chain pinned to
+// a core only uses the paths from it. Otherwise, Nearest sorts the paths of every core by length and LeastLoaded
+// tries the cores serving fewer chains first.
+func (p *PlacementBFS) findChainPaths(ru *oaiv1beta1.ChainPosition) [][]string {
+	if ru.CoreNode != "" {
+		return p.findPathsTo(ru.CoreNode, ru.RUNode)
+	}
+
+	cores := make([]string, len(p.cores))
+	copy(cores, p.cores)
+	if p.coreSelection == oaiv1beta1.CoreSelectionLeastLoaded {
+		sort.SliceStable(cores, func(i, j int) bool {
+			return p.coreChains[cores[i]] < p.coreChains[cores[j]]
+		})
+	}
+
+	var paths [][]string
+	for _, core := range cores {
+		paths = append(paths, p.findPathsTo(core, ru.RUNode)...)
+	}
+
+	if p.coreSelection != oaiv1beta1.CoreSelectionLeastLoaded {
+		sort.SliceStable(paths, func(i, j int) bool {
+			return len(paths[i]) < len(paths[j])
+		})
+	}
+
+	return paths
+}
+
+func (p *PlacementBFS) findPathsTo(core, nodeToFind string) [][]string {
+	if val, ok := p.cachePaths[core+"/"+nodeToFind]; ok {
 		return val
 	}
 
 	visited := utils.NewStringSet()
 	queue := pkgqueue.New(int64(len(p.nodes)))
-	queue.Put([]string{p.root})
+	queue.Put([]string{core})
 
 	pathsToNode := make([][]string, 0)
 
@@ -144,7 +443,8 @@ func (p *PlacementBFS) findPathsTo(nodeToFind string) [][]string {
 		}
 
 		for nodeName := range nodeToExplore.Links {
-			if !visited.Has(nodeName) {
+			// the chain is served by a single core, paths crossing other cores are not considered
+			if !visited.Has(nodeName) && !utils.ContainsString(p.cores, nodeName) {
 				newPath := getNewPath(path, nodeName)
 				queue.Put(newPath)
 			}
@@ -154,11 +454,14 @@ func (p *PlacementBFS) findPathsTo(nodeToFind string) [][]string {
 	return pathsToNode
 }
 
+// allocateRUsResources reserves the RU nodes before placing any chain, so the other pieces do not take them. If any RU
+// node does not have the resources, every reservation is rolled back.
 func (p *PlacementBFS) allocateRUsResources(splitsPlacer []*oaiv1beta1.ChainPosition) error {
+	tx := &transaction{}
 	for _, ru := range splitsPlacer {
 		topologyNode := p.nodes[ru.RUNode]
-		if err := topologyNode.AllocateResources(p.requestedResources.Memory, p.requestedResources.CPU,
-			p.log); err != nil {
+		if err := tx.allocateNode(topologyNode, p.pieceResources(PieceRU), p.log); err != nil {
+			tx.rollback()
 			return fmt.Errorf("error allocating split '%s' in node '%s'. Not enough resources available: %w",
 				ru.SplitName, ru.RUNode, err)
 		}
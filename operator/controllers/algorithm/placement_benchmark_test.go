@@ -0,0 +1,83 @@
+package algorithm
+
+import (
+	"fmt"
+	"testing"
+
+	oaiv1beta1 "github.com/juliorenner/oai-k8s/operator/api/v1beta1"
+	"github.com/juliorenner/oai-k8s/operator/controllers/utils"
+	"github.com/juliorenner/oai-k8s/operator/generator"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// maxBenchmarkRUs limits the RUs placed in each benchmark so the time reflects the topology size
+const maxBenchmarkRUs = 100
+
+func BenchmarkPlace(b *testing.B) {
+	disaggregations := newTestPlacement(b).disaggregation
+
+	benchmarks := []*generator.Options{
+		{Shape: generator.Tree, Depth: 3, FanOut: 2},
+		{Shape: generator.Tree, Depth: 5, FanOut: 3},
+		{Shape: generator.Tree, Depth: 6, FanOut: 4},
+		{Shape: generator.FatTree, Depth: 3, FanOut: 2},
+		{Shape: generator.FatTree, Depth: 5, FanOut: 3},
+		{Shape: generator.Ring, Nodes: 100},
+		{Shape: generator.Mesh, Nodes: 100, MeshDegree: 0.05},
+		{Shape: generator.Mesh, Nodes: 1000, MeshDegree: 0.005},
+	}
+
+	for _, options := range benchmarks {
+		options.Capacity = &generator.Distribution{Type: generator.Uniform, A: 600, B: 2400}
+		options.Delay = &generator.Distribution{Type: generator.Uniform, A: 0.1, B: 1}
+		options.Seed = 1
+
+		topology, err := generator.Generate(options)
+		if err != nil {
+			b.Fatalf("error generating topology: %s", err)
+		}
+
+		nodes := generateBenchmarkNodeList(topology)
+		requestedResources := &utils.RequestedResources{
+			Memory: *utils.NewQuantity("256Mi"),
+			CPU:    *utils.NewQuantity("150m"),
+		}
+
+		edgeNodes := generator.EdgeNodes(topology)
+		if len(edgeNodes) > maxBenchmarkRUs {
+			edgeNodes = edgeNodes[:maxBenchmarkRUs]
+		}
+
+		b.Run(fmt.Sprintf("%s-%dnodes-%drus", options.Shape, len(topology.Nodes), len(edgeNodes)), func(b *testing.B) {
+			for n := 0; n < b.N; n++ {
+				b.StopTimer()
+				rus := generateRUs(edgeNodes...)
+				topologyGraph := NewPlacementBFS(topology, disaggregations, nodes, nil, requestedResources,
+					ctrllog.NullLogger{})
+				b.StartTimer()
+
+				if _, err := topologyGraph.Place(rus); err != nil {
+					b.Fatalf("error placing rus: %s", err)
+				}
+			}
+		})
+	}
+}
+
+func generateBenchmarkNodeList(topology *oaiv1beta1.Topology) *v1.NodeList {
+	nodeList := &v1.NodeList{}
+	for nodeName := range topology.Nodes {
+		resources := v1.ResourceList{
+			v1.ResourceCPU:    *utils.NewQuantity("16"),
+			v1.ResourceMemory: *utils.NewQuantity("32Gi"),
+		}
+		nodeList.Items = append(nodeList.Items, v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: nodeName},
+			Status:     v1.NodeStatus{Capacity: resources, Allocatable: resources},
+		})
+	}
+
+	return nodeList
+}
@@ -0,0 +1,243 @@
+package algorithm
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"testing/quick"
+
+	oaiv1beta1 "github.com/juliorenner/oai-k8s/operator/api/v1beta1"
+	"github.com/juliorenner/oai-k8s/operator/controllers/utils"
+	"github.com/juliorenner/oai-k8s/operator/generator"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var shapes = []generator.Shape{generator.Tree, generator.FatTree, generator.Ring, generator.Mesh}
+
+// scenario is one random placement input
+type scenario struct {
+	topology           *oaiv1beta1.Topology
+	disaggregation     *oaiv1beta1.Disaggregation
+	nodes              *v1.NodeList
+	requestedResources *utils.RequestedResources
+	rus                []*oaiv1beta1.ChainPosition
+}
+
+// TestPlacementProperties places random RUs in random topologies and checks the invariants of every result
+func TestPlacementProperties(t *testing.T) {
+	disaggregations := newTestPlacement(t).disaggregation
+
+	property := func(seed int64) bool {
+		s, err := generateScenario(seed, disaggregations[dsg8Key])
+		if err != nil {
+			t.Logf("seed %d: error generating scenario: %s", seed, err)
+			return false
+		}
+
+		topologyGraph := NewPlacementBFS(s.topology, disaggregations, s.nodes, nil, s.requestedResources,
+			ctrllog.NullLogger{})
+		_, err = topologyGraph.Place(s.rus)
+		if fits := rusFit(s); err != nil || !fits {
+			// nothing is placed only when the RU nodes do not have resources for all RUs
+			if err == nil || fits {
+				t.Logf("seed %d: RUs fit %t, placement error: %v", seed, fits, err)
+				return false
+			}
+			return true
+		}
+
+		violations := placementViolations(s, topologyGraph.GetRemainingBandwidth())
+		for _, violation := range violations {
+			t.Logf("seed %d: %s", seed, violation)
+		}
+
+		return len(violations) == 0
+	}
+
+	if err := quick.Check(property, &quick.Config{MaxCount: 300}); err != nil {
+		t.Error(err)
+	}
+}
+
+func generateScenario(seed int64, disaggregation *oaiv1beta1.Disaggregation) (*scenario, error) {
+	r := rand.New(rand.NewSource(seed))
+
+	topology, err := generator.Generate(&generator.Options{
+		Shape:      shapes[r.Intn(len(shapes))],
+		Depth:      2 + r.Intn(3),
+		FanOut:     1 + r.Intn(3),
+		Nodes:      4 + r.Intn(30),
+		MeshDegree: r.Float64() * 0.3,
+		Capacity:   &generator.Distribution{Type: generator.Uniform, A: 150, B: 1200},
+		Delay:      &generator.Distribution{Type: generator.Uniform, A: 0.1, B: 1.5},
+		Seed:       r.Int63(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s := &scenario{
+		topology:       topology,
+		disaggregation: disaggregation,
+		nodes:          &v1.NodeList{},
+		requestedResources: &utils.RequestedResources{
+			Memory: *utils.NewQuantity("256Mi"),
+			CPU:    *utils.NewQuantity("150m"),
+		},
+	}
+
+	var nodeNames []string
+	for nodeName := range topology.Nodes {
+		cpu := resource.NewMilliQuantity(int64(200+r.Intn(1000)), resource.DecimalSI)
+		memory := resource.NewQuantity(int64(512+r.Intn(2048))*1024*1024, resource.BinarySI)
+		s.nodes.Items = append(s.nodes.Items, v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: nodeName},
+			Status: v1.NodeStatus{
+				Capacity:    v1.ResourceList{v1.ResourceCPU: *cpu, v1.ResourceMemory: *memory},
+				Allocatable: v1.ResourceList{v1.ResourceCPU: *cpu, v1.ResourceMemory: *memory},
+			},
+		})
+		if !topology.Nodes[nodeName].Core {
+			nodeNames = append(nodeNames, nodeName)
+		}
+	}
+
+	edgeNodes := generator.EdgeNodes(topology)
+	for i := 0; i < 1+r.Intn(15); i++ {
+		// most RUs go to the edge, the remaining ones check the nodes too close to the core are refused
+		ruNode := nodeNames[r.Intn(len(nodeNames))]
+		if len(edgeNodes) > 0 && r.Intn(4) > 0 {
+			ruNode = edgeNodes[r.Intn(len(edgeNodes))]
+		}
+		s.rus = append(s.rus, &oaiv1beta1.ChainPosition{SplitName: fmt.Sprintf("split%d", i), RUNode: ruNode})
+	}
+
+	return s, nil
+}
+
+// rusFit returns true if every RU node has the resources for the RU pieces placed in it
+func rusFit(s *scenario) bool {
+	rusPerNode := make(map[string]int64)
+	for _, ru := range s.rus {
+		rusPerNode[ru.RUNode]++
+	}
+
+	for _, node := range s.nodes.Items {
+		count := rusPerNode[node.Name]
+		cpu := s.requestedResources.CPU.MilliValue() * count
+		memory := s.requestedResources.Memory.Value() * count
+		if node.Status.Allocatable.Cpu().MilliValue() < cpu || node.Status.Allocatable.Memory().Value() < memory {
+			return false
+		}
+	}
+
+	return true
+}
+
+// placementViolations checks the placement result against the topology and the disaggregation requirements
+func placementViolations(s *scenario, remainingBandwidth map[string]*utils.Link) []string {
+	var violations []string
+
+	core := ""
+	for nodeName, node := range s.topology.Nodes {
+		if node.Core {
+			core = nodeName
+		}
+	}
+
+	links := make(map[string]string)
+	for linkName, link := range s.topology.Links {
+		links[link.Source.Node+"/"+link.Destination.Node] = linkName
+		links[link.Destination.Node+"/"+link.Source.Node] = linkName
+	}
+
+	nodesUsage := make(map[string]int64)
+	linksUsage := make(map[string]float32)
+	for _, ru := range s.rus {
+		// the RU piece is always allocated in the RU node
+		nodesUsage[ru.RUNode]++
+
+		if ru.CUNode == "" && ru.DUNode == "" {
+			if len(ru.Path) > 0 {
+				violations = append(violations, fmt.Sprintf("unallocated ru '%s' has a path", ru.SplitName))
+			}
+			continue
+		}
+
+		path := ru.Path
+		if ru.CUNode == "" || ru.DUNode == "" || len(path) == 0 {
+			violations = append(violations, fmt.Sprintf("ru '%s' partially allocated: cu '%s', du '%s', path %v",
+				ru.SplitName, ru.CUNode, ru.DUNode, path))
+			continue
+		}
+		nodesUsage[ru.CUNode]++
+		nodesUsage[ru.DUNode]++
+
+		if path[0] != core || path[len(path)-1] != ru.RUNode {
+			violations = append(violations, fmt.Sprintf("ru '%s' path %v does not go from the core to the ru node",
+				ru.SplitName, path))
+			continue
+		}
+
+		cuIndex, duIndex := indexOf(path, ru.CUNode), indexOf(path, ru.DUNode)
+		if cuIndex <= 0 || duIndex <= cuIndex || duIndex >= len(path)-1 {
+			violations = append(violations, fmt.Sprintf("ru '%s' path %v does not have core, cu '%s', du '%s' and ru in "+
+				"order", ru.SplitName, path, ru.CUNode, ru.DUNode))
+			continue
+		}
+
+		segments := []struct {
+			from, to    int
+			requirement *oaiv1beta1.NetworkRequirements
+		}{
+			{0, cuIndex, s.disaggregation.Backhaul},
+			{cuIndex, duIndex, s.disaggregation.Midhaul},
+			{duIndex, len(path) - 1, s.disaggregation.Fronthaul},
+		}
+		for _, segment := range segments {
+			var latency float32
+			for i := segment.from; i < segment.to; i++ {
+				linkName, exists := links[path[i]+"/"+path[i+1]]
+				if !exists {
+					violations = append(violations, fmt.Sprintf("ru '%s' path %v uses a link that does not exist",
+						ru.SplitName, path))
+					break
+				}
+				latency += s.topology.Links[linkName].LinkDelay
+				linksUsage[linkName] += segment.requirement.Bandwidth
+			}
+
+			if segment.requirement.Latency > 0 && latency > segment.requirement.Latency {
+				violations = append(violations, fmt.Sprintf("ru '%s' segment %v has latency %f over the budget %f",
+					ru.SplitName, path[segment.from:segment.to+1], latency, segment.requirement.Latency))
+			}
+		}
+	}
+
+	for _, node := range s.nodes.Items {
+		cpu := s.requestedResources.CPU.MilliValue() * nodesUsage[node.Name]
+		memory := s.requestedResources.Memory.Value() * nodesUsage[node.Name]
+		if cpu > node.Status.Allocatable.Cpu().MilliValue() || memory > node.Status.Allocatable.Memory().Value() {
+			violations = append(violations, fmt.Sprintf("node '%s' overcommitted with %d pieces", node.Name,
+				nodesUsage[node.Name]))
+		}
+	}
+
+	for linkName, link := range s.topology.Links {
+		if linksUsage[linkName] > link.LinkCapacity {
+			violations = append(violations, fmt.Sprintf("link '%s' overcommitted: %f of %f", linkName,
+				linksUsage[linkName], link.LinkCapacity))
+		}
+
+		remaining := remainingBandwidth[linkName].AvailableBandwidth
+		if diff := link.LinkCapacity - linksUsage[linkName] - remaining; diff > 0.01 || diff < -0.01 {
+			violations = append(violations, fmt.Sprintf("link '%s' remaining bandwidth %f does not match the usage %f",
+				linkName, remaining, linksUsage[linkName]))
+		}
+	}
+
+	return violations
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/go-logr/logr"
 	oaiv1beta1 "github.com/juliorenner/oai-k8s/operator/api/v1beta1"
 	"github.com/juliorenner/oai-k8s/operator/controllers/utils"
 	. "github.com/onsi/gomega"
@@ -21,21 +22,47 @@ var disaggregationJSON = "{\r\n    \"1\": {\r\n        \"protocolStack\": {\r\n
 	"\"PDCP\"],\r\n            \"du\": [\"RLCH\", \"RLCL\", \"MACH\", \"MACL\"],\r\n            \"ru\": [\"PHYH\", \"PHYL\", \"RF\"]\r\n        },\r\n        \"splitOptions\": {\r\n            \"cu-du\": \"O2\",\r\n            \"du-ru\": \"O6\"\r\n        },\r\n        \"backhaul\": {\r\n            \"bandwidth\": 151\r\n        },\r\n        \"midhaul\": {\r\n            \"latency\": 30,\r\n            \"bandwidth\": 151\r\n        },\r\n        \"fronthaul\": {\r\n            \"latency\": 2,\r\n            \"bandwidth\": 152\r\n        },\r\n        \"crosshaul\": {\r\n            \"latency\": 30\r\n        }\r\n    },\r\n    \"2\": {\r\n        \"protocolStack\": {\r\n            \"cu\": [\"RRC\", \"PDCP\"],\r\n            \"du\": [],\r\n            \"ru\": [\"RLCH\", \"RLCL\", \"MACH\", \"MACL\", \"PHYH\", \"PHYL\", \"RF\"]\r\n        },\r\n        \"backhaul\": {\r\n            \"bandwidth\": 151\r\n        },\r\n        \"midhaul\": {},\r\n        \"fronthaul\": {\r\n            \"bandwidth\": 151\r\n        },\r\n        \"crosshaul\": {\r\n            \"latency\": 30\r\n        }\r\n    },\r\n    \"3\": {\r\n        \"protocolStack\": {\r\n            \"cu\": [\"RRC\", \"PDCP\", \"RLCH\", \"RLCL\", \"MACH\", \"MACL\"],\r\n            \"du\": [],\r\n            \"ru\": [\"PHYH\", \"PHYL\", \"RF\"]\r\n        },\r\n        \"backhaul\": {\r\n            \"bandwidth\": 151\r\n        },\r\n        \"midhaul\": {},\r\n        \"fronthaul\": {\r\n            \"latency\": 2,\r\n            \"bandwidth\": 152\r\n        },\r\n        \"crosshaul\": {\r\n            \"latency\": 30\r\n        }\r\n    },\r\n    \"4\": {\r\n        \"protocolStack\": {\r\n            \"cu\": [\"RRC\", \"PDCP\", \"RLCH\", \"RLCL\", \"MACH\", \"MACL\", \"PHYH\", \"PHYL\", \"RF\"],\r\n            \"du\": [],\r\n            \"ru\": []\r\n        },\r\n        \"backhaul\": {},\r\n        \"midhaul\": {},\r\n        \"fronthaul\": {},\r\n        \"crosshaul\": {\r\n            \"latency\": 30\r\n        }\r\n    }\r\n}"
 
 func TestQuantity(t *testing.T) {
+	RegisterTestingT(t)
+
 	memory := utils.NewQuantity("500Mi")
 	cpu := utils.NewQuantity("500m")
 
 	memoryNode := utils.NewQuantity("16397940Ki")
 	cpuNode := utils.NewQuantity("3800m")
 
-	fmt.Println(memoryNode.ScaledValue(resource.Mega))
+	Expect(memoryNode.ScaledValue(resource.Mega)).To(BeNumerically("==", 16792))
 
 	memoryNode.Sub(*memory)
 	cpuNode.Sub(*cpu)
 
-	fmt.Println(memoryNode.Value())
-	fmt.Println(cpuNode.Value())
-	fmt.Println(memoryNode.ScaledValue(resource.Mega))
-	fmt.Println(cpuNode)
+	Expect(memoryNode.Value()).To(BeNumerically("==", 16397940*1024-500*1024*1024))
+	Expect(cpuNode.MilliValue()).To(BeNumerically("==", 3300))
+	// Value rounds up, so CPU must always be compared with MilliValue
+	Expect(cpuNode.Value()).To(BeNumerically("==", 4))
+	Expect(memoryNode.ScaledValue(resource.Mega)).To(BeNumerically("==", 16268))
+}
+
+func TestAllocateHugePages(t *testing.T) {
+	RegisterTestingT(t)
+
+	log := zap.New(zap.UseDevMode(true))
+	newNode := func(hugePages *resource.Quantity) *utils.Node {
+		return &utils.Node{NodeName: "node1", Resources: &utils.Resources{
+			MemoryAvailable:       utils.NewQuantity("8Gi"),
+			CPUAvailable:          utils.NewQuantity("4"),
+			HugePages1GiAvailable: hugePages,
+		}}
+	}
+	requested := &utils.RequestedResources{HugePages1Gi: *utils.NewQuantity("2Gi")}
+
+	// the pieces taking all the huge pages left fit exactly
+	node := newNode(utils.NewQuantity("2Gi"))
+	Expect(node.AllocateResources(requested, log)).To(Succeed())
+	Expect(node.Resources.HugePages1GiAvailable.IsZero()).To(BeTrue())
+
+	Expect(newNode(utils.NewQuantity("1Gi")).AllocateResources(requested, log)).NotTo(Succeed())
+	Expect(newNode(nil).AllocateResources(requested, log)).NotTo(Succeed())
+	Expect(newNode(nil).AllocateResources(&utils.RequestedResources{}, log)).To(Succeed())
 }
 
 func TestPlacementAlgorithm(t *testing.T) {
@@ -81,7 +108,7 @@ func TestPlacementAlgorithm(t *testing.T) {
 			if err := json.Unmarshal([]byte(topologyJSON), topology); err != nil {
 				t.Fatalf("error unmarshaling topology: %s", err)
 			}
-			topologyGraph := NewPlacementBFS(topology, disaggregation, k8sNode, requestedResources, log)
+			topologyGraph := NewPlacementBFS(topology, disaggregation, k8sNode, nil, requestedResources, log)
 
 			valid, err := topologyGraph.Place(tc.rus)
 			Expect(valid).To(Equal(tc.isValid))
@@ -102,6 +129,578 @@ func TestPlacementAlgorithm(t *testing.T) {
 	}
 }
 
+func TestPlacementCandidates(t *testing.T) {
+	RegisterTestingT(t)
+
+	tp := newTestPlacement(t)
+
+	topologyGraph := tp.graph(generateNodeList(), nil)
+
+	ru := &oaiv1beta1.ChainPosition{SplitName: "split0", RUNode: "node6"}
+	candidates := topologyGraph.Candidates(ru)
+	Expect(candidates).To(HaveLen(4))
+	for _, candidate := range candidates {
+		Expect(candidate.Path[0]).To(Equal("node14"))
+		Expect(candidate.Path[len(candidate.Path)-1]).To(Equal("node6"))
+		Expect(candidate.CUNode).To(Equal(candidate.Path[1]))
+		Expect(candidate.DUNode).To(Equal(candidate.Path[2]))
+	}
+	Expect(ru.CUNode).To(BeEmpty())
+
+	ru.CUNode = "node2"
+	candidates = topologyGraph.Candidates(ru)
+	Expect(candidates).To(HaveLen(2))
+	for _, candidate := range candidates {
+		Expect(candidate.CUNode).To(Equal("node2"))
+	}
+
+	Expect(topologyGraph.ReserveNetwork(candidates[0])).To(Succeed())
+	Expect(topologyGraph.GetRemainingBandwidth()["node2--node14"].AvailableBandwidth).To(BeNumerically("==", 1049))
+	topologyGraph.ReleaseNetwork(candidates[0])
+	for _, link := range topologyGraph.GetRemainingBandwidth() {
+		Expect(link.AvailableBandwidth).To(Equal(tp.topology.Links[link.LinkName].LinkCapacity))
+	}
+}
+
+func TestPlacementReservedLinks(t *testing.T) {
+	RegisterTestingT(t)
+
+	tp := newTestPlacement(t)
+
+	topologyGraph := tp.graph(generateNodeList(), nil)
+
+	ru := &oaiv1beta1.ChainPosition{SplitName: "split0", RUNode: "node6", CUNode: "node2"}
+	chain := topologyGraph.Candidates(ru)[0]
+	links := topologyGraph.ChainLinks(chain)
+	Expect(links).To(HaveLen(len(chain.Path) - 1))
+
+	// the reservations of another placer are deducted the same way the chain network is
+	otherGraph := tp.graph(generateNodeList(), nil)
+	otherGraph.ReserveLinks(links)
+	Expect(topologyGraph.ReserveNetwork(chain)).To(Succeed())
+	for linkName, link := range topologyGraph.GetRemainingBandwidth() {
+		Expect(otherGraph.GetRemainingBandwidth()[linkName].AvailableBandwidth).To(Equal(link.AvailableBandwidth))
+	}
+
+	otherGraph.ReleaseLinks(links)
+	for _, link := range otherGraph.GetRemainingBandwidth() {
+		Expect(link.AvailableBandwidth).To(Equal(tp.topology.Links[link.LinkName].LinkCapacity))
+	}
+}
+
+func TestPlacementRejections(t *testing.T) {
+	RegisterTestingT(t)
+
+	tp := newTestPlacement(t)
+
+	topologyGraph := tp.graph(generateNodeList(), nil)
+
+	rus := generateRUs("node6", "node6", "node6", "node14", "node1")
+	_, err := topologyGraph.Place(rus)
+	Expect(err).NotTo(HaveOccurred())
+
+	rejections := topologyGraph.GetRejections()
+	Expect(rejections).To(HaveLen(3))
+	Expect(rejections).NotTo(HaveKey("split0"))
+	Expect(rejections).NotTo(HaveKey("split1"))
+
+	Expect(rejections["split2"]).NotTo(BeEmpty())
+	for _, rejection := range rejections["split2"] {
+		Expect(rejection.Path[len(rejection.Path)-1]).To(Equal("node6"))
+		Expect(rejection.Reason).To(ContainSubstring("short of bandwidth"))
+	}
+
+	Expect(rejections["split3"]).To(HaveLen(1))
+	Expect(rejections["split3"][0].Reason).To(ContainSubstring("too short"))
+	Expect(rejections["split4"]).To(HaveLen(1))
+	Expect(rejections["split4"][0].Reason).To(ContainSubstring("too short"))
+}
+
+func TestPlacementMultiCore(t *testing.T) {
+	RegisterTestingT(t)
+
+	// node15 is a second core, two hops closer to node13 than node14
+	tp := newTestPlacement(t)
+	tp.topology.Nodes["node15"] = &oaiv1beta1.Node{Interfaces: []string{"eth0"}, Core: true}
+	tp.topology.Links["node5--node15"] = &oaiv1beta1.Link{
+		LinkCapacity: 1200,
+		LinkDelay:    1,
+		Source:       oaiv1beta1.Connection{Node: "node5", Interface: "eth4"},
+		Destination:  oaiv1beta1.Connection{Node: "node15", Interface: "eth0"},
+	}
+
+	topologyGraph := tp.graph(generateNodeList(), nil)
+	rus := generateRUs("node13", "node6")
+	rus[1].CoreNode = "node15"
+	_, err := topologyGraph.Place(rus)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(rus[0].CoreNode).To(Equal("node15"))
+	Expect(rus[0].Path).To(Equal([]string{"node15", "node5", "node11", "node12", "node13"}))
+	Expect(rus[1].CoreNode).To(Equal("node15"))
+	Expect(rus[1].Path[0]).To(Equal("node15"))
+
+	topologyGraph = tp.graph(generateNodeList(), nil)
+	topologyGraph.SetCoreSelection(oaiv1beta1.CoreSelectionLeastLoaded)
+	topologyGraph.ReserveCores(map[string]int{"node15": 1})
+	rus = generateRUs("node13", "node6")
+	_, err = topologyGraph.Place(rus)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(rus[0].CoreNode).To(Equal("node14"))
+	Expect(rus[1].CoreNode).To(Equal("node14"))
+
+	// pinned to a node that is not a core
+	rus = generateRUs("node7")
+	rus[0].CoreNode = "node1"
+	_, err = topologyGraph.Place(rus)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(rus[0].CUNode).To(BeEmpty())
+	Expect(topologyGraph.GetRejections()["split0"][0].Reason).To(ContainSubstring("not a core"))
+}
+
+func TestPlacementNR(t *testing.T) {
+	RegisterTestingT(t)
+
+	tp := newTestPlacement(t)
+	nr := tp.disaggregation[dsg8Key].DeepCopy()
+	nr.E1 = &oaiv1beta1.NetworkRequirements{Latency: 10, Bandwidth: 50}
+	tp.disaggregation[dsg8NRKey] = nr
+
+	topologyGraph := tp.graph(generateNodeList(), nil)
+	topologyGraph.SetRAT(oaiv1beta1.RATNR)
+
+	rus := generateRUs("node13", "node6")
+	_, err := topologyGraph.Place(rus)
+	Expect(err).NotTo(HaveOccurred())
+
+	Expect(topologyGraph.Placed(rus[0])).To(BeTrue())
+	Expect(rus[0].Disaggregation).To(Equal(dsg8NRKey))
+	cuIndex, cuupIndex, duIndex := indexOf(rus[0].Path, rus[0].CUNode), indexOf(rus[0].Path, rus[0].CUUPNode),
+		indexOf(rus[0].Path, rus[0].DUNode)
+	Expect(cuIndex).To(Equal(1))
+	Expect(cuupIndex).To(BeNumerically(">", cuIndex))
+	Expect(duIndex).To(BeNumerically(">", cuupIndex))
+
+	// the E1 segment goes from the CU-CP to the CU-UP
+	links := topologyGraph.ChainLinks(rus[0])
+	Expect(links).To(HaveLen(len(rus[0].Path) - 1))
+	e1Link := topologyGraph.nodes[rus[0].CUNode].Links[rus[0].Path[cuIndex+1]].LinkName
+	Expect(links[e1Link]).To(BeNumerically("==", 50))
+
+	// node6 is three hops from the core, too close for the four pieces
+	Expect(topologyGraph.Placed(rus[1])).To(BeFalse())
+	Expect(topologyGraph.GetRejections()["split1"][0].Reason).To(ContainSubstring("CU-UP"))
+}
+
+func TestPlacementNodesUsage(t *testing.T) {
+	RegisterTestingT(t)
+
+	tp := newTestPlacement(t)
+
+	// CU candidates are node1 and node2, both without CPU left for a new split
+	nodesUsage := utils.PodsRequestedResources([]v1.Pod{
+		generatePod("node1", "3800m"),
+		generatePod("node2", "3600m"),
+		generatePod("node13", "100m"),
+	})
+	topologyGraph := tp.graph(generateNodeList(), nodesUsage)
+
+	rus := generateRUs("node6")
+	valid, err := topologyGraph.Place(rus)
+	Expect(valid).To(BeTrue())
+	Expect(err).To(BeNil())
+	Expect(rus[0].CUNode).To(BeEmpty())
+	Expect(rus[0].DUNode).To(BeEmpty())
+}
+
+func TestPlacementPieceResources(t *testing.T) {
+	RegisterTestingT(t)
+
+	tp := newTestPlacement(t)
+
+	// only node12 has huge pages, node4 and node11 are closer to the core but cannot run the DU
+	nodeList := generateNodeList()
+	for i := range nodeList.Items {
+		if nodeList.Items[i].Name == "node12" {
+			nodeList.Items[i].Status.Allocatable[utils.ResourceHugePages1Gi] = *utils.NewQuantity("2Gi")
+		}
+	}
+
+	topologyGraph := tp.graph(nodeList, nil)
+	topologyGraph.SetPieceResources(PieceDU, &utils.RequestedResources{
+		Memory:        *utils.NewQuantity("1Gi"),
+		CPU:           *utils.NewQuantity("50m"),
+		ExclusiveCPUs: 2,
+		HugePages1Gi:  *utils.NewQuantity("1Gi"),
+	})
+
+	rus := generateRUs("node13")
+	_, err := topologyGraph.Place(rus)
+	Expect(err).NotTo(HaveOccurred())
+
+	Expect(rus[0].DUNode).To(Equal("node12"))
+	Expect(topologyGraph.nodes["node12"].Resources.HugePages1GiAvailable.String()).To(Equal("1Gi"))
+	Expect(topologyGraph.nodes["node12"].Resources.CPUAvailable.MilliValue()).To(BeNumerically("==", 1950))
+
+	// without huge pages in any node the DU is not placed
+	topologyGraph = tp.graph(generateNodeList(), nil)
+	topologyGraph.SetPieceResources(PieceDU, &utils.RequestedResources{HugePages1Gi: *utils.NewQuantity("1Gi")})
+
+	rus = generateRUs("node13")
+	_, err = topologyGraph.Place(rus)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(rus[0].DUNode).To(BeEmpty())
+	Expect(topologyGraph.GetRejections()["split0"][0].Reason).To(ContainSubstring("no DU candidate"))
+
+	// the exclusive CPUs are whole cores left after the shared CPU
+	node := &utils.Node{NodeName: "node", Resources: &utils.Resources{MemoryAvailable: utils.NewQuantity("1Gi"),
+		CPUAvailable: utils.NewQuantity("3500m")}}
+	Expect(node.HasResources(&utils.RequestedResources{CPU: *utils.NewQuantity("100m"), ExclusiveCPUs: 3})).
+		To(BeTrue())
+	Expect(node.HasResources(&utils.RequestedResources{CPU: *utils.NewQuantity("600m"), ExclusiveCPUs: 3})).
+		To(BeFalse())
+}
+
+func TestPlacementConstraints(t *testing.T) {
+	RegisterTestingT(t)
+
+	tp := newTestPlacement(t)
+
+	// node1 never hosts a CU and node12 is the only node with an accelerator card for DUs
+	nodeList := generateNodeList()
+	for i := range nodeList.Items {
+		switch nodeList.Items[i].Name {
+		case "node1":
+			nodeList.Items[i].Spec.Taints = []v1.Taint{{Key: "dedicated", Value: "transport",
+				Effect: v1.TaintEffectNoSchedule}}
+		case "node12":
+			nodeList.Items[i].Labels = map[string]string{"accelerator": "true"}
+		}
+	}
+
+	topologyGraph := tp.graph(nodeList, nil)
+	topologyGraph.SetConstraints(&oaiv1beta1.PlacementConstraints{
+		DU:           &oaiv1beta1.RoleConstraints{NodeSelector: map[string]string{"accelerator": "true"}},
+		AntiAffinity: []oaiv1beta1.PlacementRole{oaiv1beta1.PlacementRoleCU},
+	})
+
+	rus := generateRUs("node13", "node9")
+	_, err := topologyGraph.Place(rus)
+	Expect(err).NotTo(HaveOccurred())
+
+	Expect(rus[0].CUNode).To(Equal("node2"))
+	Expect(rus[0].DUNode).To(Equal("node12"))
+
+	// node2 already hosts the CU of the first chain and node1 taint is not tolerated
+	Expect(rus[1].CUNode).To(BeEmpty())
+	var reasons []string
+	for _, rejection := range topologyGraph.GetRejections()["split1"] {
+		reasons = append(reasons, rejection.Reason)
+	}
+	Expect(reasons).To(ContainElement(ContainSubstring("already hosts the CU of another chain")))
+	Expect(reasons).To(ContainElement(ContainSubstring("taint dedicated=transport:NoSchedule not tolerated")))
+
+	// tolerating the taint makes node1 eligible
+	topologyGraph = tp.graph(nodeList, nil)
+	topologyGraph.SetConstraints(&oaiv1beta1.PlacementConstraints{
+		CU: &oaiv1beta1.RoleConstraints{Tolerations: []v1.Toleration{{Key: "dedicated",
+			Operator: v1.TolerationOpExists}}},
+		AntiAffinity: []oaiv1beta1.PlacementRole{oaiv1beta1.PlacementRoleCU},
+	})
+
+	rus = generateRUs("node13", "node9")
+	_, err = topologyGraph.Place(rus)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(rus[0].CUNode).NotTo(BeEmpty())
+	Expect(rus[1].CUNode).NotTo(BeEmpty())
+	Expect(rus[1].CUNode).NotTo(Equal(rus[0].CUNode))
+}
+
+func TestPlacementChainConstraints(t *testing.T) {
+	RegisterTestingT(t)
+
+	tp := newTestPlacement(t)
+
+	testCases := []struct {
+		name          string
+		constraints   *oaiv1beta1.ChainConstraints
+		expectedCU    string
+		expectedDU    string
+		expectedError string
+	}{
+		{
+			name:        "pinned du",
+			constraints: &oaiv1beta1.ChainConstraints{DUNode: "node12"},
+			expectedDU:  "node12",
+		},
+		{
+			name:        "pinned cu",
+			constraints: &oaiv1beta1.ChainConstraints{CUNode: "node1"},
+			expectedCU:  "node1",
+		},
+		{
+			name:        "forbidden nodes",
+			constraints: &oaiv1beta1.ChainConstraints{ForbiddenNodes: []string{"node2", "node5"}},
+			expectedCU:  "node1",
+		},
+		{
+			name:        "allowed nodes",
+			constraints: &oaiv1beta1.ChainConstraints{AllowedNodes: []string{"node1", "node11"}},
+			expectedCU:  "node1",
+			expectedDU:  "node11",
+		},
+		{
+			name:          "pinned du out of the path",
+			constraints:   &oaiv1beta1.ChainConstraints{DUNode: "node3"},
+			expectedError: "DU pinned to node 'node3' out of the path",
+		},
+		{
+			name:          "max hops",
+			constraints:   &oaiv1beta1.ChainConstraints{MaxHops: 4},
+			expectedError: "path has 5 hops, over the chain limit of 4",
+		},
+		{
+			name: "latency override",
+			constraints: &oaiv1beta1.ChainConstraints{DUNode: "node11",
+				Latency: &oaiv1beta1.LatencyBudget{Fronthaul: "1"}},
+			expectedError: "fronthaul latency exceeded by 0.250000",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			RegisterTestingT(t)
+
+			topologyGraph := tp.graph(generateNodeList(), nil)
+			rus := generateRUs("node13")
+			rus[0].Constraints = tc.constraints
+
+			_, err := topologyGraph.Place(rus)
+			Expect(err).NotTo(HaveOccurred())
+
+			if tc.expectedError != "" {
+				Expect(rus[0].DUNode).To(BeEmpty())
+				Expect(topologyGraph.GetRejections()["split0"][0].Reason).To(ContainSubstring(tc.expectedError))
+				return
+			}
+
+			// paths of the same length are tried in any order, only the nodes decided by the constraints are checked
+			Expect(rus[0].DUNode).NotTo(BeEmpty())
+			if tc.expectedCU != "" {
+				Expect(rus[0].CUNode).To(Equal(tc.expectedCU))
+			}
+			if tc.expectedDU != "" {
+				Expect(rus[0].DUNode).To(Equal(tc.expectedDU))
+			}
+			for _, nodeName := range []string{rus[0].CUNode, rus[0].DUNode, rus[0].CUUPNode} {
+				Expect(tc.constraints.ForbiddenNodes).NotTo(ContainElement(nodeName))
+			}
+		})
+	}
+}
+
+func TestPlacementPriority(t *testing.T) {
+	RegisterTestingT(t)
+
+	tp := newTestPlacement(t)
+
+	// the link between node12 and node13 only fits one fronthaul, the RU with higher priority wins it
+	rus := generateRUs("node13", "node13")
+	rus[1].Priority = 1
+
+	topologyGraph := tp.graph(generateNodeList(), nil)
+	_, err := topologyGraph.Place(rus)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(rus[0].DUNode).To(BeEmpty())
+	Expect(rus[1].DUNode).NotTo(BeEmpty())
+	Expect(topologyGraph.GetEvictions()).To(BeEmpty())
+
+	// a chain already placed keeps its position without preemption
+	placed := generateRUs("node13")
+	topologyGraph = tp.graph(generateNodeList(), nil)
+	_, err = topologyGraph.Place(placed)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(placed[0].DUNode).NotTo(BeEmpty())
+
+	rus = []*oaiv1beta1.ChainPosition{placed[0].DeepCopy(), {SplitName: "split1", RUNode: "node13", Priority: 1}}
+	topologyGraph = tp.graph(generateNodeList(), nil)
+	_, err = topologyGraph.Place(rus)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(rus[0].Path).To(Equal(placed[0].Path))
+	Expect(rus[1].DUNode).To(BeEmpty())
+	Expect(topologyGraph.GetRejections()).To(HaveKey("split1"))
+
+	// with preemption it is evicted and left unallocated
+	rus = []*oaiv1beta1.ChainPosition{placed[0].DeepCopy(), {SplitName: "split1", RUNode: "node13", Priority: 1}}
+	topologyGraph = tp.graph(generateNodeList(), nil)
+	topologyGraph.SetPreemption(true)
+	_, err = topologyGraph.Place(rus)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(rus[0].DUNode).To(BeEmpty())
+	Expect(rus[0].Path).To(BeEmpty())
+	Expect(rus[1].DUNode).NotTo(BeEmpty())
+	Expect(topologyGraph.GetEvictions()).To(Equal([]*oaiv1beta1.Eviction{{SplitName: "split0",
+		PreemptedBy: "split1", Reallocated: false}}))
+	Expect(topologyGraph.GetRejections()).To(HaveKey("split0"))
+	Expect(topologyGraph.GetRejections()).NotTo(HaveKey("split1"))
+
+	// chains of the same priority are not preempted
+	rus = []*oaiv1beta1.ChainPosition{placed[0].DeepCopy(), {SplitName: "split1", RUNode: "node13"}}
+	topologyGraph = tp.graph(generateNodeList(), nil)
+	topologyGraph.SetPreemption(true)
+	_, err = topologyGraph.Place(rus)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(rus[0].Path).To(Equal(placed[0].Path))
+	Expect(rus[1].DUNode).To(BeEmpty())
+	Expect(topologyGraph.GetEvictions()).To(BeEmpty())
+}
+
+func TestPlacementKeepChain(t *testing.T) {
+	RegisterTestingT(t)
+
+	tp := newTestPlacement(t)
+
+	placed := generateRUs("node13")
+	_, err := tp.graph(generateNodeList(), nil).Place(placed)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(placed[0].DUNode).To(Equal("node4"))
+
+	// the recorded DU is kept, even if it is not the first DU candidate of the path
+	rus := []*oaiv1beta1.ChainPosition{placed[0].DeepCopy()}
+	rus[0].DUNode = "node12"
+	_, err = tp.graph(generateNodeList(), nil).Place(rus)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(rus[0].Path).To(Equal(placed[0].Path))
+	Expect(rus[0].CUNode).To(Equal(placed[0].CUNode))
+	Expect(rus[0].DUNode).To(Equal("node12"))
+
+	// a chain whose DU is out of its path is placed again
+	rus = []*oaiv1beta1.ChainPosition{placed[0].DeepCopy()}
+	rus[0].DUNode = "node3"
+	_, err = tp.graph(generateNodeList(), nil).Place(rus)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(rus[0].DUNode).To(Equal("node4"))
+}
+
+func TestPlacementRollback(t *testing.T) {
+	RegisterTestingT(t)
+
+	tp := newTestPlacement(t)
+
+	// the second RU does not fit the link between node12 and node13 and its RU node reservation is released
+	topologyGraph := tp.graph(generateNodeList(), nil)
+	rus := generateRUs("node13", "node13")
+	_, err := topologyGraph.Place(rus)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(rus[1].DUNode).To(BeEmpty())
+	Expect(topologyGraph.nodes["node13"].Resources.CPUAvailable.MilliValue()).To(Equal(int64(3500)))
+
+	// a chain failing midway on bandwidth gives back its nodes and the links already reserved
+	bandwidth := make(map[string]float32)
+	for linkName, link := range topologyGraph.GetRemainingBandwidth() {
+		bandwidth[linkName] = link.AvailableBandwidth
+	}
+	cuCPU := topologyGraph.nodes[rus[0].CUNode].Resources.CPUAvailable.MilliValue()
+	duCPU := topologyGraph.nodes[rus[0].DUNode].Resources.CPUAvailable.MilliValue()
+
+	chain := rus[0].DeepCopy()
+	chain.SplitName = "split1"
+	Expect(topologyGraph.newDsg8().AllocateResources(chain)).NotTo(Succeed())
+
+	for linkName, link := range topologyGraph.GetRemainingBandwidth() {
+		Expect(link.AvailableBandwidth).To(Equal(bandwidth[linkName]), linkName)
+	}
+	Expect(topologyGraph.nodes[rus[0].CUNode].Resources.CPUAvailable.MilliValue()).To(Equal(cuCPU))
+	Expect(topologyGraph.nodes[rus[0].DUNode].Resources.CPUAvailable.MilliValue()).To(Equal(duCPU))
+	Expect(topologyGraph.constraints.piecesNodes[PieceCU][rus[0].CUNode]).To(Equal(1))
+
+	// the RU nodes are all reserved or none
+	topologyGraph = tp.graph(generateNodeList(), nil)
+	topologyGraph.SetPieceResources(PieceRU, &utils.RequestedResources{
+		Memory: *utils.NewQuantity("512Mi"),
+		CPU:    *utils.NewQuantity("3000m"),
+	})
+	_, err = topologyGraph.Place(generateRUs("node9", "node13", "node13"))
+	Expect(err).To(HaveOccurred())
+	Expect(topologyGraph.nodes["node9"].Resources.CPUAvailable.MilliValue()).To(Equal(int64(4000)))
+	Expect(topologyGraph.nodes["node13"].Resources.CPUAvailable.MilliValue()).To(Equal(int64(4000)))
+}
+
+func TestPlacementChainLatency(t *testing.T) {
+	RegisterTestingT(t)
+
+	tp := newTestPlacement(t)
+
+	topologyGraph := tp.graph(generateNodeList(), nil)
+	rus := generateRUs("node13")
+	_, err := topologyGraph.Place(rus)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(rus[0].DUNode).NotTo(BeEmpty())
+
+	// the segments split the links of the path, so their latency adds up to the path latency
+	var pathLatency float32
+	for i := 0; i+1 < len(rus[0].Path); i++ {
+		pathLatency += topologyGraph.nodes[rus[0].Path[i]].Links[rus[0].Path[i+1]].Latency
+	}
+
+	latency := topologyGraph.ChainLatency(rus[0])
+	Expect(latency).To(HaveLen(3))
+	Expect(latency["backhaul"] + latency["midhaul"] + latency["fronthaul"]).To(BeNumerically("~", pathLatency, 0.001))
+	Expect(latency["fronthaul"]).To(BeNumerically("<=", tp.disaggregation[dsg8Key].Fronthaul.Latency))
+}
+
+// testPlacement keeps the fixtures shared by the placement tests
+type testPlacement struct {
+	disaggregation     map[string]*oaiv1beta1.Disaggregation
+	topology           *oaiv1beta1.Topology
+	requestedResources *utils.RequestedResources
+	log                logr.Logger
+}
+
+// newTestPlacement parses the disaggregation and topology fixtures, every call returns new copies that can be changed
+// by the test
+func newTestPlacement(t testing.TB) *testPlacement {
+	tp := &testPlacement{
+		disaggregation: map[string]*oaiv1beta1.Disaggregation{},
+		topology:       &oaiv1beta1.Topology{},
+		requestedResources: &utils.RequestedResources{
+			Memory: *utils.NewQuantity("512Mi"),
+			CPU:    *utils.NewQuantity("500m"),
+		},
+		log: zap.New(zap.UseDevMode(true)),
+	}
+
+	if err := json.Unmarshal([]byte(disaggregationJSON), &tp.disaggregation); err != nil {
+		t.Fatalf("error unmarshaling disaggregation: %s", err)
+	}
+	if err := json.Unmarshal([]byte(topologyJSON), tp.topology); err != nil {
+		t.Fatalf("error unmarshaling topology: %s", err)
+	}
+
+	return tp
+}
+
+// graph returns a placement of the fixtures over the nodes given
+func (tp *testPlacement) graph(nodeList *v1.NodeList, nodesUsage map[string]*utils.RequestedResources) *PlacementBFS {
+	return NewPlacementBFS(tp.topology, tp.disaggregation, nodeList, nodesUsage, tp.requestedResources, tp.log)
+}
+
+func generatePod(nodeName, cpu string) v1.Pod {
+	return v1.Pod{
+		Spec: v1.PodSpec{
+			NodeName: nodeName,
+			Containers: []v1.Container{
+				{
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{
+							v1.ResourceCPU: *utils.NewQuantity(cpu),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 func generateRUs(nodes ...string) []*oaiv1beta1.ChainPosition {
 	var rus []*oaiv1beta1.ChainPosition
 	for i, node := range nodes {
@@ -0,0 +1,124 @@
+package algorithm
+
+import (
+	"sort"
+
+	oaiv1beta1 "github.com/juliorenner/oai-k8s/operator/api/v1beta1"
+	"github.com/juliorenner/oai-k8s/operator/controllers/utils"
+)
+
+// SetPreemption enables evicting the running chains of lower priority from the resources taken by chains of higher
+// priority.
+func (p *PlacementBFS) SetPreemption(preemption bool) {
+	p.preemption = preemption
+}
+
+// GetEvictions returns the chains evicted by chains of higher priority
+func (p *PlacementBFS) GetEvictions() []*oaiv1beta1.Eviction {
+	return p.evictions
+}
+
+// byPriority returns the RUs from the highest to the lowest priority, keeping the list order among the RUs with the
+// same priority
+func byPriority(rus []*oaiv1beta1.ChainPosition) []*oaiv1beta1.ChainPosition {
+	ordered := make([]*oaiv1beta1.ChainPosition, len(rus))
+	copy(ordered, rus)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Priority > ordered[j].Priority
+	})
+
+	return ordered
+}
+
+// placementOrder returns the order the RUs are placed in. With preemption, the RUs follow their priority and, among
+// the RUs of the same priority, the ones holding a position from a previous placement go first, so the chains of
+// higher priority take the resources of the running chains of lower priority. Without preemption the running chains
+// are never moved, they all go first.
+func (p *PlacementBFS) placementOrder(rus []*oaiv1beta1.ChainPosition) []*oaiv1beta1.ChainPosition {
+	ordered := byPriority(rus)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if p.preemption && ordered[i].Priority != ordered[j].Priority {
+			return ordered[i].Priority > ordered[j].Priority
+		}
+
+		return p.hasPosition(ordered[i]) && !p.hasPosition(ordered[j])
+	})
+
+	return ordered
+}
+
+// hasPosition returns true if the RU holds a position from a previous placement: its CU, its DU and a path from a
+// core of the topology to its node
+func (p *PlacementBFS) hasPosition(ru *oaiv1beta1.ChainPosition) bool {
+	return ru.CUNode != "" && ru.DUNode != "" && len(ru.Path) > 0 && ru.Path[len(ru.Path)-1] == ru.RUNode &&
+		utils.ContainsString(p.cores, ru.Path[0])
+}
+
+// keepChain places the RU holding a position from a previous placement in the same path and nodes. If the chain does
+// not fit there anymore, its position is cleared and false is returned, so it is placed again as a pending RU.
+func (p *PlacementBFS) keepChain(dsg8 *disaggregation8, ru *oaiv1beta1.ChainPosition) bool {
+	if !p.hasPosition(ru) {
+		return false
+	}
+
+	pos, reason := dsg8.keep(ru)
+	if pos == nil {
+		p.log.Info("chain does not fit its previous position anymore", "ru", ru.SplitName, "path", ru.Path,
+			"reason", reason)
+		clearPosition(ru)
+		return false
+	}
+
+	fulfillRU(ru, pos)
+	if err := dsg8.AllocateResources(ru); err != nil {
+		p.log.Error(err, "error keeping chain in its previous position", "ru", ru.SplitName)
+		clearPosition(ru)
+		return false
+	}
+	p.coreChains[ru.CoreNode]++
+
+	return true
+}
+
+// preemptedBy returns the first placed chain of higher priority than the RU sharing a piece node or a link with its
+// previous position, nil if the RU lost its position for other reasons
+func preemptedBy(ru *oaiv1beta1.ChainPosition, previous []string,
+	placed []*oaiv1beta1.ChainPosition) *oaiv1beta1.ChainPosition {
+	previousLinks := pathLinks(previous)
+	for _, chain := range placed {
+		if chain.Priority <= ru.Priority {
+			continue
+		}
+
+		for _, nodeName := range []string{chain.CUNode, chain.CUUPNode, chain.DUNode} {
+			if nodeName != "" && utils.ContainsString(previous, nodeName) {
+				return chain
+			}
+		}
+		for link := range pathLinks(chain.Path) {
+			if previousLinks[link] {
+				return chain
+			}
+		}
+	}
+
+	return nil
+}
+
+// pathLinks returns the links of the path, each one keyed by its nodes in both directions
+func pathLinks(path []string) map[string]bool {
+	links := map[string]bool{}
+	for i := 1; i < len(path); i++ {
+		links[path[i-1]+"--"+path[i]] = true
+		links[path[i]+"--"+path[i-1]] = true
+	}
+
+	return links
+}
+
+// clearPosition removes the nodes and the path of a chain not placed
+func clearPosition(ru *oaiv1beta1.ChainPosition) {
+	ru.CUNode, ru.CUUPNode, ru.DUNode = "", "", ""
+	ru.Path = nil
+	ru.Disaggregation = ""
+}
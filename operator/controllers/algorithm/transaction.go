@@ -0,0 +1,47 @@
+package algorithm
+
+import (
+	"github.com/go-logr/logr"
+	"github.com/juliorenner/oai-k8s/operator/controllers/utils"
+)
+
+// transaction records the reservations of one chain, so they are either kept together or rolled back if any of them
+// fails
+type transaction struct {
+	// rollbacks undo the reservations in the order they were made
+	rollbacks []func()
+}
+
+// allocateNode reserves the resources in the node. The node deducts the resources even when it does not have them,
+// so the reservation is recorded before checking the error.
+func (t *transaction) allocateNode(node *utils.Node, requested *utils.RequestedResources, log logr.Logger) error {
+	err := node.AllocateResources(requested, log)
+	t.rollbacks = append(t.rollbacks, func() {
+		node.ReleaseResources(requested)
+	})
+
+	return err
+}
+
+// allocateLink reserves the bandwidth in the link, recorded before checking the error as allocateNode
+func (t *transaction) allocateLink(link *utils.Link, bandwidth float32) error {
+	err := link.AllocateResources(bandwidth)
+	t.rollbacks = append(t.rollbacks, func() {
+		link.ReleaseResources(bandwidth)
+	})
+
+	return err
+}
+
+// onRollback records a custom undo step
+func (t *transaction) onRollback(rollback func()) {
+	t.rollbacks = append(t.rollbacks, rollback)
+}
+
+// rollback undoes every reservation from the last to the first
+func (t *transaction) rollback() {
+	for i := len(t.rollbacks) - 1; i >= 0; i-- {
+		t.rollbacks[i]()
+	}
+	t.rollbacks = nil
+}
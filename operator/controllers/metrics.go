@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"time"
+
+	oaiv1beta1 "github.com/juliorenner/oai-k8s/operator/api/v1beta1"
+	"github.com/prometheus/client_golang/prometheus"
+	v1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	metricsNamespace = "oai"
+
+	// podMissing is the state of a split piece whose pod does not exist
+	podMissing = "Missing"
+	// podNotReady is the state of a split piece whose pod is running but not ready
+	podNotReady = "NotReady"
+)
+
+var (
+	podStates = []string{string(v1.PodPending), string(v1.PodRunning), string(v1.PodSucceeded),
+		string(v1.PodFailed), string(v1.PodUnknown), podNotReady, podMissing}
+
+	placementDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Name:      "placement_duration_seconds",
+		Help:      "Time taken to place the RUs of a splits placer.",
+		Buckets:   prometheus.ExponentialBuckets(0.001, 4, 10),
+	})
+	requestedRUs = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "placement_requested_rus",
+		Help:      "RUs requested by the splits placer.",
+	}, []string{"namespace", "splitsplacer"})
+	allocatedRUs = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "placement_allocated_rus",
+		Help:      "RUs allocated by the splits placer.",
+	}, []string{"namespace", "splitsplacer"})
+	linkResidualBandwidth = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "link_residual_bandwidth",
+		Help:      "Bandwidth of the topology link not reserved by any chain.",
+	}, []string{"topology", "link"})
+	nodeReservedCPU = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "node_reserved_cpu_cores",
+		Help:      "CPU reserved in the node by the chains placed in the topology.",
+	}, []string{"topology", "node"})
+	nodeReservedMemory = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "node_reserved_memory_bytes",
+		Help:      "Memory reserved in the node by the chains placed in the topology.",
+	}, []string{"topology", "node"})
+	splitPieceState = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Name:      "split_piece_state",
+		Help:      "State of the pod of each split piece, 1 for the current state.",
+	}, []string{"namespace", "split", "piece", "state"})
+	configMapRenders = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Name:      "configmap_renders_total",
+		Help:      "Template and values config maps created or updated by the split controller.",
+	}, []string{"namespace", "configmap", "operation"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(placementDuration, requestedRUs, allocatedRUs, linkResidualBandwidth,
+		nodeReservedCPU, nodeReservedMemory, splitPieceState, configMapRenders)
+}
+
+func recordPlacement(splitsPlacer *oaiv1beta1.SplitsPlacer, duration time.Duration) {
+	placementDuration.Observe(duration.Seconds())
+	requestedRUs.WithLabelValues(splitsPlacer.Namespace, splitsPlacer.Name).Set(float64(len(splitsPlacer.Spec.RUs)))
+	allocatedRUs.WithLabelValues(splitsPlacer.Namespace, splitsPlacer.Name).Set(float64(splitsPlacer.Status.AllocatedRUs))
+}
+
+func deleteSplitsPlacerMetrics(namespace, name string) {
+	requestedRUs.DeleteLabelValues(namespace, name)
+	allocatedRUs.DeleteLabelValues(namespace, name)
+}
+
+// recordTopologyReservations sets the residual bandwidth of each link and the resources reserved in each node
+// according to the reservations ledger of the topology
+func recordTopologyReservations(topologyName string, topology *oaiv1beta1.Topology,
+	reservations *oaiv1beta1.TopologyReservations) {
+	residual := make(map[string]float32)
+	for linkName, link := range topology.Links {
+		residual[linkName] = link.LinkCapacity
+	}
+
+	cpu := make(map[string]float64)
+	memory := make(map[string]float64)
+	for nodeName := range topology.Nodes {
+		cpu[nodeName], memory[nodeName] = 0, 0
+	}
+
+	for _, chain := range reservations.Chains {
+		for linkName, bandwidth := range chain.Links {
+			if _, exists := residual[linkName]; exists {
+				residual[linkName] -= bandwidth
+			}
+		}
+		for nodeName, node := range chain.Nodes {
+			reservedCPU := getReservedResources(node).TotalCPU()
+			cpu[nodeName] += float64(reservedCPU.MilliValue()) / 1000
+			memory[nodeName] += float64(node.Memory.Value())
+		}
+	}
+
+	for linkName, bandwidth := range residual {
+		linkResidualBandwidth.WithLabelValues(topologyName, linkName).Set(float64(bandwidth))
+	}
+	for nodeName := range cpu {
+		nodeReservedCPU.WithLabelValues(topologyName, nodeName).Set(cpu[nodeName])
+		nodeReservedMemory.WithLabelValues(topologyName, nodeName).Set(memory[nodeName])
+	}
+}
+
+// recordSplitPieceState sets 1 to the current state of the piece pod and 0 to the others
+func recordSplitPieceState(split *oaiv1beta1.Split, piece SplitPiece, pod *v1.Pod, exists bool) {
+	current := podMissing
+	if exists {
+		current = getPodState(pod)
+	}
+
+	for _, state := range podStates {
+		value := 0.0
+		if state == current {
+			value = 1
+		}
+		splitPieceState.WithLabelValues(split.Namespace, split.Name, string(piece), state).Set(value)
+	}
+}
+
+func deleteSplitMetrics(namespace, name string) {
+	for piece := range Splits {
+		for _, state := range podStates {
+			splitPieceState.DeleteLabelValues(namespace, name, piece, state)
+		}
+	}
+}
+
+func recordConfigMapRender(cm *v1.ConfigMap, operation string) {
+	configMapRenders.WithLabelValues(cm.Namespace, cm.Name, operation).Inc()
+}
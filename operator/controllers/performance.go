@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	oaiv1beta1 "github.com/juliorenner/oai-k8s/operator/api/v1beta1"
+	"github.com/juliorenner/oai-k8s/operator/controllers/utils"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// probeCPUValue and probeMemoryValue are the resources of the probe sidecar of the pieces with a performance
+	// profile, every container needs requests equal to limits for the pod to be Guaranteed
+	probeCPUValue    = "50m"
+	probeMemoryValue = "32Mi"
+
+	hugePagesPath = "/hugepages"
+)
+
+// getPerformanceProfile returns the performance profile of the piece, nil if it has none
+func getPerformanceProfile(profiles *oaiv1beta1.PerformanceProfiles, piece SplitPiece) *oaiv1beta1.PerformanceProfile {
+	if profiles == nil {
+		return nil
+	}
+
+	switch piece {
+	case CU:
+		return profiles.CU
+	case CUUP:
+		return profiles.CUUP
+	case DU:
+		return profiles.DU
+	case RU:
+		return profiles.RU
+	}
+
+	return nil
+}
+
+// getProfileResources returns the resources of the piece container, requests and limits are the same
+func getProfileResources(profile *oaiv1beta1.PerformanceProfile) v1.ResourceList {
+	memory := *utils.NewQuantity(SplitMemoryLimitValue)
+	if profile.Memory != nil {
+		memory = profile.Memory.DeepCopy()
+	}
+
+	resources := v1.ResourceList{
+		v1.ResourceCPU:    *resource.NewQuantity(profile.CPUs, resource.DecimalSI),
+		v1.ResourceMemory: memory,
+	}
+	if profile.HugePages1Gi != nil && !profile.HugePages1Gi.IsZero() {
+		resources[utils.ResourceHugePages1Gi] = profile.HugePages1Gi.DeepCopy()
+	}
+	if profile.HugePages2Mi != nil && !profile.HugePages2Mi.IsZero() {
+		resources[utils.ResourceHugePages2Mi] = profile.HugePages2Mi.DeepCopy()
+	}
+
+	return resources
+}
+
+// getPieceResources returns the resources the placement reserves for the piece
+func getPieceResources(profiles *oaiv1beta1.PerformanceProfiles, piece SplitPiece) *utils.RequestedResources {
+	profile := getPerformanceProfile(profiles, piece)
+	if profile == nil {
+		return &utils.RequestedResources{
+			Memory: *utils.NewQuantity(SplitMemoryRequestValue),
+			CPU:    *utils.NewQuantity(SplitCPURequestValue),
+		}
+	}
+
+	resources := getProfileResources(profile)
+	requested := &utils.RequestedResources{
+		Memory:        resources[v1.ResourceMemory],
+		CPU:           *utils.NewQuantity(probeCPUValue),
+		ExclusiveCPUs: profile.CPUs,
+		HugePages1Gi:  resources[utils.ResourceHugePages1Gi],
+		HugePages2Mi:  resources[utils.ResourceHugePages2Mi],
+	}
+	requested.Memory.Add(*utils.NewQuantity(probeMemoryValue))
+
+	return requested
+}
+
+// GetPiecesResources returns the resources reserved for the pieces with a performance profile, the key is the piece
+func GetPiecesResources(profiles *oaiv1beta1.PerformanceProfiles) map[SplitPiece]*utils.RequestedResources {
+	piecesResources := make(map[SplitPiece]*utils.RequestedResources)
+	for _, piece := range splitPiecesOrder {
+		if getPerformanceProfile(profiles, piece) != nil {
+			piecesResources[piece] = getPieceResources(profiles, piece)
+		}
+	}
+
+	return piecesResources
+}
+
+// setPerformanceProfile sets the Guaranteed resources of the piece and its probe sidecar, mounts the huge pages and
+// selects the runtime class of the profile
+func setPerformanceProfile(podSpec *v1.PodSpec, profile *oaiv1beta1.PerformanceProfile) {
+	if profile == nil {
+		return
+	}
+
+	for i := range podSpec.Containers {
+		container := &podSpec.Containers[i]
+		if container.Name == probeContainerName {
+			probeResources := v1.ResourceList{
+				v1.ResourceCPU:    *utils.NewQuantity(probeCPUValue),
+				v1.ResourceMemory: *utils.NewQuantity(probeMemoryValue),
+			}
+			container.Resources = v1.ResourceRequirements{Limits: probeResources, Requests: probeResources}
+			continue
+		}
+
+		resources := getProfileResources(profile)
+		container.Resources = v1.ResourceRequirements{Limits: resources, Requests: resources.DeepCopy()}
+
+		_, hugePages1Gi := resources[utils.ResourceHugePages1Gi]
+		_, hugePages2Mi := resources[utils.ResourceHugePages2Mi]
+		if hugePages1Gi || hugePages2Mi {
+			container.VolumeMounts = append(container.VolumeMounts,
+				v1.VolumeMount{Name: "hugepages", MountPath: hugePagesPath})
+			podSpec.Volumes = append(podSpec.Volumes, v1.Volume{Name: "hugepages",
+				VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{Medium: v1.StorageMediumHugePages}}})
+		}
+	}
+
+	if profile.RuntimeClassName != "" {
+		runtimeClassName := profile.RuntimeClassName
+		podSpec.RuntimeClassName = &runtimeClassName
+	}
+}
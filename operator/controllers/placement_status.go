@@ -0,0 +1,118 @@
+package controllers
+
+import (
+	"math"
+	"sort"
+
+	oaiv1beta1 "github.com/juliorenner/oai-k8s/operator/api/v1beta1"
+	"github.com/juliorenner/oai-k8s/operator/controllers/algorithm"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// setPlacementStatistics writes to the status the utilisation of the links and nodes and the hops and latency of the
+// chains allocated
+func setPlacementStatistics(splitsPlacer *oaiv1beta1.SplitsPlacer, topology *oaiv1beta1.Topology,
+	rus []*oaiv1beta1.ChainPosition, topologyGraph *algorithm.PlacementBFS) {
+	splitsPlacer.Status.Links = getLinksUtilisation(topology, topologyGraph)
+	splitsPlacer.Status.Nodes = nil
+	splitsPlacer.Status.Hops = nil
+	splitsPlacer.Status.Chains = nil
+
+	nodes := make(map[string]*oaiv1beta1.NodeUtilisation)
+	for _, ru := range rus {
+		if ru.DUNode == "" || ru.CUNode == "" {
+			continue
+		}
+
+		chain := GetChainReservation(ru, nil, splitsPlacer.Spec.Performance)
+		for nodeName, reservation := range chain.Nodes {
+			node, exists := nodes[nodeName]
+			if !exists {
+				node = &oaiv1beta1.NodeUtilisation{Name: nodeName}
+				nodes[nodeName] = node
+				splitsPlacer.Status.Nodes = append(splitsPlacer.Status.Nodes, node)
+			}
+			node.CPU.Add(getReservedResources(reservation).TotalCPU())
+			node.Memory.Add(reservation.Memory)
+		}
+
+		splitsPlacer.Status.Chains = append(splitsPlacer.Status.Chains, &oaiv1beta1.ChainStatistics{
+			SplitName: ru.SplitName,
+			Hops:      int32(len(ru.Path) - 1),
+			Latency:   getSegmentLatency(topologyGraph.ChainLatency(ru)),
+		})
+	}
+
+	sort.Slice(splitsPlacer.Status.Nodes, func(i, j int) bool {
+		return splitsPlacer.Status.Nodes[i].Name < splitsPlacer.Status.Nodes[j].Name
+	})
+
+	splitsPlacer.Status.Hops = getHopStatistics(splitsPlacer.Status.Chains)
+}
+
+func getLinksUtilisation(topology *oaiv1beta1.Topology,
+	topologyGraph *algorithm.PlacementBFS) []*oaiv1beta1.LinkUtilisation {
+	remainingBandwidth := topologyGraph.GetRemainingBandwidth()
+
+	var links []*oaiv1beta1.LinkUtilisation
+	for linkName, link := range topology.Links {
+		remaining := link.LinkCapacity
+		if remainingLink, exists := remainingBandwidth[linkName]; exists {
+			remaining = remainingLink.AvailableBandwidth
+		}
+
+		links = append(links, &oaiv1beta1.LinkUtilisation{
+			Name:      linkName,
+			Capacity:  floatQuantity(link.LinkCapacity),
+			Used:      floatQuantity(link.LinkCapacity - remaining),
+			Remaining: floatQuantity(remaining),
+		})
+	}
+
+	sort.Slice(links, func(i, j int) bool {
+		return links[i].Name < links[j].Name
+	})
+
+	return links
+}
+
+func getHopStatistics(chains []*oaiv1beta1.ChainStatistics) *oaiv1beta1.HopStatistics {
+	if len(chains) == 0 {
+		return nil
+	}
+
+	hops := &oaiv1beta1.HopStatistics{}
+	for _, chain := range chains {
+		hops.Total += chain.Hops
+		if chain.Hops > hops.Max {
+			hops.Max = chain.Hops
+		}
+	}
+	hops.Average = floatQuantity(float32(hops.Total) / float32(len(chains)))
+
+	return hops
+}
+
+// getSegmentLatency converts the latency of each segment, the key is the segment name
+func getSegmentLatency(latency map[string]float32) *oaiv1beta1.SegmentLatency {
+	segmentLatency := &oaiv1beta1.SegmentLatency{}
+	segments := map[string]**resource.Quantity{
+		"backhaul":  &segmentLatency.Backhaul,
+		"midhaul":   &segmentLatency.Midhaul,
+		"e1":        &segmentLatency.E1,
+		"fronthaul": &segmentLatency.Fronthaul,
+	}
+	for segment, value := range latency {
+		if field, exists := segments[segment]; exists {
+			quantity := floatQuantity(value)
+			*field = &quantity
+		}
+	}
+
+	return segmentLatency
+}
+
+// floatQuantity returns the value as a quantity with milli precision
+func floatQuantity(value float32) resource.Quantity {
+	return *resource.NewMilliQuantity(int64(math.Round(float64(value)*1000)), resource.DecimalSI)
+}
@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	oaiv1beta1 "github.com/juliorenner/oai-k8s/operator/api/v1beta1"
+	"github.com/juliorenner/oai-k8s/operator/controllers/utils"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("placement status unit tests", func() {
+	DescribeTable("getHopStatistics", func(hops []int32, expected *oaiv1beta1.HopStatistics) {
+		var chains []*oaiv1beta1.ChainStatistics
+		for _, chainHops := range hops {
+			chains = append(chains, &oaiv1beta1.ChainStatistics{Hops: chainHops})
+		}
+
+		statistics := getHopStatistics(chains)
+		if expected == nil {
+			Expect(statistics).To(BeNil())
+			return
+		}
+		Expect(statistics.Total).To(Equal(expected.Total))
+		Expect(statistics.Max).To(Equal(expected.Max))
+		Expect(statistics.Average.Cmp(expected.Average)).To(BeZero(), statistics.Average.String())
+	},
+		Entry("no chain allocated", []int32(nil), nil),
+		Entry("whole average", []int32{5, 4, 4, 3},
+			&oaiv1beta1.HopStatistics{Total: 16, Max: 5, Average: *utils.NewQuantity("4")}),
+		Entry("fractional average", []int32{5, 4},
+			&oaiv1beta1.HopStatistics{Total: 9, Max: 5, Average: *utils.NewQuantity("4500m")}),
+	)
+
+	DescribeTable("getSegmentLatency", func(latency map[string]float32, fronthaul string, e1 bool) {
+		segmentLatency := getSegmentLatency(latency)
+		Expect(segmentLatency.Fronthaul.String()).To(Equal(fronthaul))
+		Expect(segmentLatency.E1 != nil).To(Equal(e1))
+	},
+		Entry("cu and du", map[string]float32{"backhaul": 2, "midhaul": 1.5, "fronthaul": 0.25}, "250m", false),
+		Entry("cu-up split", map[string]float32{"backhaul": 2, "e1": 0, "midhaul": 1.5, "fronthaul": 1}, "1", true),
+	)
+})
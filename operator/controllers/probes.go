@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"fmt"
+	"strings"
+
+	oaiv1beta1 "github.com/juliorenner/oai-k8s/operator/api/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	// lteF1CPort is the F1-C port the LTE CU listens to, as set in the cu template
+	lteF1CPort = 501
+	nrF1CPort  = 38472
+	e1Port     = 38462
+	// fronthaulPort is the nFAPI P5 port the DU listens to
+	fronthaulPort = 50001
+
+	socketBound      socketState = "bound"
+	socketListening  socketState = "listening"
+	socketAssociated socketState = "associated"
+
+	probeContainerName = "probe"
+	probePort          = 8086
+	livenessPath       = "/healthz"
+	readinessPath      = "/readyz"
+	// probeCommand runs the replacer embedded in the OAI images as the probe server
+	probeCommand = "/replacer"
+
+	probePeriodSeconds = 5
+	// startupFailureThreshold gives the replacer up to 5 minutes to render the config and start the softmodem
+	startupFailureThreshold = 60
+
+	lteSoftModemProcess   = "lte-softmodem"
+	lteUESoftModemProcess = "lte-uesoftmodem"
+	nrSoftModemProcess    = "nr-softmodem"
+	nrUESoftModemProcess  = "nr-uesoftmodem"
+)
+
+// socketState is the state of a socket checked by the probe server: bound to the local port (UDP, TCP or SCTP),
+// listening to the local port (SCTP) or associated to the remote port (SCTP)
+type socketState string
+
+type socketCheck struct {
+	state socketState
+	port  int32
+}
+
+func (c socketCheck) String() string {
+	return fmt.Sprintf("%s:%d", c.state, c.port)
+}
+
+// probedPorts are the ports of SplitPorts and NRSplitPorts the softmodem binds once its interfaces are up
+var probedPorts = map[SplitPiece][]int32{
+	CU: {501, 601, 2152},
+	DU: {500, 600, 50001, 50011},
+	RU: {50000, 50010},
+}
+
+var nrProbedPorts = map[SplitPiece][]int32{
+	CU:   {38472},
+	CUUP: {2152},
+	DU:   {2152, 50001, 50011},
+	RU:   {50000, 50010},
+}
+
+// getReadinessChecks returns the sockets checking the piece is ready for the next piece of the chain to start: the
+// CU listens to F1, the CU-UP is associated to the CU-CP through E1, the DU is associated to the CU through F1 and
+// listens to the fronthaul and the RU is associated to the DU through the fronthaul. Besides, the ports of the piece
+// must be bound.
+func getReadinessChecks(split SplitPiece, rat oaiv1beta1.RAT) []socketCheck {
+	f1Port := int32(lteF1CPort)
+	ports := probedPorts
+	if rat == oaiv1beta1.RATNR {
+		f1Port = nrF1CPort
+		ports = nrProbedPorts
+	}
+
+	var checks []socketCheck
+	switch split {
+	case CU:
+		checks = append(checks, socketCheck{socketListening, f1Port})
+		if rat == oaiv1beta1.RATNR {
+			checks = append(checks, socketCheck{socketListening, e1Port})
+		}
+	case CUUP:
+		checks = append(checks, socketCheck{socketAssociated, e1Port})
+	case DU:
+		checks = append(checks, socketCheck{socketAssociated, f1Port}, socketCheck{socketListening, fronthaulPort})
+	case RU:
+		checks = append(checks, socketCheck{socketAssociated, fronthaulPort})
+	}
+
+	for _, port := range ports[split] {
+		checks = append(checks, socketCheck{socketBound, port})
+	}
+
+	return checks
+}
+
+func getProcessName(split SplitPiece, rat oaiv1beta1.RAT) string {
+	if split == RU {
+		if rat == oaiv1beta1.RATNR {
+			return nrUESoftModemProcess
+		}
+		return lteUESoftModemProcess
+	}
+
+	if rat == oaiv1beta1.RATNR {
+		return nrSoftModemProcess
+	}
+	return lteSoftModemProcess
+}
+
+// getProbeContainer returns the sidecar serving the probes of the piece. It runs the replacer of the piece image in
+// probe mode, which checks the softmodem process is alive and its sockets are ready.
+func getProbeContainer(split SplitPiece, rat oaiv1beta1.RAT) v1.Container {
+	var sockets []string
+	for _, check := range getReadinessChecks(split, rat) {
+		sockets = append(sockets, check.String())
+	}
+
+	return v1.Container{
+		Name:            probeContainerName,
+		Image:           getImageName(split, rat),
+		ImagePullPolicy: v1.PullAlways,
+		Command:         []string{probeCommand, "probe"},
+		Ports: []v1.ContainerPort{
+			{
+				Name:          "probe",
+				ContainerPort: probePort,
+				Protocol:      v1.ProtocolTCP,
+			},
+		},
+		Env: []v1.EnvVar{
+			{
+				Name:  "ProbePort",
+				Value: fmt.Sprintf("%d", probePort),
+			},
+			{
+				Name:  "ProbeProcess",
+				Value: getProcessName(split, rat),
+			},
+			{
+				Name:  "ProbeSockets",
+				Value: strings.Join(sockets, ","),
+			},
+		},
+	}
+}
+
+func getHTTPProbe(path string) *v1.Probe {
+	return &v1.Probe{
+		Handler: v1.Handler{
+			HTTPGet: &v1.HTTPGetAction{
+				Path: path,
+				Port: intstr.FromInt(probePort),
+			},
+		},
+		PeriodSeconds: probePeriodSeconds,
+	}
+}
+
+// setProbes adds the probe sidecar to the pod and points the probes of the piece container to it. The process
+// namespace is shared so the sidecar sees the softmodem.
+func setProbes(podSpec *v1.PodSpec, split SplitPiece, rat oaiv1beta1.RAT) {
+	shareProcessNamespace := true
+	podSpec.ShareProcessNamespace = &shareProcessNamespace
+
+	container := &podSpec.Containers[0]
+	container.StartupProbe = getHTTPProbe(livenessPath)
+	container.StartupProbe.FailureThreshold = startupFailureThreshold
+	container.LivenessProbe = getHTTPProbe(livenessPath)
+	container.ReadinessProbe = getHTTPProbe(readinessPath)
+
+	podSpec.Containers = append(podSpec.Containers, getProbeContainer(split, rat))
+}
@@ -0,0 +1,197 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	oaiv1beta1 "github.com/juliorenner/oai-k8s/operator/api/v1beta1"
+	"github.com/juliorenner/oai-k8s/operator/controllers/utils"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	reservationsKey = "reservations"
+
+	reservationsFinalizer = "oai.unisinos/reservations"
+)
+
+// GetTopologyConfigMap returns the config map where the topology and its reservations ledger are kept.
+func GetTopologyConfigMap(k8sClient client.Client, objectKey types.NamespacedName) (*v1.ConfigMap, error) {
+	cm := &v1.ConfigMap{}
+	if exists, err := utils.GetConfigMap(k8sClient, objectKey, cm); err != nil {
+		return nil, fmt.Errorf("error getting topology '%s' config map: %w", objectKey.String(), err)
+	} else if !exists {
+		return nil, fmt.Errorf("topology config map '%s' does not exists", objectKey.String())
+	}
+
+	return cm, nil
+}
+
+// ReadTopologyReservations reads the reservations ledger from the topology config map. An empty ledger is returned
+// if no reservation was made yet.
+func ReadTopologyReservations(cm *v1.ConfigMap) (*oaiv1beta1.TopologyReservations, error) {
+	reservations := &oaiv1beta1.TopologyReservations{}
+	if data, exists := cm.Data[reservationsKey]; exists {
+		if err := json.Unmarshal([]byte(data), reservations); err != nil {
+			return nil, fmt.Errorf("error unmarshaling topology reservations: %w", err)
+		}
+	}
+
+	if reservations.Chains == nil {
+		reservations.Chains = make(map[string]*oaiv1beta1.ChainReservation)
+	}
+
+	return reservations, nil
+}
+
+// UpdateTopologyReservations applies the update to the ledger of the topology config map and writes it back. The write
+// fails with a conflict if the config map changed since it was read, so the ledger is only updated based on the
+// reservations the caller consulted.
+func UpdateTopologyReservations(k8sClient client.Client, cm *v1.ConfigMap,
+	update func(reservations *oaiv1beta1.TopologyReservations)) error {
+	reservations, err := ReadTopologyReservations(cm)
+	if err != nil {
+		return err
+	}
+
+	update(reservations)
+
+	data, err := json.Marshal(reservations)
+	if err != nil {
+		return fmt.Errorf("error marshaling topology reservations: %w", err)
+	}
+
+	if cm.Data == nil {
+		cm.Data = make(map[string]string)
+	}
+	cm.Data[reservationsKey] = string(data)
+
+	if err := k8sClient.Update(context.Background(), cm); err != nil {
+		return fmt.Errorf("error updating topology reservations: %w", err)
+	}
+
+	return nil
+}
+
+// ReservedLinks sums the bandwidth reserved in each link by the chains that are not owned by the splits placer. The
+// key is the link name.
+func ReservedLinks(reservations *oaiv1beta1.TopologyReservations,
+	splitsPlacerKey types.NamespacedName) map[string]float32 {
+	linksUsage := make(map[string]float32)
+	for chainKey, chain := range reservations.Chains {
+		if IsChainOwnedBy(chainKey, splitsPlacerKey) {
+			continue
+		}
+
+		for linkName, bandwidth := range chain.Links {
+			linksUsage[linkName] += bandwidth
+		}
+	}
+
+	return linksUsage
+}
+
+// ReservedCores counts the chains served by each core that are not owned by the splits placer. The key is the core
+// node.
+func ReservedCores(reservations *oaiv1beta1.TopologyReservations,
+	splitsPlacerKey types.NamespacedName) map[string]int {
+	coreChains := make(map[string]int)
+	for chainKey, chain := range reservations.Chains {
+		if IsChainOwnedBy(chainKey, splitsPlacerKey) || chain.Core == "" {
+			continue
+		}
+
+		coreChains[chain.Core]++
+	}
+
+	return coreChains
+}
+
+// NodesUsage returns the resources in use in each node: the requests of the pods bound to the nodes plus the nodes
+// reserved by the chains in the ledger that are not owned by the splits placer. The pods of those chains are not
+// counted, the ledger already reserves their pieces whether they were created or not. The key is the node name.
+func NodesUsage(pods []v1.Pod, reservations *oaiv1beta1.TopologyReservations,
+	splitsPlacerKey types.NamespacedName) map[string]*utils.RequestedResources {
+	reservedSplits := utils.NewStringSet()
+	for chainKey := range reservations.Chains {
+		if !IsChainOwnedBy(chainKey, splitsPlacerKey) {
+			reservedSplits.Add(getChainSplitKey(chainKey))
+		}
+	}
+
+	var unreservedPods []v1.Pod
+	for _, pod := range pods {
+		if !reservedSplits.Has(fmt.Sprintf("%s/%s", pod.Namespace, pod.Labels["split-owner"])) {
+			unreservedPods = append(unreservedPods, pod)
+		}
+	}
+
+	nodesUsage := utils.PodsRequestedResources(unreservedPods)
+	for chainKey, chain := range reservations.Chains {
+		if IsChainOwnedBy(chainKey, splitsPlacerKey) {
+			continue
+		}
+
+		for nodeName, node := range chain.Nodes {
+			usage, exists := nodesUsage[nodeName]
+			if !exists {
+				usage = &utils.RequestedResources{}
+				nodesUsage[nodeName] = usage
+			}
+			usage.AddRequested(getReservedResources(node))
+		}
+	}
+
+	return nodesUsage
+}
+
+// getReservedResources returns the resources reserved by a chain in the node
+func getReservedResources(node *oaiv1beta1.NodeReservation) *utils.RequestedResources {
+	return &utils.RequestedResources{
+		Memory:        node.Memory,
+		CPU:           node.CPU,
+		ExclusiveCPUs: node.ExclusiveCPUs,
+		HugePages1Gi:  node.HugePages1Gi,
+		HugePages2Mi:  node.HugePages2Mi,
+	}
+}
+
+// RemoveChainReservations removes every chain owned by the splits placer from the ledger.
+func RemoveChainReservations(reservations *oaiv1beta1.TopologyReservations, splitsPlacerKey types.NamespacedName) {
+	for chainKey := range reservations.Chains {
+		if IsChainOwnedBy(chainKey, splitsPlacerKey) {
+			delete(reservations.Chains, chainKey)
+		}
+	}
+}
+
+func GetChainKey(splitsPlacerKey types.NamespacedName, splitName string) string {
+	return fmt.Sprintf("%s/%s", splitsPlacerKey.String(), splitName)
+}
+
+// getChainSplitKey returns the namespace and name of the split of the chain, the chain key is
+// <namespace>/<splits placer>/<split>
+func getChainSplitKey(chainKey string) string {
+	parts := strings.SplitN(chainKey, "/", 3)
+	if len(parts) != 3 {
+		return chainKey
+	}
+
+	return parts[0] + "/" + parts[2]
+}
+
+func IsChainOwnedBy(chainKey string, splitsPlacerKey types.NamespacedName) bool {
+	return strings.HasPrefix(chainKey, splitsPlacerKey.String()+"/")
+}
+
+// IsConflict returns true if the error, or any error it wraps, is a conflict updating a resource.
+func IsConflict(err error) bool {
+	var statusErr *apierrors.StatusError
+	return errors.As(err, &statusErr) && apierrors.IsConflict(statusErr)
+}
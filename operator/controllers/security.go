@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"fmt"
+
+	oaiv1beta1 "github.com/juliorenner/oai-k8s/operator/api/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// nonRootUser runs the pieces of the restricted profile
+	nonRootUser = 1000
+
+	tmpPath = "/tmp"
+
+	seccompProfileRuntimeDefault = "RuntimeDefault"
+)
+
+// getSecurityContext returns the security context of the piece container
+func getSecurityContext(profile oaiv1beta1.SecurityProfile) *v1.SecurityContext {
+	boolTrue, boolFalse := true, false
+	switch profile {
+	case oaiv1beta1.SecurityProfileNetAdmin:
+		return &v1.SecurityContext{
+			Capabilities: &v1.Capabilities{
+				Add:  []v1.Capability{"NET_ADMIN"},
+				Drop: []v1.Capability{"ALL"},
+			},
+			Privileged:               &boolFalse,
+			AllowPrivilegeEscalation: &boolFalse,
+			ReadOnlyRootFilesystem:   &boolTrue,
+		}
+	case oaiv1beta1.SecurityProfileRestricted:
+		return getRestrictedSecurityContext()
+	}
+
+	return &v1.SecurityContext{
+		Capabilities: &v1.Capabilities{
+			Add: []v1.Capability{"NET_ADMIN"},
+		},
+		Privileged: &boolTrue,
+	}
+}
+
+func getRestrictedSecurityContext() *v1.SecurityContext {
+	boolTrue, boolFalse := true, false
+	user := int64(nonRootUser)
+
+	return &v1.SecurityContext{
+		Capabilities: &v1.Capabilities{
+			Drop: []v1.Capability{"ALL"},
+		},
+		Privileged:               &boolFalse,
+		AllowPrivilegeEscalation: &boolFalse,
+		ReadOnlyRootFilesystem:   &boolTrue,
+		RunAsNonRoot:             &boolTrue,
+		RunAsUser:                &user,
+	}
+}
+
+// setSecurityProfile sets the security context of the piece and of its probe sidecar, which never needs privileges.
+// Out of the privileged profile, the root filesystem is read only: the replacer renders the config into an empty
+// dir mounted in the config path, where the template and values are mounted, and the softmodem writes to an empty
+// dir in /tmp.
+func setSecurityProfile(podTemplate *v1.PodTemplateSpec, profile oaiv1beta1.SecurityProfile, piece SplitPiece) {
+	profile = getPieceSecurityProfile(profile, piece)
+	podSpec := &podTemplate.Spec
+	for i := range podSpec.Containers {
+		if podSpec.Containers[i].Name == probeContainerName {
+			podSpec.Containers[i].SecurityContext = getRestrictedSecurityContext()
+			continue
+		}
+		podSpec.Containers[i].SecurityContext = getSecurityContext(profile)
+	}
+
+	if profile != oaiv1beta1.SecurityProfileNetAdmin && profile != oaiv1beta1.SecurityProfileRestricted {
+		return
+	}
+
+	container := &podSpec.Containers[0]
+	container.VolumeMounts = append(container.VolumeMounts,
+		v1.VolumeMount{Name: "config", MountPath: configPath},
+		v1.VolumeMount{Name: "tmp", MountPath: tmpPath},
+	)
+	podSpec.Volumes = append(podSpec.Volumes,
+		v1.Volume{Name: "config", VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}},
+		v1.Volume{Name: "tmp", VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}},
+	)
+}
+
+// getPieceSecurityProfile returns the profile the piece runs with. The UE softmodem of the RU needs NET_ADMIN for its
+// TUN device, so the RU of a restricted split runs with the net admin profile.
+func getPieceSecurityProfile(profile oaiv1beta1.SecurityProfile, piece SplitPiece) oaiv1beta1.SecurityProfile {
+	if piece == RU && profile == oaiv1beta1.SecurityProfileRestricted {
+		return oaiv1beta1.SecurityProfileNetAdmin
+	}
+
+	return profile
+}
+
+// getDeploymentObject returns the deployment of the piece as sent to the API server. Out of the privileged profile,
+// the pod security context sets the runtime default seccomp profile, required by the restricted pod security level.
+// The vendored API types predate the seccompProfile field, so it is set in an unstructured copy of the deployment.
+func getDeploymentObject(deployment *appsv1.Deployment, profile oaiv1beta1.SecurityProfile) (runtime.Object, error) {
+	if profile != oaiv1beta1.SecurityProfileNetAdmin && profile != oaiv1beta1.SecurityProfileRestricted {
+		return deployment, nil
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(deployment)
+	if err != nil {
+		return nil, fmt.Errorf("error converting deployment %s: %w", deployment.Name, err)
+	}
+	object := &unstructured.Unstructured{Object: content}
+	object.SetGroupVersionKind(appsv1.SchemeGroupVersion.WithKind("Deployment"))
+	if err := unstructured.SetNestedField(object.Object, seccompProfileRuntimeDefault, "spec", "template", "spec",
+		"securityContext", "seccompProfile", "type"); err != nil {
+		return nil, fmt.Errorf("error setting seccomp profile of deployment %s: %w", deployment.Name, err)
+	}
+
+	return object, nil
+}
@@ -18,9 +18,13 @@ package controllers
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -43,8 +47,10 @@ const (
 	logSplitPieceKey = "splitPiece"
 	logResourceName  = "resouceName"
 
-	lteSoftModemImageName = "lte_softmodem_k8s"
-	ueSoftModemImageName  = "ue_softmodem_k8s"
+	lteSoftModemImageName  = "lte_softmodem_k8s"
+	ueSoftModemImageName   = "ue_softmodem_k8s"
+	nrSoftModemImageName   = "nr_softmodem_k8s"
+	nrUESoftModemImageName = "nr_uesoftmodem_k8s"
 
 	dockerRepositoryEnv = "DOCKER_REPOSITORY"
 
@@ -76,10 +82,23 @@ func (r *SplitReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	// your logic here
 	split := &oaiv1beta1.Split{}
 	if err := r.Get(ctx, req.NamespacedName, split); err != nil {
+		if apierrors.IsNotFound(err) {
+			deleteSplitMetrics(req.Namespace, req.Name)
+		}
 		log.Error(err, "unable to fetch Remote Unit")
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
+	if err := r.validateCellID(split); err != nil {
+		log.Error(err, "invalid radio")
+		r.Recorder.Event(split, v1.EventTypeWarning, "radio", err.Error())
+		split.Status.State = oaiv1beta1.SplitStateError
+		if err := r.Status().Update(ctx, split); err != nil {
+			log.Error(err, "error updating status")
+		}
+		return ctrl.Result{}, err
+	}
+
 	if err := r.syncConfigMaps(split, log); err != nil {
 		log.Error(err, "error syncing config maps")
 		r.Recorder.Event(split, v1.EventTypeWarning, "configmaps", err.Error())
@@ -107,44 +126,119 @@ func (r *SplitReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Complete(r)
 }
 
+// validateCellID checks no other split of the same RAT broadcasts the cell ID of the split, the splits without radio
+// broadcasting the default cell. When two splits use the same cell ID, the oldest one keeps it and the other is not
+// deployed. The PCI of LTE splits is also checked, as the CRD allows the NR range.
+func (r *SplitReconciler) validateCellID(split *oaiv1beta1.Split) error {
+	radio := getRadio(split)
+	if split.Spec.RAT != oaiv1beta1.RATNR && radio.PCI > maxLTEPCI {
+		return fmt.Errorf("pci %d is out of the LTE range, the maximum is %d", radio.PCI, maxLTEPCI)
+	}
+
+	splits := &oaiv1beta1.SplitList{}
+	if err := r.List(context.Background(), splits); err != nil {
+		return fmt.Errorf("error listing splits: %w", err)
+	}
+
+	for i := range splits.Items {
+		other := &splits.Items[i]
+		if other.Namespace == split.Namespace && other.Name == split.Name {
+			continue
+		}
+		if (other.Spec.RAT == oaiv1beta1.RATNR) != (split.Spec.RAT == oaiv1beta1.RATNR) ||
+			getRadio(other).CellID != radio.CellID {
+			continue
+		}
+		if createdBefore(other, split) {
+			return fmt.Errorf("cell id %d is already used by split '%s/%s'", radio.CellID, other.Namespace,
+				other.Name)
+		}
+	}
+
+	return nil
+}
+
+// createdBefore orders the splits by creation time and then by namespace and name
+func createdBefore(split, other *oaiv1beta1.Split) bool {
+	if !split.CreationTimestamp.Equal(&other.CreationTimestamp) {
+		return split.CreationTimestamp.Before(&other.CreationTimestamp)
+	}
+	if split.Namespace != other.Namespace {
+		return split.Namespace < other.Namespace
+	}
+
+	return split.Name < other.Name
+}
+
 func (r *SplitReconciler) syncStatus(instance *oaiv1beta1.Split) error {
 	noErrors := true
 	cuPod := &v1.Pod{}
-	if exists, err := r.getCUPod(instance, cuPod); err != nil {
+	exists, err := r.getCUPod(instance, cuPod)
+	if err != nil {
 		return fmt.Errorf("error getting cu pod: %w", err)
-	} else if exists {
+	}
+	recordSplitPieceState(instance, CU, cuPod, exists)
+	if exists {
 		instance.Status.CUNode = cuPod.Spec.NodeName
 		instance.Status.CUIP = cuPod.Status.PodIP
-		if cuPod.Status.Phase != v1.PodRunning {
+		if state := getPodState(cuPod); state != string(v1.PodRunning) {
 			noErrors = false
-			r.Recorder.Event(instance, v1.EventTypeWarning, "CU", fmt.Sprintf("cu pod in state '%s'", cuPod.Status.Phase))
+			r.Recorder.Event(instance, v1.EventTypeWarning, "CU", fmt.Sprintf("cu pod in state '%s'", state))
+		}
+	}
+
+	if instance.Spec.RAT == oaiv1beta1.RATNR {
+		cuupPod := &v1.Pod{}
+		exists, err = r.getCUUPPod(instance, cuupPod)
+		if err != nil {
+			return fmt.Errorf("error getting cu-up pod: %w", err)
+		}
+		recordSplitPieceState(instance, CUUP, cuupPod, exists)
+		if exists {
+			instance.Status.CUUPNode = cuupPod.Spec.NodeName
+			instance.Status.CUUPIP = cuupPod.Status.PodIP
+			if state := getPodState(cuupPod); state != string(v1.PodRunning) {
+				noErrors = false
+				r.Recorder.Event(instance, v1.EventTypeWarning, "CUUP", fmt.Sprintf("cu-up pod in state '%s'", state))
+			}
 		}
 	}
 
 	duPod := &v1.Pod{}
-	if exists, err := r.getDUPod(instance, duPod); err != nil {
+	exists, err = r.getDUPod(instance, duPod)
+	if err != nil {
 		return fmt.Errorf("error getting du pod: %w", err)
-	} else if exists {
+	}
+	recordSplitPieceState(instance, DU, duPod, exists)
+	if exists {
 		instance.Status.DUNode = duPod.Spec.NodeName
 		instance.Status.DUIP = duPod.Status.PodIP
-		if duPod.Status.Phase != v1.PodRunning {
+		if state := getPodState(duPod); state != string(v1.PodRunning) {
 			noErrors = false
-			r.Recorder.Event(instance, v1.EventTypeWarning, "DU", fmt.Sprintf("du pod in state '%s'", duPod.Status.Phase))
+			r.Recorder.Event(instance, v1.EventTypeWarning, "DU", fmt.Sprintf("du pod in state '%s'", state))
 		}
 	}
 
 	ruPod := &v1.Pod{}
-	if exists, err := r.getRUPod(instance, ruPod); err != nil {
+	exists, err = r.getRUPod(instance, ruPod)
+	if err != nil {
 		return fmt.Errorf("error getting ru pod: %w", err)
-	} else if exists {
+	}
+	recordSplitPieceState(instance, RU, ruPod, exists)
+	if exists {
 		instance.Status.RUNode = ruPod.Spec.NodeName
 		instance.Status.RUIP = ruPod.Status.PodIP
-		if ruPod.Status.Phase != v1.PodRunning {
+		if state := getPodState(ruPod); state != string(v1.PodRunning) {
 			noErrors = false
-			r.Recorder.Event(instance, v1.EventTypeWarning, "RU", fmt.Sprintf("ru pod in state '%s'", ruPod.Status.Phase))
+			r.Recorder.Event(instance, v1.EventTypeWarning, "RU", fmt.Sprintf("ru pod in state '%s'", state))
 		}
 	}
 
+	instance.Status.Stage, err = r.getStage(instance)
+	if err != nil {
+		return fmt.Errorf("error getting bring-up stage: %w", err)
+	}
+
 	if noErrors {
 		instance.Status.State = oaiv1beta1.SplitStateRunning
 	} else {
@@ -158,12 +252,37 @@ func (r *SplitReconciler) syncStatus(instance *oaiv1beta1.Split) error {
 	return nil
 }
 
+// syncDeployments creates the deployment of each piece and rolls the pieces whose template or values changed. The
+// pieces are created and rolled in the chain order, a piece is only created or rolled once the pieces before it are
+// ready with their current pod template.
 func (r *SplitReconciler) syncDeployments(instance *oaiv1beta1.Split, log logr.Logger) error {
-	for split := range Splits {
+	templates, err := getTemplateSources(instance)
+	if err != nil {
+		return err
+	}
+	// waiting is set once a piece is not ready, the pieces after it wait for it
+	waiting := false
+	for _, splitPiece := range splitPiecesOrder {
+		template, deployed := templates[splitPiece]
+		if !deployed {
+			continue
+		}
+
+		split := string(splitPiece)
 		log.Info("syncing deployment", logSplitPieceKey, split)
-		splitPiece := SplitPiece(split)
 		objectKey := getSplitObjectKey(instance, splitPiece)
 
+		templateHash, err := r.getTemplateHash(instance, template)
+		if err != nil {
+			return fmt.Errorf("error getting template hash: %w", err)
+		}
+
+		valuesChecksum, err := r.getValuesChecksum(instance, splitPiece)
+		if err != nil {
+			return fmt.Errorf("error getting values checksum: %w", err)
+		}
+
+		desired := getSplitDeployment(instance, splitPiece, template, templateHash, valuesChecksum)
 		deployment := &appsv1.Deployment{}
 		exists, err := utils.GetDeployment(r.Client, objectKey, deployment)
 		if err != nil {
@@ -171,38 +290,132 @@ func (r *SplitReconciler) syncDeployments(instance *oaiv1beta1.Split, log logr.L
 		}
 
 		if exists {
-			log.Info("already exists...", logSplitPieceKey, split, logResourceName, deployment.Name)
+			if deployment.Spec.Template.Annotations[PodTemplateHashAnnotation] ==
+				desired.Spec.Template.Annotations[PodTemplateHashAnnotation] {
+				log.Info("already exists...", logSplitPieceKey, split, logResourceName, deployment.Name)
+				waiting = waiting || !deploymentRolledOut(deployment)
+				continue
+			}
+
+			if waiting {
+				log.Info("waiting for the previous pieces to roll out", logSplitPieceKey, split, logResourceName,
+					deployment.Name)
+				continue
+			}
+
+			// the pod template changed, roll the pods with the new one
+			deployment.Spec.Strategy = desired.Spec.Strategy
+			deployment.Spec.Template = desired.Spec.Template
+			object, err := getDeploymentObject(deployment, instance.Spec.SecurityProfile)
+			if err != nil {
+				return err
+			}
+			if err := r.Update(context.Background(), object); err != nil {
+				return fmt.Errorf("error updating deployment %s: %w", deployment.Name, err)
+			}
+
+			log.Info("deployment template updated", logSplitPieceKey, split, logResourceName, deployment.Name)
+			waiting = true
+			continue
+		}
+
+		if waiting {
+			log.Info("waiting for the previous pieces to be ready", logSplitPieceKey, split)
 			continue
 		}
 
-		deployment = getSplitDeployment(instance, splitPiece)
+		deployment = desired
 		if err := ctrl.SetControllerReference(instance, deployment, r.Scheme); err != nil {
 			return fmt.Errorf("error setting config map owner reference: %w", err)
 		}
 
-		if err := r.Create(context.Background(), deployment); err != nil {
+		object, err := getDeploymentObject(deployment, instance.Spec.SecurityProfile)
+		if err != nil {
+			return err
+		}
+		if err := r.Create(context.Background(), object); err != nil {
 			return fmt.Errorf("error creating deployment %s: %w", deployment.Name, err)
 		}
 
 		log.Info("deployment created", logSplitPieceKey, split, logResourceName, deployment.Name)
+		waiting = true
 	}
 	return nil
 }
 
-func getImageName(splitName SplitPiece) string {
+// getPodState returns the phase of the pod, or NotReady for the running pods failing the readiness probe
+func getPodState(pod *v1.Pod) string {
+	if pod.Status.Phase == "" {
+		return string(v1.PodPending)
+	}
+	if pod.Status.Phase != v1.PodRunning {
+		return string(pod.Status.Phase)
+	}
+
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodReady && condition.Status != v1.ConditionTrue {
+			return podNotReady
+		}
+	}
+
+	return string(v1.PodRunning)
+}
+
+// getStage returns the first piece, in the chain order, whose deployment is not ready
+func (r *SplitReconciler) getStage(instance *oaiv1beta1.Split) (oaiv1beta1.SplitStage, error) {
+	templates := GetTemplateConfigMaps(instance.Spec.RAT)
+	for _, splitPiece := range splitPiecesOrder {
+		if _, deployed := templates[splitPiece]; !deployed {
+			continue
+		}
+
+		objectKey := getSplitObjectKey(instance, splitPiece)
+		deployment := &appsv1.Deployment{}
+		exists, err := utils.GetDeployment(r.Client, objectKey, deployment)
+		if err != nil {
+			return "", fmt.Errorf("error getting deployment %s: %w", objectKey.Name, err)
+		}
+
+		if !exists || !deploymentRolledOut(deployment) {
+			return oaiv1beta1.SplitStage(splitPiece), nil
+		}
+	}
+
+	return oaiv1beta1.SplitStageReady, nil
+}
+
+// deploymentRolledOut checks all the replicas of the deployment run its current pod template
+func deploymentRolledOut(deployment *appsv1.Deployment) bool {
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+
+	status := deployment.Status
+	return status.ObservedGeneration >= deployment.Generation && status.UpdatedReplicas == replicas &&
+		status.Replicas == replicas && status.AvailableReplicas == replicas
+}
+
+func getImageName(splitName SplitPiece, rat oaiv1beta1.RAT) string {
 	dockerRepository := os.Getenv(dockerRepositoryEnv)
 	imageName := ""
 	switch splitName {
-	case CU, DU:
+	case CU, CUUP, DU:
 		imageName = lteSoftModemImageName
+		if rat == oaiv1beta1.RATNR {
+			imageName = nrSoftModemImageName
+		}
 	case RU:
 		imageName = ueSoftModemImageName
+		if rat == oaiv1beta1.RATNR {
+			imageName = nrUESoftModemImageName
+		}
 	}
 	return fmt.Sprintf("%s/%s:1", dockerRepository, imageName)
 }
 
 func (r *SplitReconciler) syncConfigMaps(instance *oaiv1beta1.Split, log logr.Logger) error {
-	if err := r.syncTemplatesConfigMap(instance.Namespace, log); err != nil {
+	if err := r.syncTemplatesConfigMap(instance, log); err != nil {
 		return fmt.Errorf("error syncing template config maps: %w", err)
 	}
 
@@ -215,23 +428,78 @@ func (r *SplitReconciler) syncConfigMaps(instance *oaiv1beta1.Split, log logr.Lo
 	return nil
 }
 
-func (r *SplitReconciler) syncTemplatesConfigMap(splitNamespace string, log logr.Logger) error {
-	for _, templateName := range TemplateConfigMaps {
-		operatorObjectKey := types.NamespacedName{
-			Namespace: operatorNamespace,
-			Name:      templateName,
+// templateSource is where the template of a piece is read from and the config map mounted by the piece
+type templateSource struct {
+	source types.NamespacedName
+	// configMapName is the config map with the template in the split namespace
+	configMapName string
+	key           string
+}
+
+// getTemplateSources returns the template of each piece deployed by the split. The template set of the split is used
+// if set, otherwise the templates of the operator namespace. A template set is only read from the split namespace or
+// the operator namespace, so a split can not copy the config maps of other namespaces.
+func getTemplateSources(instance *oaiv1beta1.Split) (map[SplitPiece]*templateSource, error) {
+	sources := make(map[SplitPiece]*templateSource)
+	for splitPiece, templateName := range GetTemplateConfigMaps(instance.Spec.RAT) {
+		sources[splitPiece] = &templateSource{
+			source:        types.NamespacedName{Namespace: operatorNamespace, Name: templateName},
+			configMapName: templateName,
+			key:           templateKey,
+		}
+
+		if set := instance.Spec.TemplateSet; set != nil {
+			setName := fmt.Sprintf("%s-%s", set.Name, set.Version)
+			namespace := set.Namespace
+			if namespace == "" {
+				namespace = instance.Namespace
+			}
+			if namespace != instance.Namespace && namespace != operatorNamespace {
+				return nil, fmt.Errorf("template set '%s' can not be read from namespace '%s', only from the split "+
+					"namespace or '%s'", setName, namespace, operatorNamespace)
+			}
+			sources[splitPiece] = &templateSource{
+				source:        types.NamespacedName{Namespace: namespace, Name: setName},
+				configMapName: setName,
+				key:           string(splitPiece),
+			}
+		}
+	}
+
+	return sources, nil
+}
+
+// syncTemplatesConfigMap copies the templates of the split pieces to the split namespace, the templates already in
+// the split namespace are mounted directly
+func (r *SplitReconciler) syncTemplatesConfigMap(instance *oaiv1beta1.Split, log logr.Logger) error {
+	templates, err := getTemplateSources(instance)
+	if err != nil {
+		return err
+	}
+
+	for splitPiece, template := range templates {
+		cmSource := &v1.ConfigMap{}
+		exists, err := utils.GetConfigMap(r.Client, template.source, cmSource)
+		if err != nil {
+			return fmt.Errorf("error getting template config map %s from namespace %s: %w", template.source.Name,
+				template.source.Namespace, err)
+		} else if !exists {
+			return apierrors.NewNotFound(v1.Resource("configmaps"), template.source.String())
+		}
+
+		content, exists := cmSource.Data[template.key]
+		if !exists {
+			return fmt.Errorf("template config map %s has no template for the %s piece", template.source.Name,
+				splitPiece)
 		}
 
-		cmOperator := &v1.ConfigMap{}
-		exists, err := utils.GetConfigMap(r.Client, operatorObjectKey, cmOperator)
-		if err != nil || !exists {
-			return fmt.Errorf("error getting template config map %s from the operator namespace: %w",
-				operatorObjectKey.Name, err)
+		if template.source.Namespace == instance.Namespace {
+			continue
 		}
 
 		objectKey := types.NamespacedName{
-			Namespace: splitNamespace,
-			Name:      templateName,
+			Namespace: instance.Namespace,
+			Name:      template.configMapName,
 		}
 		// TODO: Use cache
 		cm := &v1.ConfigMap{}
@@ -241,22 +509,27 @@ func (r *SplitReconciler) syncTemplatesConfigMap(splitNamespace string, log logr
 		}
 
 		if exists {
-			if cm.Data["template"] == cmOperator.Data["template"] {
+			if cm.Data[template.key] == content {
 				continue
 			}
 
-			cm.Data["template"] = cmOperator.Data["template"]
+			if cm.Data == nil {
+				cm.Data = make(map[string]string)
+			}
+			cm.Data[template.key] = content
 			if err := r.Update(context.Background(), cm); err != nil {
 				return fmt.Errorf("error updating config map %s in namespace %s: %w", cm.Name, cm.Namespace, err)
 			}
+			recordConfigMapRender(cm, "update")
 		} else {
 			cm.Name = objectKey.Name
 			cm.Namespace = objectKey.Namespace
 			cm.Data = make(map[string]string)
-			cm.Data["template"] = cmOperator.Data["template"]
+			cm.Data[template.key] = content
 			if err := r.Create(context.Background(), cm); err != nil {
 				return fmt.Errorf("error creating config map %s in namespace %s: %w", cm.Name, cm.Namespace, err)
 			}
+			recordConfigMapRender(cm, "create")
 		}
 	}
 
@@ -265,9 +538,60 @@ func (r *SplitReconciler) syncTemplatesConfigMap(splitNamespace string, log logr
 	return nil
 }
 
+// getTemplateHash returns the hash of the template mounted by the piece
+func (r *SplitReconciler) getTemplateHash(instance *oaiv1beta1.Split, template *templateSource) (string, error) {
+	objectKey := types.NamespacedName{
+		Namespace: instance.Namespace,
+		Name:      template.configMapName,
+	}
+
+	cm := &v1.ConfigMap{}
+	exists, err := utils.GetConfigMap(r.Client, objectKey, cm)
+	if err != nil {
+		return "", fmt.Errorf("error getting template config map %s from namespace %s: %w", objectKey.Name,
+			objectKey.Namespace, err)
+	} else if !exists {
+		return "", apierrors.NewNotFound(v1.Resource("configmaps"), objectKey.String())
+	}
+
+	hash := sha256.Sum256([]byte(cm.Data[template.key]))
+
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// getValuesChecksum returns the checksum of the values the piece depends on, ignoring the values in
+// checksumExcludedValues
+func (r *SplitReconciler) getValuesChecksum(instance *oaiv1beta1.Split, splitPiece SplitPiece) (string, error) {
+	objectKey := getSplitObjectKey(instance, splitPiece)
+	cm := &v1.ConfigMap{}
+	exists, err := utils.GetConfigMap(r.Client, objectKey, cm)
+	if err != nil {
+		return "", fmt.Errorf("error getting config map %s: %w", objectKey.Name, err)
+	}
+	if !exists {
+		return "", nil
+	}
+
+	var values []string
+	for _, line := range strings.Split(cm.Data["values"], "\n") {
+		excluded := false
+		for _, key := range checksumExcludedValues[splitPiece] {
+			if strings.HasPrefix(line, key+":") {
+				excluded = true
+			}
+		}
+		if !excluded {
+			values = append(values, line)
+		}
+	}
+
+	checksum := sha256.Sum256([]byte(strings.Join(values, "\n")))
+
+	return hex.EncodeToString(checksum[:]), nil
+}
+
 func (r *SplitReconciler) syncValuesConfigMap(instance *oaiv1beta1.Split, log logr.Logger) error {
-	for split := range Splits {
-		splitPiece := SplitPiece(split)
+	for splitPiece := range GetTemplateConfigMaps(instance.Spec.RAT) {
 		objectKey := getSplitObjectKey(instance, splitPiece)
 
 		log.Info("reconciling config map values for split", logSplitPieceKey, string(splitPiece))
@@ -296,6 +620,7 @@ func (r *SplitReconciler) syncValuesConfigMap(instance *oaiv1beta1.Split, log lo
 			if err != nil {
 				return fmt.Errorf("error updating config map %s: %w", cm.Name, err)
 			}
+			recordConfigMapRender(cm, "update")
 		} else {
 			cm.Name = objectKey.Name
 			cm.Namespace = objectKey.Namespace
@@ -309,16 +634,32 @@ func (r *SplitReconciler) syncValuesConfigMap(instance *oaiv1beta1.Split, log lo
 			if err != nil {
 				return fmt.Errorf("error creating config map %s: %w", cm.Name, err)
 			}
+			recordConfigMapRender(cm, "create")
 		}
 	}
 
 	return nil
 }
 
+// getConfigMapContent returns the values of the piece: the addresses it connects to and the radio of the split
 func (r *SplitReconciler) getConfigMapContent(split SplitPiece, instance *oaiv1beta1.Split) (string, error) {
+	cmContent, err := r.getAddressesConfigMapContent(split, instance)
+	if err != nil {
+		return "", err
+	}
+
+	return cmContent + getRadioConfigMapContent(getRadio(instance)), nil
+}
+
+func (r *SplitReconciler) getAddressesConfigMapContent(split SplitPiece, instance *oaiv1beta1.Split) (string, error) {
 	switch split {
 	case CU:
+		if instance.Spec.RAT == oaiv1beta1.RATNR {
+			return r.getNRCUConfigMapContent(instance)
+		}
 		return r.getCUConfigMapContent(instance)
+	case CUUP:
+		return r.getNRCUUPConfigMapContent(instance)
 	case DU:
 		return r.getDUConfigMapContent(instance)
 	case RU:
@@ -356,6 +697,90 @@ func (r *SplitReconciler) getCUConfigMapContent(instance *oaiv1beta1.Split) (str
 	return fmt.Sprintf(cuConfigMapContentTemplate, cmContent.UPF, cmContent.LocalAddress, cmContent.SouthAddress), nil
 }
 
+// getNRCUConfigMapContent returns the values of the CU-CP, which reaches the AMF through NGAP, the DU through F1-C and
+// the CU-UP through E1
+func (r *SplitReconciler) getNRCUConfigMapContent(instance *oaiv1beta1.Split) (string, error) {
+	cmContent := &nrCUContent{AMF: instance.Spec.AMFIP}
+	if cmContent.AMF == "" {
+		cmContent.AMF = instance.Spec.CoreIP
+	}
+
+	cuPod := &v1.Pod{}
+	exists, err := r.getCUPod(instance, cuPod)
+	if err != nil {
+		return "", fmt.Errorf("error getting CU config map content from cu pod: %w", err)
+	}
+
+	if exists {
+		cmContent.LocalAddress = cuPod.Status.PodIP
+	}
+
+	duPod := &v1.Pod{}
+	exists, err = r.getDUPod(instance, duPod)
+	if err != nil {
+		return "", fmt.Errorf("error getting CU config map content from du pod: %w", err)
+	}
+
+	if exists {
+		cmContent.SouthAddress = duPod.Status.PodIP
+	}
+
+	cuupPod := &v1.Pod{}
+	exists, err = r.getCUUPPod(instance, cuupPod)
+	if err != nil {
+		return "", fmt.Errorf("error getting CU config map content from cu-up pod: %w", err)
+	}
+
+	if exists {
+		cmContent.CUUPAddress = cuupPod.Status.PodIP
+	}
+
+	return fmt.Sprintf(nrCUConfigMapContentTemplate, cmContent.AMF, cmContent.LocalAddress, cmContent.SouthAddress,
+		cmContent.CUUPAddress), nil
+}
+
+// getNRCUUPConfigMapContent returns the values of the CU-UP, which reaches the UPF through N3, the DU through F1-U and
+// the CU-CP through E1
+func (r *SplitReconciler) getNRCUUPConfigMapContent(instance *oaiv1beta1.Split) (string, error) {
+	cmContent := &nrCUUPContent{UPF: instance.Spec.UPFIP}
+	if cmContent.UPF == "" {
+		cmContent.UPF = instance.Spec.CoreIP
+	}
+
+	cuupPod := &v1.Pod{}
+	exists, err := r.getCUUPPod(instance, cuupPod)
+	if err != nil {
+		return "", fmt.Errorf("error getting CU-UP config map content from cu-up pod: %w", err)
+	}
+
+	if exists {
+		cmContent.LocalAddress = cuupPod.Status.PodIP
+	}
+
+	duPod := &v1.Pod{}
+	exists, err = r.getDUPod(instance, duPod)
+	if err != nil {
+		return "", fmt.Errorf("error getting CU-UP config map content from du pod: %w", err)
+	}
+
+	if exists {
+		cmContent.SouthAddress = duPod.Status.PodIP
+	}
+
+	cuPod := &v1.Pod{}
+	exists, err = r.getCUPod(instance, cuPod)
+	if err != nil {
+		return "", fmt.Errorf("error getting CU-UP config map content from cu pod: %w", err)
+	}
+
+	if exists {
+		cmContent.CUCPAddress = cuPod.Status.PodIP
+	}
+
+	return fmt.Sprintf(nrCUUPConfigMapContentTemplate, cmContent.UPF, cmContent.LocalAddress, cmContent.SouthAddress,
+		cmContent.CUCPAddress), nil
+}
+
 func (r *SplitReconciler) getDUConfigMapContent(instance *oaiv1beta1.Split) (string, error) {
 	cmContent := &duContent{}
 
@@ -419,6 +844,16 @@ func (r *SplitReconciler) getRUConfigMapContent(instance *oaiv1beta1.Split) (str
 	return fmt.Sprintf(ruConfigMapContentTemplate, cmContent.NorthAddress, cmContent.LocalAddress), nil
 }
 
+func getRadioConfigMapContent(radio *oaiv1beta1.Radio) string {
+	cmContent := fmt.Sprintf(radioConfigMapContentTemplate, radio.CellID, radio.PCI, radio.TAC, radio.Band,
+		radio.Bandwidth, radio.EARFCN, radio.TxGain)
+	for _, plmn := range radio.PLMNs {
+		cmContent += fmt.Sprintf(plmnConfigMapContentTemplate, plmn.MCC, plmn.MNC)
+	}
+
+	return cmContent
+}
+
 // TODO: Use Informer/Cache
 func (r *SplitReconciler) getCUPod(instance *oaiv1beta1.Split, pod *v1.Pod) (bool, error) {
 	exists, err := r.getPod(instance, CU, pod)
@@ -428,6 +863,15 @@ func (r *SplitReconciler) getCUPod(instance *oaiv1beta1.Split, pod *v1.Pod) (boo
 	return exists, nil
 }
 
+// TODO: Use Informer/Cache
+func (r *SplitReconciler) getCUUPPod(instance *oaiv1beta1.Split, pod *v1.Pod) (bool, error) {
+	exists, err := r.getPod(instance, CUUP, pod)
+	if err != nil {
+		return false, fmt.Errorf("error getting cu-up pod: %w", err)
+	}
+	return exists, nil
+}
+
 // TODO: Use Informer/Cache
 func (r *SplitReconciler) getDUPod(instance *oaiv1beta1.Split, pod *v1.Pod) (bool, error) {
 	exists, err := r.getPod(instance, DU, pod)
@@ -490,13 +934,13 @@ func getResourceName(instance *oaiv1beta1.Split, split SplitPiece) string {
 	return fmt.Sprintf("%s-%s", split, instance.Name)
 }
 
-func getSplitDeployment(instance *oaiv1beta1.Split, split SplitPiece) *appsv1.Deployment {
+func getSplitDeployment(instance *oaiv1beta1.Split, split SplitPiece, template *templateSource, templateHash,
+	valuesChecksum string) *appsv1.Deployment {
 	objectKey := getSplitObjectKey(instance, split)
 	podLabels := map[string]string{
 		"split":       string(split),
 		"split-owner": instance.Name,
 	}
-	boolTrue := true
 	deployment := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      objectKey.Name,
@@ -506,15 +950,22 @@ func getSplitDeployment(instance *oaiv1beta1.Split, split SplitPiece) *appsv1.De
 			Selector: &metav1.LabelSelector{
 				MatchLabels: podLabels,
 			},
+			// the new pod can not run next to the old one: both would bind the ports of the piece and the peers only
+			// associate to one of them
+			Strategy: appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType},
 			Template: v1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Labels: podLabels,
+					Annotations: map[string]string{
+						TemplateHashAnnotation:   templateHash,
+						ValuesChecksumAnnotation: valuesChecksum,
+					},
 				},
 				Spec: v1.PodSpec{
 					Containers: []v1.Container{
 						{
 							Name:            string(split),
-							Image:           getImageName(split),
+							Image:           getImageName(split, instance.Spec.RAT),
 							ImagePullPolicy: v1.PullAlways,
 							VolumeMounts: []v1.VolumeMount{
 								{
@@ -526,20 +977,16 @@ func getSplitDeployment(instance *oaiv1beta1.Split, split SplitPiece) *appsv1.De
 									MountPath: configPath + "/values",
 								},
 							},
-							Ports: getContainerPorts(split),
-							SecurityContext: &v1.SecurityContext{
-								Capabilities: &v1.Capabilities{
-									Add: []v1.Capability{
-										"NET_ADMIN",
-									},
-								},
-								Privileged: &boolTrue,
-							},
+							Ports: getContainerPorts(split, instance.Spec.RAT),
 							Env: []v1.EnvVar{
 								{
 									Name:  "SplitPiece",
 									Value: string(split),
 								},
+								{
+									Name:  "RAT",
+									Value: string(getRAT(instance)),
+								},
 							},
 							Resources: v1.ResourceRequirements{
 								Limits: v1.ResourceList{
@@ -559,11 +1006,11 @@ func getSplitDeployment(instance *oaiv1beta1.Split, split SplitPiece) *appsv1.De
 							VolumeSource: v1.VolumeSource{
 								ConfigMap: &v1.ConfigMapVolumeSource{
 									LocalObjectReference: v1.LocalObjectReference{
-										Name: TemplateConfigMaps[split],
+										Name: template.configMapName,
 									},
 									Items: []v1.KeyToPath{
 										{
-											Key:  "template",
+											Key:  template.key,
 											Path: "template.conf",
 										},
 									},
@@ -592,25 +1039,80 @@ func getSplitDeployment(instance *oaiv1beta1.Split, split SplitPiece) *appsv1.De
 		},
 	}
 
+	setProbes(&deployment.Spec.Template.Spec, split, instance.Spec.RAT)
+	setPerformanceProfile(&deployment.Spec.Template.Spec, getPerformanceProfile(instance.Spec.Performance, split))
+	setSecurityProfile(&deployment.Spec.Template, instance.Spec.SecurityProfile, split)
+	setRoleConstraints(&deployment.Spec.Template.Spec, getRoleConstraints(instance.Spec.Constraints, split))
+
 	nodeName := ""
 	switch split {
 	case CU:
 		nodeName = instance.Spec.CUNode
+	case CUUP:
+		nodeName = instance.Spec.CUUPNode
 	case DU:
 		nodeName = instance.Spec.DUNode
 	case RU:
 		nodeName = instance.Spec.RUNode
 	}
 
-	if nodeName != "" {
+	if instance.Spec.SchedulerName != "" {
+		deployment.Spec.Template.Spec.SchedulerName = instance.Spec.SchedulerName
+	} else if nodeName != "" {
 		deployment.Spec.Template.Spec.NodeName = nodeName
 	}
+	deployment.Spec.Template.Annotations[PodTemplateHashAnnotation] = getPodTemplateHash(&deployment.Spec.Template)
 
 	return deployment
 }
 
-func getContainerPorts(split SplitPiece) []v1.ContainerPort {
-	ports := SplitPorts[split]
+// getPodTemplateHash returns the hash of the pod template, leaving out its own annotation
+func getPodTemplateHash(template *v1.PodTemplateSpec) string {
+	hashed := template.DeepCopy()
+	delete(hashed.Annotations, PodTemplateHashAnnotation)
+	// a pod template is made of plain API types, its marshalling does not fail
+	data, _ := json.Marshal(hashed)
+	hash := sha256.Sum256(data)
+
+	return hex.EncodeToString(hash[:])
+}
+
+// getRoleConstraints returns the constraints of the piece, nil if it has none
+func getRoleConstraints(constraints *oaiv1beta1.PlacementConstraints, split SplitPiece) *oaiv1beta1.RoleConstraints {
+	if constraints == nil {
+		return nil
+	}
+
+	switch split {
+	case CU:
+		return constraints.CU
+	case CUUP:
+		return constraints.CUUP
+	case DU:
+		return constraints.DU
+	}
+
+	return nil
+}
+
+// setRoleConstraints adds the node selector and the tolerations of the role, so the pod is admitted in the nodes the
+// placement chose and it is not evicted by their taints
+func setRoleConstraints(podSpec *v1.PodSpec, constraints *oaiv1beta1.RoleConstraints) {
+	if constraints == nil {
+		return
+	}
+
+	if len(constraints.NodeSelector) > 0 {
+		podSpec.NodeSelector = make(map[string]string)
+		for key, value := range constraints.NodeSelector {
+			podSpec.NodeSelector[key] = value
+		}
+	}
+	podSpec.Tolerations = append(podSpec.Tolerations, constraints.Tolerations...)
+}
+
+func getContainerPorts(split SplitPiece, rat oaiv1beta1.RAT) []v1.ContainerPort {
+	ports := getSplitPorts(rat)[split]
 	containerPorts := []v1.ContainerPort{}
 	for _, port := range ports {
 		containerPort := v1.ContainerPort{
@@ -623,3 +1125,12 @@ func getContainerPorts(split SplitPiece) []v1.ContainerPort {
 
 	return containerPorts
 }
+
+// getRAT returns the RAT of the split, LTE if not set
+func getRAT(instance *oaiv1beta1.Split) oaiv1beta1.RAT {
+	if instance.Spec.RAT == "" {
+		return oaiv1beta1.RATLTE
+	}
+
+	return instance.Spec.RAT
+}
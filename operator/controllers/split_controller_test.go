@@ -3,13 +3,18 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"time"
 
 	oaiv1beta1 "github.com/juliorenner/oai-k8s/operator/api/v1beta1"
+	"github.com/juliorenner/oai-k8s/operator/controllers/utils"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	k8sScheme "k8s.io/client-go/kubernetes/scheme"
@@ -56,6 +61,49 @@ var _ = Describe("split controller unit tests", func() {
 			"upfaddress: 192.1.1.1\nlocaladdress: 192.1.1.243\nsouthaddress: 192.1.1.244\n"),
 	)
 
+	nrInstance := instance.DeepCopy()
+	nrInstance.Spec.RAT = oaiv1beta1.RATNR
+	nrInstance.Spec.AMFIP = "192.1.1.2"
+	DescribeTable("getNRCUConfigMapContent", func(resources []runtime.Object, expectedContent string) {
+		fakeClient := getFakeClient()
+
+		createResources(resources, fakeClient)
+		reconciler := &SplitReconciler{Client: fakeClient}
+
+		cmContent, err := reconciler.getAddressesConfigMapContent(CU, nrInstance)
+		Expect(err).To(BeNil())
+		Expect(cmContent).To(Equal(expectedContent))
+	},
+		Entry("pods not yet created", []runtime.Object{},
+			"amfaddress: 192.1.1.2\nlocaladdress: \nsouthaddress: \ncuupaddress: \n"),
+		Entry("pods created", []runtime.Object{
+			getSplitPod(nrInstance, CU),
+			getSplitPod(nrInstance, CUUP),
+			getSplitPod(nrInstance, DU),
+		},
+			"amfaddress: 192.1.1.2\nlocaladdress: 192.1.1.243\nsouthaddress: 192.1.1.244\ncuupaddress: 192.1.1.246\n"),
+	)
+
+	DescribeTable("getNRCUUPConfigMapContent", func(resources []runtime.Object, expectedContent string) {
+		fakeClient := getFakeClient()
+
+		createResources(resources, fakeClient)
+		reconciler := &SplitReconciler{Client: fakeClient}
+
+		cmContent, err := reconciler.getAddressesConfigMapContent(CUUP, nrInstance)
+		Expect(err).To(BeNil())
+		Expect(cmContent).To(Equal(expectedContent))
+	},
+		Entry("pods not yet created, UPF from the core IP", []runtime.Object{},
+			"upfaddress: 192.1.1.1\nlocaladdress: \nsouthaddress: \ncucpaddress: \n"),
+		Entry("pods created", []runtime.Object{
+			getSplitPod(nrInstance, CU),
+			getSplitPod(nrInstance, CUUP),
+			getSplitPod(nrInstance, DU),
+		},
+			"upfaddress: 192.1.1.1\nlocaladdress: 192.1.1.246\nsouthaddress: 192.1.1.244\ncucpaddress: 192.1.1.243\n"),
+	)
+
 	DescribeTable("getDUConfigMapContent", func(resources []runtime.Object, expectedContent string) {
 		fakeClient := getFakeClient()
 
@@ -167,7 +215,7 @@ var _ = Describe("split controller unit tests", func() {
 			createResources(resources, fakeClient)
 		}
 
-		err := reconciler.syncTemplatesConfigMap(instance.Namespace, log)
+		err := reconciler.syncTemplatesConfigMap(instance, log)
 		Expect(err != nil).To(Equal(isErrorExpected))
 
 		if !isErrorExpected {
@@ -183,6 +231,431 @@ var _ = Describe("split controller unit tests", func() {
 		Entry("sync - create", true, false, false),
 		Entry("sync - update", true, true, false),
 	)
+
+	DescribeTable("syncTemplatesConfigMap template set", func(setNamespace string, pieces []SplitPiece,
+		isErrorExpected bool) {
+		log := zap.New(zap.UseDevMode(true))
+		fakeClient := getFakeClient()
+		reconciler := &SplitReconciler{Client: fakeClient}
+
+		setInstance := instance.DeepCopy()
+		setInstance.Spec.TemplateSet = &oaiv1beta1.TemplateSetReference{Name: "oai", Version: "v1",
+			Namespace: setNamespace}
+		set := &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "oai-v1", Namespace: setNamespace},
+			Data:       map[string]string{},
+		}
+		if setNamespace == "" {
+			set.Namespace = instance.Namespace
+		}
+		for _, piece := range pieces {
+			set.Data[string(piece)] = "template " + string(piece)
+		}
+		createResources([]runtime.Object{set}, fakeClient)
+
+		err := reconciler.syncTemplatesConfigMap(setInstance, log)
+		Expect(err != nil).To(Equal(isErrorExpected))
+
+		if !isErrorExpected {
+			cm := &v1.ConfigMap{}
+			objKey := types.NamespacedName{Namespace: instance.Namespace, Name: "oai-v1"}
+			Expect(fakeClient.Get(context.Background(), objKey, cm)).To(BeNil())
+			for _, piece := range pieces {
+				Expect(cm.Data[string(piece)]).To(Equal("template " + string(piece)))
+			}
+		}
+	},
+		Entry("set in the split namespace", "", []SplitPiece{CU, DU, RU}, false),
+		Entry("set copied from the operator namespace", operatorNamespace, []SplitPiece{CU, DU, RU}, false),
+		Entry("error - set without the ru template", operatorNamespace, []SplitPiece{CU, DU}, true),
+		Entry("error - set in another namespace", "templates", []SplitPiece{CU, DU, RU}, true),
+	)
+
+	DescribeTable("getTemplateHash", func(resources []runtime.Object, isNotFoundExpected bool) {
+		fakeClient := getFakeClient()
+		createResources(resources, fakeClient)
+		reconciler := &SplitReconciler{Client: fakeClient}
+
+		hash, err := reconciler.getTemplateHash(instance, &templateSource{configMapName: "oai-v1", key: "cu"})
+		Expect(apierrors.IsNotFound(err)).To(Equal(isNotFoundExpected))
+		Expect(hash == "").To(Equal(isNotFoundExpected))
+	},
+		Entry("template mounted", []runtime.Object{&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "oai-v1", Namespace: instance.Namespace},
+			Data:       map[string]string{"cu": "template cu"},
+		}}, false),
+		Entry("template config map missing", []runtime.Object{}, true),
+	)
+
+	DescribeTable("syncDeployments rolls", func(changedTemplates []SplitPiece, changedValues []SplitPiece,
+		rolledOut bool, expectedRolled []SplitPiece) {
+		log := zap.New(zap.UseDevMode(true))
+		scheme := runtime.NewScheme()
+		Expect(k8sScheme.AddToScheme(scheme)).To(BeNil())
+		Expect(oaiv1beta1.AddToScheme(scheme)).To(BeNil())
+		fakeClient := fake.NewFakeClientWithScheme(scheme)
+		reconciler := &SplitReconciler{Client: fakeClient, Scheme: scheme}
+
+		setInstance := instance.DeepCopy()
+		setInstance.Spec.TemplateSet = &oaiv1beta1.TemplateSetReference{Name: "oai", Version: "v1"}
+		set := &v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "oai-v1", Namespace: instance.Namespace},
+			Data:       map[string]string{"cu": "cu", "du": "du", "ru": "ru"},
+		}
+		resources := []runtime.Object{set}
+		for piece := range TemplateConfigMaps {
+			values := getSplitConfigMap(setInstance, piece)
+			values.Data = map[string]string{"values": "northaddress: 192.1.1.1\nlocaladdress: 192.1.1.2\n"}
+			resources = append(resources, values)
+		}
+		createResources(resources, fakeClient)
+
+		bringUp(reconciler, fakeClient, setInstance)
+		if !rolledOut {
+			setDeploymentRolledOut(fakeClient, setInstance, CU, false)
+		}
+		checksums := getDeploymentChecksums(fakeClient, setInstance)
+
+		for _, piece := range changedTemplates {
+			set.Data[string(piece)] += " v2"
+		}
+		Expect(fakeClient.Update(context.Background(), set)).To(BeNil())
+		for _, piece := range changedValues {
+			values := &v1.ConfigMap{}
+			Expect(fakeClient.Get(context.Background(), getSplitObjectKey(setInstance, piece), values)).To(BeNil())
+			// the local address is not part of the checksum, only the north one rolls the piece
+			values.Data["values"] = "northaddress: 192.1.1.3\nlocaladdress: 192.1.1.4\n"
+			Expect(fakeClient.Update(context.Background(), values)).To(BeNil())
+		}
+		Expect(reconciler.syncDeployments(setInstance, log)).To(BeNil())
+
+		rolled := []SplitPiece{}
+		for _, piece := range splitPiecesOrder {
+			checksum, exists := getDeploymentChecksums(fakeClient, setInstance)[piece]
+			if exists && checksum != checksums[piece] {
+				rolled = append(rolled, piece)
+			}
+		}
+		Expect(rolled).To(Equal(expectedRolled))
+	},
+		Entry("nothing changed", []SplitPiece{}, []SplitPiece{}, true, []SplitPiece{}),
+		Entry("du template changed", []SplitPiece{DU}, []SplitPiece{}, true, []SplitPiece{DU}),
+		Entry("ru values changed", []SplitPiece{}, []SplitPiece{RU}, true, []SplitPiece{RU}),
+		Entry("cu and du changed, du waits for the cu", []SplitPiece{CU}, []SplitPiece{DU}, true,
+			[]SplitPiece{CU}),
+		Entry("du changed while the cu rolls out", []SplitPiece{DU}, []SplitPiece{}, false, []SplitPiece{}),
+	)
+
+	It("syncDeployments rolls the pieces whose pod template changed", func() {
+		log := zap.New(zap.UseDevMode(true))
+		scheme := runtime.NewScheme()
+		Expect(k8sScheme.AddToScheme(scheme)).To(BeNil())
+		Expect(oaiv1beta1.AddToScheme(scheme)).To(BeNil())
+		fakeClient := fake.NewFakeClientWithScheme(scheme)
+		reconciler := &SplitReconciler{Client: fakeClient, Scheme: scheme}
+
+		movedInstance := instance.DeepCopy()
+		movedInstance.Spec.DUNode = "node2"
+		// the restricted pieces are sent to the API server as unstructured objects
+		movedInstance.Spec.SecurityProfile = oaiv1beta1.SecurityProfileRestricted
+		resources := []runtime.Object{}
+		for _, template := range getTemplateConfigMaps() {
+			resources = append(resources, template)
+		}
+		createResources(resources, fakeClient)
+		Expect(reconciler.syncTemplatesConfigMap(movedInstance, log)).To(BeNil())
+		bringUp(reconciler, fakeClient, movedInstance)
+		checksums := getDeploymentChecksums(fakeClient, movedInstance)
+
+		// neither the templates nor the values change, only the node of the du
+		movedInstance.Spec.DUNode = "node3"
+		Expect(reconciler.syncDeployments(movedInstance, log)).To(BeNil())
+
+		rolled := []SplitPiece{}
+		for piece, checksum := range getDeploymentChecksums(fakeClient, movedInstance) {
+			if checksum != checksums[piece] {
+				rolled = append(rolled, piece)
+			}
+		}
+		Expect(rolled).To(Equal([]SplitPiece{DU}))
+		deployment := &appsv1.Deployment{}
+		Expect(fakeClient.Get(context.Background(), getSplitObjectKey(movedInstance, DU), deployment)).To(BeNil())
+		Expect(deployment.Spec.Template.Spec.NodeName).To(Equal("node3"))
+	})
+
+	It("syncDeployments brings the chain up in order", func() {
+		log := zap.New(zap.UseDevMode(true))
+		scheme := runtime.NewScheme()
+		Expect(k8sScheme.AddToScheme(scheme)).To(BeNil())
+		Expect(oaiv1beta1.AddToScheme(scheme)).To(BeNil())
+		fakeClient := fake.NewFakeClientWithScheme(scheme)
+		reconciler := &SplitReconciler{Client: fakeClient, Scheme: scheme}
+
+		resources := []runtime.Object{}
+		for _, template := range getTemplateConfigMaps() {
+			resources = append(resources, template)
+		}
+		createResources(resources, fakeClient)
+		Expect(reconciler.syncTemplatesConfigMap(instance, log)).To(BeNil())
+
+		for i, piece := range []SplitPiece{CU, DU, RU} {
+			stage, err := reconciler.getStage(instance)
+			Expect(err).To(BeNil())
+			Expect(stage).To(Equal(oaiv1beta1.SplitStage(piece)))
+
+			// the piece is created and the next one waits for it to be ready
+			Expect(reconciler.syncDeployments(instance, log)).To(BeNil())
+			Expect(reconciler.syncDeployments(instance, log)).To(BeNil())
+			deployments := &appsv1.DeploymentList{}
+			Expect(fakeClient.List(context.Background(), deployments)).To(BeNil())
+			Expect(deployments.Items).To(HaveLen(i + 1))
+			for _, deployment := range deployments.Items {
+				Expect(deployment.Spec.Strategy.Type).To(Equal(appsv1.RecreateDeploymentStrategyType))
+			}
+
+			stage, err = reconciler.getStage(instance)
+			Expect(err).To(BeNil())
+			Expect(stage).To(Equal(oaiv1beta1.SplitStage(piece)))
+
+			setDeploymentRolledOut(fakeClient, instance, piece, true)
+		}
+
+		stage, err := reconciler.getStage(instance)
+		Expect(err).To(BeNil())
+		Expect(stage).To(Equal(oaiv1beta1.SplitStageReady))
+	})
+
+	DescribeTable("getReadinessChecks", func(split SplitPiece, rat oaiv1beta1.RAT, expected []string) {
+		checks := []string{}
+		for _, check := range getReadinessChecks(split, rat) {
+			checks = append(checks, check.String())
+		}
+		Expect(checks).To(Equal(expected))
+	},
+		Entry("lte cu listens to f1", CU, oaiv1beta1.RATLTE,
+			[]string{"listening:501", "bound:501", "bound:601", "bound:2152"}),
+		Entry("lte du associated to the cu and listening to the fronthaul", DU, oaiv1beta1.RATLTE,
+			[]string{"associated:501", "listening:50001", "bound:500", "bound:600", "bound:50001", "bound:50011"}),
+		Entry("lte ru associated to the du", RU, oaiv1beta1.RATLTE,
+			[]string{"associated:50001", "bound:50000", "bound:50010"}),
+		Entry("nr cu-cp listens to f1 and e1", CU, oaiv1beta1.RATNR,
+			[]string{"listening:38472", "listening:38462", "bound:38472"}),
+		Entry("nr cu-up associated to the cu-cp", CUUP, oaiv1beta1.RATNR, []string{"associated:38462", "bound:2152"}),
+	)
+
+	DescribeTable("getPodState", func(phase v1.PodPhase, ready v1.ConditionStatus, expected string) {
+		pod := &v1.Pod{Status: v1.PodStatus{Phase: phase}}
+		if ready != "" {
+			pod.Status.Conditions = []v1.PodCondition{{Type: v1.PodReady, Status: ready}}
+		}
+		Expect(getPodState(pod)).To(Equal(expected))
+	},
+		Entry("pod without phase", v1.PodPhase(""), v1.ConditionStatus(""), string(v1.PodPending)),
+		Entry("failed pod", v1.PodFailed, v1.ConditionFalse, string(v1.PodFailed)),
+		Entry("running pod not ready", v1.PodRunning, v1.ConditionFalse, podNotReady),
+		Entry("running pod ready", v1.PodRunning, v1.ConditionTrue, string(v1.PodRunning)),
+	)
+
+	DescribeTable("setSecurityProfile", func(profile oaiv1beta1.SecurityProfile, privileged, readOnly bool,
+		capabilities []v1.Capability, seccomp string) {
+		split := instance.DeepCopy()
+		split.Spec.SecurityProfile = profile
+		template := &templateSource{configMapName: "cu-test-template", key: templateKey}
+		deployment := getSplitDeployment(split, CU, template, "", "")
+
+		podSpec := deployment.Spec.Template.Spec
+		Expect(podSpec.Containers).To(HaveLen(2))
+		securityContext := podSpec.Containers[0].SecurityContext
+		Expect(*securityContext.Privileged).To(Equal(privileged))
+		Expect(securityContext.Capabilities.Add).To(Equal(capabilities))
+		Expect(securityContext.ReadOnlyRootFilesystem != nil && *securityContext.ReadOnlyRootFilesystem).
+			To(Equal(readOnly))
+		// the deprecated seccomp annotation gives way to the seccomp profile of the pod security context
+		Expect(deployment.Spec.Template.Annotations).NotTo(HaveKey(v1.SeccompPodAnnotationKey))
+		object, err := getDeploymentObject(deployment, profile)
+		Expect(err).To(BeNil())
+		seccompType := ""
+		if unstructuredObject, ok := object.(*unstructured.Unstructured); ok {
+			seccompType, _, err = unstructured.NestedString(unstructuredObject.Object, "spec", "template", "spec",
+				"securityContext", "seccompProfile", "type")
+			Expect(err).To(BeNil())
+			Expect(unstructuredObject.GetKind()).To(Equal("Deployment"))
+		}
+		Expect(seccompType).To(Equal(seccomp))
+
+		// the replacer renders the config into a writable volume when the root filesystem is read only
+		writable := false
+		for _, mount := range podSpec.Containers[0].VolumeMounts {
+			writable = writable || mount.MountPath == configPath
+		}
+		Expect(writable).To(Equal(readOnly))
+
+		probeSecurityContext := podSpec.Containers[1].SecurityContext
+		Expect(*probeSecurityContext.Privileged).To(BeFalse())
+		Expect(*probeSecurityContext.RunAsNonRoot).To(BeTrue())
+		Expect(probeSecurityContext.Capabilities.Add).To(BeEmpty())
+	},
+		Entry("default profile is privileged", oaiv1beta1.SecurityProfile(""), true, false,
+			[]v1.Capability{"NET_ADMIN"}, ""),
+		Entry("privileged profile", oaiv1beta1.SecurityProfilePrivileged, true, false,
+			[]v1.Capability{"NET_ADMIN"}, ""),
+		Entry("net admin profile", oaiv1beta1.SecurityProfileNetAdmin, false, true,
+			[]v1.Capability{"NET_ADMIN"}, seccompProfileRuntimeDefault),
+		Entry("restricted profile", oaiv1beta1.SecurityProfileRestricted, false, true,
+			[]v1.Capability(nil), seccompProfileRuntimeDefault),
+	)
+
+	It("keeps NET_ADMIN for the RU of a restricted split", func() {
+		split := instance.DeepCopy()
+		split.Spec.SecurityProfile = oaiv1beta1.SecurityProfileRestricted
+		template := &templateSource{configMapName: "ru-test-template", key: templateKey}
+		deployment := getSplitDeployment(split, RU, template, "", "")
+
+		securityContext := deployment.Spec.Template.Spec.Containers[0].SecurityContext
+		Expect(*securityContext.Privileged).To(BeFalse())
+		Expect(*securityContext.AllowPrivilegeEscalation).To(BeFalse())
+		Expect(*securityContext.ReadOnlyRootFilesystem).To(BeTrue())
+		Expect(securityContext.Capabilities.Add).To(Equal([]v1.Capability{"NET_ADMIN"}))
+		Expect(securityContext.Capabilities.Drop).To(Equal([]v1.Capability{"ALL"}))
+
+		// the other pieces of the split stay restricted
+		deployment = getSplitDeployment(split, DU, template, "", "")
+		securityContext = deployment.Spec.Template.Spec.Containers[0].SecurityContext
+		Expect(securityContext.Capabilities.Add).To(BeEmpty())
+		Expect(*securityContext.RunAsNonRoot).To(BeTrue())
+	})
+
+	DescribeTable("setPerformanceProfile", func(profile *oaiv1beta1.PerformanceProfile, hugePages bool) {
+		split := instance.DeepCopy()
+		split.Spec.Performance = &oaiv1beta1.PerformanceProfiles{DU: profile}
+		template := &templateSource{configMapName: "du-test-template", key: templateKey}
+
+		// the pieces without a profile keep the burstable resources
+		cu := getSplitDeployment(split, CU, template, "", "")
+		Expect(cu.Spec.Template.Spec.Containers[0].Resources.Limits.Cpu().String()).To(Equal(SplitCPULimitValue))
+
+		podSpec := getSplitDeployment(split, DU, template, "", "").Spec.Template.Spec
+		for _, container := range podSpec.Containers {
+			Expect(container.Resources.Requests).To(Equal(container.Resources.Limits))
+		}
+		Expect(podSpec.Containers[0].Resources.Requests.Cpu().Value()).To(Equal(profile.CPUs))
+		Expect(*podSpec.RuntimeClassName).To(Equal(profile.RuntimeClassName))
+
+		mounted := false
+		for _, mount := range podSpec.Containers[0].VolumeMounts {
+			mounted = mounted || mount.MountPath == hugePagesPath
+		}
+		Expect(mounted).To(Equal(hugePages))
+
+		requested := GetPiecesResources(split.Spec.Performance)
+		Expect(requested).To(HaveKey(DU))
+		Expect(requested[DU].ExclusiveCPUs).To(Equal(profile.CPUs))
+	},
+		Entry("du with exclusive cpus", &oaiv1beta1.PerformanceProfile{CPUs: 2, RuntimeClassName: "rt"}, false),
+		Entry("du with huge pages", &oaiv1beta1.PerformanceProfile{CPUs: 4, Memory: utils.NewQuantity("1Gi"),
+			HugePages1Gi: utils.NewQuantity("2Gi"), RuntimeClassName: "rt"}, true),
+	)
+
+	DescribeTable("setRoleConstraints", func(piece SplitPiece, nodeSelector map[string]string, tolerations int) {
+		split := instance.DeepCopy()
+		split.Spec.Constraints = &oaiv1beta1.PlacementConstraints{
+			DU: &oaiv1beta1.RoleConstraints{
+				NodeSelector: map[string]string{"accelerator": "true"},
+				Tolerations:  []v1.Toleration{{Key: "dedicated", Operator: v1.TolerationOpExists}},
+			},
+		}
+		template := &templateSource{configMapName: "test-template", key: templateKey}
+
+		podSpec := getSplitDeployment(split, piece, template, "", "").Spec.Template.Spec
+		Expect(podSpec.NodeSelector).To(Equal(nodeSelector))
+		Expect(podSpec.Tolerations).To(HaveLen(tolerations))
+	},
+		Entry("du with the role constraints", DU, map[string]string{"accelerator": "true"}, 1),
+		Entry("cu without constraints", CU, map[string]string(nil), 0),
+	)
+
+	DescribeTable("probed ports are split ports", func(rat oaiv1beta1.RAT) {
+		for split := range GetTemplateConfigMaps(rat) {
+			ports := []int32{}
+			for _, port := range getSplitPorts(rat)[split] {
+				ports = append(ports, port.number)
+			}
+			for _, check := range getReadinessChecks(split, rat) {
+				if check.state == socketBound {
+					Expect(ports).To(ContainElement(check.port), fmt.Sprintf("%s port %d", split, check.port))
+				}
+			}
+		}
+	},
+		Entry("lte", oaiv1beta1.RATLTE),
+		Entry("nr", oaiv1beta1.RATNR),
+	)
+
+	DescribeTable("getConfigMapContent radio", func(radio *oaiv1beta1.Radio, expectedRadio string) {
+		fakeClient := getFakeClient()
+		reconciler := &SplitReconciler{Client: fakeClient}
+
+		radioInstance := instance.DeepCopy()
+		radioInstance.Spec.Radio = radio
+		cmContent, err := reconciler.getConfigMapContent(RU, radioInstance)
+		Expect(err).To(BeNil())
+		Expect(cmContent).To(Equal("northaddress: \nlocaladdress: \n" + expectedRadio))
+	},
+		Entry("default radio", nil, "radio:\n  cellid: 3584\n  pci: 0\n  tac: 1\n  band: 7\n  bandwidth: 25\n"+
+			"  earfcn: 3000\n  txgain: 90\n  plmns:\n  - mcc: \"208\"\n    mnc: \"93\"\n"),
+		Entry("split radio", &oaiv1beta1.Radio{
+			PLMNs:     []oaiv1beta1.PLMN{{MCC: "001", MNC: "01"}, {MCC: "208", MNC: "093"}},
+			CellID:    12,
+			PCI:       7,
+			TAC:       7,
+			Band:      3,
+			Bandwidth: 50,
+			EARFCN:    1575,
+			TxGain:    80,
+		}, "radio:\n  cellid: 12\n  pci: 7\n  tac: 7\n  band: 3\n  bandwidth: 50\n  earfcn: 1575\n  txgain: 80\n"+
+			"  plmns:\n  - mcc: \"001\"\n    mnc: \"01\"\n  - mcc: \"208\"\n    mnc: \"093\"\n"),
+	)
+
+	DescribeTable("validateCellID", func(radio, otherRadio *oaiv1beta1.Radio, otherRAT oaiv1beta1.RAT,
+		otherCreatedBefore bool, isErrorExpected bool) {
+		scheme := runtime.NewScheme()
+		Expect(k8sScheme.AddToScheme(scheme)).To(BeNil())
+		Expect(oaiv1beta1.AddToScheme(scheme)).To(BeNil())
+		fakeClient := fake.NewFakeClientWithScheme(scheme)
+
+		created := metav1.NewTime(time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC))
+		radioInstance := instance.DeepCopy()
+		radioInstance.CreationTimestamp = created
+		radioInstance.Spec.Radio = radio
+
+		other := instance.DeepCopy()
+		other.Name = "other"
+		other.Namespace = "othernamespace"
+		other.CreationTimestamp = metav1.NewTime(created.Add(time.Minute))
+		if otherCreatedBefore {
+			other.CreationTimestamp = metav1.NewTime(created.Add(-time.Minute))
+		}
+		other.Spec.RAT = otherRAT
+		other.Spec.Radio = otherRadio
+		createResources([]runtime.Object{radioInstance.DeepCopy(), other}, fakeClient)
+
+		reconciler := &SplitReconciler{Client: fakeClient}
+		err := reconciler.validateCellID(radioInstance)
+		Expect(err != nil).To(Equal(isErrorExpected))
+	},
+		Entry("different cell ids", &oaiv1beta1.Radio{CellID: 1}, &oaiv1beta1.Radio{CellID: 2}, oaiv1beta1.RAT(""),
+			true, false),
+		Entry("same cell id, split is the oldest", &oaiv1beta1.Radio{CellID: 1}, &oaiv1beta1.Radio{CellID: 1},
+			oaiv1beta1.RAT(""), false, false),
+		Entry("same cell id, other split is the oldest", &oaiv1beta1.Radio{CellID: 1},
+			&oaiv1beta1.Radio{CellID: 1}, oaiv1beta1.RAT(""), true, true),
+		Entry("other split without radio broadcasts the default cell", &oaiv1beta1.Radio{CellID: LTERadio.CellID},
+			nil, oaiv1beta1.RATLTE, true, true),
+		Entry("both splits without radio", nil, nil, oaiv1beta1.RAT(""), true, true),
+		Entry("same cell id in another RAT", nil, nil, oaiv1beta1.RATNR, true, false),
+		Entry("pci out of the LTE range", &oaiv1beta1.Radio{CellID: 1, PCI: 504}, nil, oaiv1beta1.RAT(""), true,
+			true),
+	)
 })
 
 func getSplitPod(instance *oaiv1beta1.Split, split SplitPiece) *v1.Pod {
@@ -194,6 +667,8 @@ func getSplitPod(instance *oaiv1beta1.Split, split SplitPiece) *v1.Pod {
 		ip = "192.1.1.244"
 	case RU:
 		ip = "192.1.1.245"
+	case CUUP:
+		ip = "192.1.1.246"
 	}
 
 	objectKey := getSplitObjectKey(instance, split)
@@ -261,6 +736,44 @@ func getCreatedTemplatesObjectKeys(namespace string) []types.NamespacedName {
 	return objKeys
 }
 
+func getDeploymentChecksums(k8sClient client.Client, instance *oaiv1beta1.Split) map[SplitPiece]string {
+	checksums := make(map[SplitPiece]string)
+	for piece := range TemplateConfigMaps {
+		deployment := &appsv1.Deployment{}
+		Expect(k8sClient.Get(context.Background(), getSplitObjectKey(instance, piece), deployment)).To(BeNil())
+		annotations := deployment.Spec.Template.Annotations
+		Expect(annotations[PodTemplateHashAnnotation]).NotTo(BeEmpty())
+		checksums[piece] = annotations[PodTemplateHashAnnotation]
+	}
+
+	return checksums
+}
+
+// bringUp syncs the deployments of the split until all of them are created, setting each one ready once created
+func bringUp(reconciler *SplitReconciler, k8sClient client.Client, instance *oaiv1beta1.Split) {
+	log := zap.New(zap.UseDevMode(true))
+	for _, piece := range splitPiecesOrder {
+		if _, deployed := GetTemplateConfigMaps(instance.Spec.RAT)[piece]; !deployed {
+			continue
+		}
+		Expect(reconciler.syncDeployments(instance, log)).To(BeNil())
+		setDeploymentRolledOut(k8sClient, instance, piece, true)
+	}
+}
+
+func setDeploymentRolledOut(k8sClient client.Client, instance *oaiv1beta1.Split, piece SplitPiece, rolledOut bool) {
+	deployment := &appsv1.Deployment{}
+	Expect(k8sClient.Get(context.Background(), getSplitObjectKey(instance, piece), deployment)).To(BeNil())
+	deployment.Status.ObservedGeneration = deployment.Generation
+	deployment.Status.Replicas = 1
+	deployment.Status.UpdatedReplicas = 1
+	deployment.Status.AvailableReplicas = 1
+	if !rolledOut {
+		deployment.Status.AvailableReplicas = 0
+	}
+	Expect(k8sClient.Update(context.Background(), deployment)).To(BeNil())
+}
+
 func getFakeClient() client.Client {
 	scheme := runtime.NewScheme()
 	Expect(k8sScheme.AddToScheme(scheme)).To(BeNil())
@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"reflect"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -28,10 +29,12 @@ import (
 	"github.com/juliorenner/oai-k8s/operator/controllers/algorithm"
 	"github.com/juliorenner/oai-k8s/operator/controllers/utils"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -42,6 +45,9 @@ const (
 	logSplitKey = "split"
 )
 
+// errStalePlan is returned when the positions of the plan do not fit the topology anymore
+var errStalePlan = errors.New("the plan does not fit the topology anymore")
+
 // SplitsPlacerReconciler reconciles a SplitsPlacer object
 type SplitsPlacerReconciler struct {
 	client.Client
@@ -52,8 +58,9 @@ type SplitsPlacerReconciler struct {
 
 // +kubebuilder:rbac:groups=oai.unisinos,resources=splitsplacers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=oai.unisinos,resources=splitsplacers/status,verbs=get;update;patch
-// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;update
 // +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
 
 func (r *SplitsPlacerReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
 	initialTime := time.Now()
@@ -64,18 +71,47 @@ func (r *SplitsPlacerReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error
 
 	splitsPlacer := &oaiv1beta1.SplitsPlacer{}
 	if err := r.Get(ctx, req.NamespacedName, splitsPlacer); err != nil {
+		if apierrors.IsNotFound(err) {
+			deleteSplitsPlacerMetrics(req.Namespace, req.Name)
+		}
 		log.Error(err, "unable to fetch SplitsPlacer")
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	if splitsPlacer.Status.State != "" {
+	if !splitsPlacer.DeletionTimestamp.IsZero() {
+		if err := r.releaseReservations(splitsPlacer, log); err != nil {
+			log.Error(err, "error releasing reservations")
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// a planned placement is applied once the dry run is disabled, as long as its positions still fit
+	if splitsPlacer.Status.State != "" &&
+		!(splitsPlacer.Status.State == oaiv1beta1.PlacerStatePlanned && !splitsPlacer.Spec.DryRun) {
 		//if splitsPlacer.Status.State == oaiv1beta1.PlacerStateFinished {
 		//log.Info("Skipping reconcile. Status Finished and retrigger not enabled.", logSplitKey, splitsPlacer.Name)
 		log.Info("Skipping reconcile, it already executed...")
 		return ctrl.Result{}, nil
 	}
 
+	if !splitsPlacer.Spec.DryRun && !utils.ContainsString(splitsPlacer.Finalizers, reservationsFinalizer) {
+		splitsPlacer.Finalizers = append(splitsPlacer.Finalizers, reservationsFinalizer)
+		if err := r.Update(ctx, splitsPlacer); err != nil {
+			return ctrl.Result{}, fmt.Errorf("error adding splits placer finalizer: %w", err)
+		}
+	}
+
 	if err := r.syncTopology(splitsPlacer, log); err != nil {
+		if errors.Is(err, errStalePlan) {
+			r.Recorder.Event(splitsPlacer, v1.EventTypeWarning, "StalePlan",
+				"The plan does not fit anymore, a new plan was made and the dry run enabled again")
+			return ctrl.Result{}, r.savePlan(splitsPlacer)
+		}
+		if IsConflict(err) {
+			log.Info("topology reservations changed during the placement, retrying")
+			return ctrl.Result{Requeue: true}, nil
+		}
 		if errStatus := r.updateStatus(splitsPlacer, oaiv1beta1.PlacerStateError); errStatus != nil {
 			log.Error(errStatus, "error updating splits placer status after error syncing topology")
 		}
@@ -84,6 +120,18 @@ func (r *SplitsPlacerReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error
 		return ctrl.Result{}, fmt.Errorf("error syncing topology: %s", err)
 	}
 
+	if splitsPlacer.Spec.DryRun {
+		allocationTime := time.Since(initialTime)
+		splitsPlacer.Status.AllocationTime = fmt.Sprintf("%f", allocationTime.Seconds())
+		splitsPlacer.Status.AllocationMilliseconds = allocationTime.Milliseconds()
+		recordPlacement(splitsPlacer, allocationTime)
+		r.Recorder.Event(splitsPlacer, v1.EventTypeNormal, "Planned", "Placement planned, no split created")
+		if err := r.updateStatus(splitsPlacer, oaiv1beta1.PlacerStatePlanned); err != nil {
+			log.Error(err, "error updating splits placer status")
+		}
+		return ctrl.Result{}, nil
+	}
+
 	if err := r.syncSplits(splitsPlacer, log); err != nil {
 		if err := r.updateStatus(splitsPlacer, oaiv1beta1.PlacerStateError); err != nil {
 			log.Error(err, "error updating splits placer status after error syncing splits")
@@ -91,14 +139,20 @@ func (r *SplitsPlacerReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error
 		return ctrl.Result{}, fmt.Errorf("error syncing splits: %w", err)
 	}
 
-	splitsPlacer.Status.AllocationTime = fmt.Sprintf("%f", time.Since(initialTime).Seconds())
+	allocationTime := time.Since(initialTime)
+	splitsPlacer.Status.AllocationTime = fmt.Sprintf("%f", allocationTime.Seconds())
+	splitsPlacer.Status.AllocationMilliseconds = allocationTime.Milliseconds()
+	recordPlacement(splitsPlacer, allocationTime)
 
 	r.Recorder.Event(splitsPlacer, v1.EventTypeNormal, "Sync", "Synced successfully")
 
+	// the update returns the status stored, which would discard the plan removal and the placement results
+	status := splitsPlacer.Status.DeepCopy()
 	if err := r.Update(context.Background(), splitsPlacer); err != nil {
 		log.Error(err, "error updating splits placer spec")
 		return ctrl.Result{}, fmt.Errorf("error updating splits placer: %w", err)
 	}
+	splitsPlacer.Status = *status
 
 	if err := r.updateStatus(splitsPlacer, oaiv1beta1.PlacerStateFinished); err != nil {
 		log.Error(err, "error updating splits placer status")
@@ -107,6 +161,22 @@ func (r *SplitsPlacerReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error
 	return ctrl.Result{}, nil
 }
 
+// savePlan stores the dry run and the plan made when the previous plan went stale
+func (r *SplitsPlacerReconciler) savePlan(splitsPlacer *oaiv1beta1.SplitsPlacer) error {
+	// the update returns the status stored, which would discard the new plan
+	status := splitsPlacer.Status.DeepCopy()
+	if err := r.Update(context.Background(), splitsPlacer); err != nil {
+		return fmt.Errorf("error enabling the dry run of the splits placer: %w", err)
+	}
+	splitsPlacer.Status = *status
+	splitsPlacer.Status.State = oaiv1beta1.PlacerStatePlanned
+	if err := r.Status().Update(context.Background(), splitsPlacer); err != nil {
+		return fmt.Errorf("error updating splitsplacer status: %w", err)
+	}
+
+	return nil
+}
+
 func (r *SplitsPlacerReconciler) updateStatus(splitsPlacer *oaiv1beta1.SplitsPlacer, desiredState oaiv1beta1.SplitsPlacerState) error {
 	if splitsPlacer.Status.State != desiredState {
 		splitsPlacer.Status.State = desiredState
@@ -124,9 +194,14 @@ func (r *SplitsPlacerReconciler) syncTopology(splitsPlacer *oaiv1beta1.SplitsPla
 		return nil
 	}
 
-	topology := &oaiv1beta1.Topology{}
 	topologyKey := r.getObjectKey(splitsPlacer.Spec.TopologyConfig, splitsPlacer.Namespace)
-	if err := r.readTopology(topologyKey, topology); err != nil {
+	topologyCM, err := GetTopologyConfigMap(r.Client, topologyKey)
+	if err != nil {
+		return fmt.Errorf("error reading topology: %w", err)
+	}
+
+	topology := &oaiv1beta1.Topology{}
+	if err := parseTopology(topologyCM, topology); err != nil {
 		return fmt.Errorf("error reading topology: %w", err)
 	}
 
@@ -137,12 +212,17 @@ func (r *SplitsPlacerReconciler) syncTopology(splitsPlacer *oaiv1beta1.SplitsPla
 		return errors.New("error validating topology nodes")
 	}
 
+	if splitsPlacer.Spec.SchedulerName != "" && !splitsPlacer.Spec.DryRun {
+		log.Info("placement delegated to the scheduler", "scheduler", splitsPlacer.Spec.SchedulerName)
+		return nil
+	}
+
 	disaggregation := map[string]*oaiv1beta1.Disaggregation{}
-	if err := r.readDisaggregationsMetadata(disaggregation); err != nil {
+	if err := ReadDisaggregationsMetadata(r.Client, disaggregation); err != nil {
 		return fmt.Errorf("error reading disaggregation metadata: %w", err)
 	}
 
-	err := r.place(splitsPlacer, topology, disaggregation, log)
+	err = r.place(splitsPlacer, topology, topologyCM, disaggregation, log)
 
 	if err != nil {
 		return fmt.Errorf("error placing service functions: %w", err)
@@ -178,11 +258,22 @@ func (r *SplitsPlacerReconciler) validateTopologyNodes(topology *oaiv1beta1.Topo
 	return nil
 }
 
+// syncSplits creates the split of each chain placed and moves the existing ones to the nodes of their chain. The
+// splits of the chains no longer allocated are deleted. With a scheduler, the splits are created without nodes and the
+// scheduler places them.
 func (r *SplitsPlacerReconciler) syncSplits(splitsPlacer *oaiv1beta1.SplitsPlacer, log logr.Logger) error {
+	cores, err := r.getCores(splitsPlacer)
+	if err != nil {
+		return fmt.Errorf("error reading core addresses: %w", err)
+	}
+
+	allocated := make(map[string]bool)
 	for _, ru := range splitsPlacer.Spec.RUs {
-		if ru.CUNode == "" || ru.DUNode == "" {
+		if splitsPlacer.Spec.SchedulerName == "" && (ru.CUNode == "" || ru.DUNode == "") {
 			continue
 		}
+		allocated[ru.SplitName] = true
+
 		// Check if split exists
 		splitKey := r.getObjectKey(ru.SplitName, splitsPlacer.Namespace)
 
@@ -192,48 +283,147 @@ func (r *SplitsPlacerReconciler) syncSplits(splitsPlacer *oaiv1beta1.SplitsPlace
 			return fmt.Errorf("error checking if split exists: %w", err)
 		}
 
+		desired := r.getSplitTemplate(ru, splitsPlacer, cores[ru.CoreNode])
 		if !exists {
 			log.Info("Creating split...", logSplitKey, ru.SplitName)
-			split = r.getSplitTemplate(ru, splitsPlacer.Namespace, splitsPlacer.Spec.CoreIP)
-			if err := ctrl.SetControllerReference(splitsPlacer, split, r.Scheme); err != nil {
+			if err := ctrl.SetControllerReference(splitsPlacer, desired, r.Scheme); err != nil {
 				return fmt.Errorf("error setting split owner reference: %w", err)
 			}
-			err := r.Create(context.Background(), split)
-			if err != nil {
+			if err := r.Create(context.Background(), desired); err != nil {
 				return fmt.Errorf("error creating split %s: %w", ru.SplitName, err)
 			}
+			continue
 		}
 
-		log.Info("Split already exists, skipping creation...", logSplitKey, ru.SplitName)
+		if splitsPlacer.Spec.SchedulerName != "" || !metav1.IsControlledBy(split, splitsPlacer) ||
+			!splitMoved(split, desired) {
+			log.Info("Split already exists, skipping creation...", logSplitKey, ru.SplitName)
+			continue
+		}
+
+		log.Info("Moving split to the nodes of its chain...", logSplitKey, ru.SplitName)
+		split.Spec.CUNode = desired.Spec.CUNode
+		split.Spec.CUUPNode = desired.Spec.CUUPNode
+		split.Spec.DUNode = desired.Spec.DUNode
+		split.Spec.CoreIP = desired.Spec.CoreIP
+		split.Spec.AMFIP = desired.Spec.AMFIP
+		split.Spec.UPFIP = desired.Spec.UPFIP
+		split.Spec.CoreNode = desired.Spec.CoreNode
+		if err := r.Update(context.Background(), split); err != nil {
+			return fmt.Errorf("error moving split %s: %w", ru.SplitName, err)
+		}
+	}
+
+	return r.deleteUnallocatedSplits(splitsPlacer, allocated, log)
+}
+
+// splitMoved returns true if the chain of the split was placed in other nodes or served by another core
+func splitMoved(split, desired *oaiv1beta1.Split) bool {
+	return split.Spec.CoreNode != desired.Spec.CoreNode || split.Spec.CUNode != desired.Spec.CUNode ||
+		split.Spec.CUUPNode != desired.Spec.CUUPNode || split.Spec.DUNode != desired.Spec.DUNode
+}
+
+// deleteUnallocatedSplits deletes the splits of the splits placer whose chain is not allocated anymore, either left
+// out of the placement or removed from the RUs
+func (r *SplitsPlacerReconciler) deleteUnallocatedSplits(splitsPlacer *oaiv1beta1.SplitsPlacer,
+	allocated map[string]bool, log logr.Logger) error {
+	splitList := &oaiv1beta1.SplitList{}
+	if err := r.List(context.Background(), splitList, client.InNamespace(splitsPlacer.Namespace)); err != nil {
+		return fmt.Errorf("error listing splits: %w", err)
+	}
+
+	for i := range splitList.Items {
+		split := &splitList.Items[i]
+		if allocated[split.Name] || !metav1.IsControlledBy(split, splitsPlacer) {
+			continue
+		}
+
+		log.Info("Deleting split of chain not allocated...", logSplitKey, split.Name)
+		if err := r.Delete(context.Background(), split); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("error deleting split %s: %w", split.Name, err)
+		}
 	}
 
 	return nil
 }
 
-func (r *SplitsPlacerReconciler) getSplitTemplate(ru *oaiv1beta1.ChainPosition, namespace string,
-	coreIP string) *oaiv1beta1.Split {
-	return &oaiv1beta1.Split{
+// getSplitTemplate returns the split of the chain. The addresses of the core node serving the chain are used if it
+// defines them, otherwise the splits placer coreIP is used.
+func (r *SplitsPlacerReconciler) getSplitTemplate(ru *oaiv1beta1.ChainPosition, splitsPlacer *oaiv1beta1.SplitsPlacer,
+	core *oaiv1beta1.Node) *oaiv1beta1.Split {
+	split := &oaiv1beta1.Split{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      ru.SplitName,
-			Namespace: namespace,
+			Namespace: splitsPlacer.Namespace,
 		},
 		Spec: oaiv1beta1.SplitSpec{
-			CoreIP: coreIP,
-			RUNode: ru.RUNode,
-			CUNode: ru.CUNode,
-			DUNode: ru.DUNode,
+			CoreIP:          splitsPlacer.Spec.CoreIP,
+			CoreNode:        ru.CoreNode,
+			RAT:             splitsPlacer.Spec.RAT,
+			RUNode:          ru.RUNode,
+			CUNode:          ru.CUNode,
+			CUUPNode:        ru.CUUPNode,
+			DUNode:          ru.DUNode,
+			SchedulerName:   splitsPlacer.Spec.SchedulerName,
+			Radio:           ru.Radio,
+			TemplateSet:     splitsPlacer.Spec.TemplateSet,
+			SecurityProfile: splitsPlacer.Spec.SecurityProfile,
+			Performance:     splitsPlacer.Spec.Performance,
+			Constraints:     splitsPlacer.Spec.Constraints,
 		},
 	}
+
+	SetSplitCore(split, ru.CoreNode, core)
+
+	return split
 }
 
-func (r *SplitsPlacerReconciler) readDisaggregationsMetadata(disaggregation map[string]*oaiv1beta1.Disaggregation) error {
+// SetSplitCore points the split to the core node serving its chain. The addresses of the core are used if it defines
+// them, otherwise the split keeps its coreIP.
+func SetSplitCore(split *oaiv1beta1.Split, coreNode string, core *oaiv1beta1.Node) {
+	split.Spec.CoreNode = coreNode
+	if core == nil {
+		return
+	}
+
+	if core.CoreIP != "" {
+		split.Spec.CoreIP = core.CoreIP
+	}
+	split.Spec.AMFIP = core.AMFIP
+	split.Spec.UPFIP = core.UPFIP
+}
+
+// getCores returns the core nodes of the topology with their addresses. The key is the core node.
+func (r *SplitsPlacerReconciler) getCores(splitsPlacer *oaiv1beta1.SplitsPlacer) (map[string]*oaiv1beta1.Node, error) {
+	cores := make(map[string]*oaiv1beta1.Node)
+	if splitsPlacer.Spec.TopologyConfig == "" {
+		return cores, nil
+	}
+
+	topology := &oaiv1beta1.Topology{}
+	if err := ReadTopology(r.Client, r.getObjectKey(splitsPlacer.Spec.TopologyConfig, splitsPlacer.Namespace),
+		topology); err != nil {
+		return nil, err
+	}
+
+	for nodeName, node := range topology.Nodes {
+		if node.Core {
+			cores[nodeName] = node
+		}
+	}
+
+	return cores, nil
+}
+
+// ReadDisaggregationsMetadata reads the disaggregations available from the operator namespace config map.
+func ReadDisaggregationsMetadata(k8sClient client.Client, disaggregation map[string]*oaiv1beta1.Disaggregation) error {
 	cmObjectKey := types.NamespacedName{
 		Namespace: operatorNamespace,
 		Name:      DisaggregationConfigMapName,
 	}
 
 	cm := &v1.ConfigMap{}
-	if exists, err := utils.GetConfigMap(r.Client, cmObjectKey, cm); err != nil {
+	if exists, err := utils.GetConfigMap(k8sClient, cmObjectKey, cm); err != nil {
 		return fmt.Errorf("error getting disaggregation config map: %w", err)
 	} else if !exists {
 		return fmt.Errorf("disaggregation config map '%s' not found in namespace '%s'", cmObjectKey.Name,
@@ -248,14 +438,17 @@ func (r *SplitsPlacerReconciler) readDisaggregationsMetadata(disaggregation map[
 	return nil
 }
 
-func (r *SplitsPlacerReconciler) readTopology(objectKey types.NamespacedName, topology *oaiv1beta1.Topology) error {
-	cm := &v1.ConfigMap{}
-	if exists, err := utils.GetConfigMap(r.Client, objectKey, cm); err != nil {
-		return fmt.Errorf("error getting topology '%s' config map: %w", objectKey.String(), err)
-	} else if !exists {
-		return fmt.Errorf("topology config map '%s' does not exists", objectKey.String())
+// ReadTopology reads the topology described in the given config map.
+func ReadTopology(k8sClient client.Client, objectKey types.NamespacedName, topology *oaiv1beta1.Topology) error {
+	cm, err := GetTopologyConfigMap(k8sClient, objectKey)
+	if err != nil {
+		return err
 	}
 
+	return parseTopology(cm, topology)
+}
+
+func parseTopology(cm *v1.ConfigMap, topology *oaiv1beta1.Topology) error {
 	topologyData, exists := cm.Data[topologyKey]
 	if !exists {
 		return fmt.Errorf("invalid topology config map. Key '%s' does not exist", topologyKey)
@@ -276,7 +469,7 @@ func (r *SplitsPlacerReconciler) getObjectKey(name string, namespace string) typ
 }
 
 func (r *SplitsPlacerReconciler) place(splitsPlacer *oaiv1beta1.SplitsPlacer, topology *oaiv1beta1.Topology,
-	disaggregations map[string]*oaiv1beta1.Disaggregation, log logr.Logger) error {
+	topologyCM *v1.ConfigMap, disaggregations map[string]*oaiv1beta1.Disaggregation, log logr.Logger) error {
 
 	nodeList := &v1.NodeList{}
 	if err := utils.ListNodes(r.Client, nodeList); err != nil {
@@ -288,9 +481,87 @@ func (r *SplitsPlacerReconciler) place(splitsPlacer *oaiv1beta1.SplitsPlacer, to
 		CPU:    *utils.NewQuantity(SplitCPURequestValue),
 	}
 
-	topologyGraph := algorithm.NewPlacementBFS(topology, disaggregations, nodeList, requestedResources, log)
+	reservations, err := ReadTopologyReservations(topologyCM)
+	if err != nil {
+		return fmt.Errorf("error reading topology reservations: %w", err)
+	}
+
+	nodesUsage, err := r.getNodesUsage(splitsPlacer, reservations)
+	if err != nil {
+		return fmt.Errorf("error getting nodes usage: %w", err)
+	}
+
+	topologyGraph := algorithm.NewPlacementBFS(topology, disaggregations, nodeList, nodesUsage, requestedResources,
+		log)
+	for piece, pieceResources := range GetPiecesResources(splitsPlacer.Spec.Performance) {
+		topologyGraph.SetPieceResources(string(piece), pieceResources)
+	}
+
+	splitsPlacerKey := r.getObjectKey(splitsPlacer.Name, splitsPlacer.Namespace)
+	topologyGraph.ReserveLinks(ReservedLinks(reservations, splitsPlacerKey))
+	topologyGraph.ReserveCores(ReservedCores(reservations, splitsPlacerKey))
+	topologyGraph.SetCoreSelection(splitsPlacer.Spec.CoreSelection)
+	topologyGraph.SetRAT(splitsPlacer.Spec.RAT)
+	topologyGraph.SetConstraints(splitsPlacer.Spec.Constraints)
+	topologyGraph.SetPreemption(splitsPlacer.Spec.Preemption)
+
+	plan := splitsPlacer.Status.Plan
+	applyingPlan := !splitsPlacer.Spec.DryRun && plan != nil
+	rus := splitsPlacer.Spec.RUs
+	if splitsPlacer.Spec.DryRun || applyingPlan {
+		// the placement is computed over a copy so the spec is kept untouched until it is applied
+		rus = make([]*oaiv1beta1.ChainPosition, len(splitsPlacer.Spec.RUs))
+		for i, ru := range splitsPlacer.Spec.RUs {
+			rus[i] = ru.DeepCopy()
+		}
+	}
+	// the planned positions are kept as running chains, so they are only moved if they do not fit anymore
+	if applyingPlan {
+		setPlannedPositions(rus, plan)
+	}
+
+	success, err := topologyGraph.Place(rus)
+	if err != nil {
+		return err
+	}
+
+	stalePlan := applyingPlan && !followsPlan(rus, plan)
+	if applyingPlan && !stalePlan {
+		splitsPlacer.Spec.RUs = rus
+	}
 
-	success, err := topologyGraph.Place(splitsPlacer.Spec.RUs)
+	splitsPlacer.Status.Plan = nil
+	setExplanations(splitsPlacer, rus, topologyGraph.GetRejections())
+	splitsPlacer.Status.Evictions = topologyGraph.GetEvictions()
+	if splitsPlacer.Spec.DryRun || stalePlan {
+		splitsPlacer.Status.Plan = getPlacementPlan(rus)
+		splitsPlacer.Status.AllocatedRUs = len(splitsPlacer.Status.Plan.Positions)
+		setRemainingBandwidth(splitsPlacer, topologyGraph.GetRemainingBandwidth())
+		setPlacementStatistics(splitsPlacer, topology, rus, topologyGraph)
+		if stalePlan {
+			// the new plan has to be reviewed again before it is applied
+			splitsPlacer.Spec.DryRun = true
+			return errStalePlan
+		}
+		return nil
+	}
+
+	// the ledger is only written if no other placement changed it meanwhile, otherwise the placement is retried
+	var updatedReservations *oaiv1beta1.TopologyReservations
+	if err := UpdateTopologyReservations(r.Client, topologyCM, func(reservations *oaiv1beta1.TopologyReservations) {
+		updatedReservations = reservations
+		RemoveChainReservations(reservations, splitsPlacerKey)
+		for _, ru := range splitsPlacer.Spec.RUs {
+			if ru.DUNode == "" || ru.CUNode == "" {
+				continue
+			}
+			reservations.Chains[GetChainKey(splitsPlacerKey, ru.SplitName)] = GetChainReservation(ru,
+				topologyGraph.ChainLinks(ru), splitsPlacer.Spec.Performance)
+		}
+	}); err != nil {
+		return err
+	}
+	recordTopologyReservations(topologyCM.Namespace+"/"+topologyCM.Name, topology, updatedReservations)
 
 	var notAllocatedRUs []*oaiv1beta1.ChainPosition
 	for _, ru := range splitsPlacer.Spec.RUs {
@@ -301,22 +572,258 @@ func (r *SplitsPlacerReconciler) place(splitsPlacer *oaiv1beta1.SplitsPlacer, to
 
 	splitsPlacer.Status.AllocatedRUs = len(splitsPlacer.Spec.RUs) - len(notAllocatedRUs)
 
-	for k, v := range topologyGraph.GetRemainingBandwidth() {
+	setRemainingBandwidth(splitsPlacer, topologyGraph.GetRemainingBandwidth())
+	setPlacementStatistics(splitsPlacer, topology, splitsPlacer.Spec.RUs, topologyGraph)
+
+	if !success {
+		return errors.New("unable to allocate all RUs")
+	}
+
+	return nil
+}
+
+func setRemainingBandwidth(splitsPlacer *oaiv1beta1.SplitsPlacer, remainingBandwidth map[string]*utils.Link) {
+	for k, v := range remainingBandwidth {
 		if splitsPlacer.Status.RemainingBandwidth == nil {
 			splitsPlacer.Status.RemainingBandwidth = make(map[string]string)
 		}
 		splitsPlacer.Status.RemainingBandwidth[k] = fmt.Sprintf("%f", v.AvailableBandwidth)
 	}
+}
 
-	if err != nil {
-		return err
-	} else if !success {
-		return errors.New("unable to allocate all RUs")
+// setExplanations writes to the status why each RU not allocated could not be placed
+func setExplanations(splitsPlacer *oaiv1beta1.SplitsPlacer, rus []*oaiv1beta1.ChainPosition,
+	rejections map[string][]*oaiv1beta1.PathRejection) {
+	splitsPlacer.Status.Explanations = nil
+	for _, ru := range rus {
+		if ru.DUNode != "" && ru.CUNode != "" {
+			continue
+		}
+
+		splitsPlacer.Status.Explanations = append(splitsPlacer.Status.Explanations, &oaiv1beta1.RUExplanation{
+			SplitName:  ru.SplitName,
+			RUNode:     ru.RUNode,
+			Rejections: rejections[ru.SplitName],
+		})
+	}
+}
+
+// getPlacementPlan splits the RUs placed by the algorithm between the allocated and the unallocated ones
+// setPlannedPositions sets the position proposed by the plan in each RU, the RUs the plan left unallocated are cleared
+func setPlannedPositions(rus []*oaiv1beta1.ChainPosition, plan *oaiv1beta1.PlacementPlan) {
+	planned := make(map[string]*oaiv1beta1.ChainPosition)
+	for _, position := range plan.Positions {
+		planned[position.SplitName] = position
+	}
+
+	for _, ru := range rus {
+		position := planned[ru.SplitName]
+		if position == nil {
+			position = &oaiv1beta1.ChainPosition{CoreNode: ru.CoreNode}
+		}
+		ru.CoreNode = position.CoreNode
+		ru.CUNode, ru.CUUPNode, ru.DUNode = position.CUNode, position.CUUPNode, position.DUNode
+		ru.Path = position.Path
+		ru.Disaggregation = position.Disaggregation
+	}
+}
+
+// followsPlan returns true if every RU was placed in the position proposed by the plan, and the RUs the plan left
+// unallocated are still unallocated
+func followsPlan(rus []*oaiv1beta1.ChainPosition, plan *oaiv1beta1.PlacementPlan) bool {
+	planned := make(map[string]*oaiv1beta1.ChainPosition)
+	for _, position := range plan.Positions {
+		planned[position.SplitName] = position
+	}
+
+	for _, ru := range rus {
+		position, exists := planned[ru.SplitName]
+		allocated := ru.CUNode != "" && ru.DUNode != ""
+		if !exists {
+			if allocated {
+				return false
+			}
+			continue
+		}
+
+		if !allocated || ru.CoreNode != position.CoreNode || ru.CUNode != position.CUNode ||
+			ru.CUUPNode != position.CUUPNode || ru.DUNode != position.DUNode ||
+			!reflect.DeepEqual(ru.Path, position.Path) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func getPlacementPlan(rus []*oaiv1beta1.ChainPosition) *oaiv1beta1.PlacementPlan {
+	plan := &oaiv1beta1.PlacementPlan{}
+	for _, ru := range rus {
+		if ru.DUNode == "" || ru.CUNode == "" {
+			plan.UnallocatedRUs = append(plan.UnallocatedRUs, ru.SplitName)
+			continue
+		}
+		plan.Positions = append(plan.Positions, ru)
+	}
+
+	return plan
+}
+
+// GetChainReservation returns the ledger entry of the chain: its core, the bandwidth reserved in each link and the
+// resources of the pieces reserved in each node.
+func GetChainReservation(ru *oaiv1beta1.ChainPosition, links map[string]float32,
+	profiles *oaiv1beta1.PerformanceProfiles) *oaiv1beta1.ChainReservation {
+	chain := &oaiv1beta1.ChainReservation{
+		Core:  ru.CoreNode,
+		Links: links,
+		Nodes: make(map[string]*oaiv1beta1.NodeReservation),
+	}
+
+	for piece, nodeName := range getChainPieces(ru) {
+		node, exists := chain.Nodes[nodeName]
+		if !exists {
+			node = &oaiv1beta1.NodeReservation{}
+			chain.Nodes[nodeName] = node
+		}
+		requestedResources := getPieceResources(profiles, piece)
+		node.CPU.Add(requestedResources.CPU)
+		node.Memory.Add(requestedResources.Memory)
+		node.ExclusiveCPUs += requestedResources.ExclusiveCPUs
+		node.HugePages1Gi.Add(requestedResources.HugePages1Gi)
+		node.HugePages2Mi.Add(requestedResources.HugePages2Mi)
+	}
+
+	return chain
+}
+
+// releaseReservations removes the chains of the splits placer from the topology ledger and then the finalizer that
+// protects them.
+func (r *SplitsPlacerReconciler) releaseReservations(splitsPlacer *oaiv1beta1.SplitsPlacer, log logr.Logger) error {
+	if !utils.ContainsString(splitsPlacer.Finalizers, reservationsFinalizer) {
+		return nil
+	}
+
+	if splitsPlacer.Spec.TopologyConfig != "" {
+		topologyKey := r.getObjectKey(splitsPlacer.Spec.TopologyConfig, splitsPlacer.Namespace)
+		splitsPlacerKey := r.getObjectKey(splitsPlacer.Name, splitsPlacer.Namespace)
+		err := retry.OnError(retry.DefaultRetry, IsConflict, func() error {
+			cm := &v1.ConfigMap{}
+			if exists, err := utils.GetConfigMap(r.Client, topologyKey, cm); err != nil || !exists {
+				return err
+			}
+
+			var updatedReservations *oaiv1beta1.TopologyReservations
+			if err := UpdateTopologyReservations(r.Client, cm, func(reservations *oaiv1beta1.TopologyReservations) {
+				updatedReservations = reservations
+				RemoveChainReservations(reservations, splitsPlacerKey)
+			}); err != nil {
+				return err
+			}
+
+			topology := &oaiv1beta1.Topology{}
+			if err := parseTopology(cm, topology); err == nil {
+				recordTopologyReservations(topologyKey.String(), topology, updatedReservations)
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("error releasing topology reservations: %w", err)
+		}
+		log.Info("topology reservations released")
+	}
+
+	splitsPlacer.Finalizers = utils.RemoveString(splitsPlacer.Finalizers, reservationsFinalizer)
+	if err := r.Update(context.Background(), splitsPlacer); err != nil {
+		return fmt.Errorf("error removing splits placer finalizer: %w", err)
 	}
 
 	return nil
 }
 
+// getNodesUsage returns the resources in use in each node: the requests of the pods already bound to the nodes, the
+// nodes reserved in the topology ledger and the pieces of the splits placers out of the ledger whose pods were not
+// created yet. The pods of the splits placer own splits are not counted, their pieces are placed again.
+func (r *SplitsPlacerReconciler) getNodesUsage(splitsPlacer *oaiv1beta1.SplitsPlacer,
+	reservations *oaiv1beta1.TopologyReservations) (map[string]*utils.RequestedResources, error) {
+	podList := &v1.PodList{}
+	if err := utils.ListPods(r.Client, podList); err != nil {
+		return nil, fmt.Errorf("error listing K8S pods: %w", err)
+	}
+
+	ownSplits := utils.NewStringSet()
+	for _, ru := range splitsPlacer.Spec.RUs {
+		ownSplits.Add(ru.SplitName)
+	}
+
+	var pods []v1.Pod
+	for _, pod := range podList.Items {
+		if pod.Namespace == splitsPlacer.Namespace && ownSplits.Has(pod.Labels["split-owner"]) {
+			continue
+		}
+		pods = append(pods, pod)
+	}
+
+	nodesUsage := NodesUsage(pods, reservations, r.getObjectKey(splitsPlacer.Name, splitsPlacer.Namespace))
+
+	existingPieces := utils.NewStringSet()
+	for _, pod := range pods {
+		if pod.Spec.NodeName != "" {
+			existingPieces.Add(getPieceKey(pod.Namespace, pod.Labels["split-owner"], pod.Labels["split"]))
+		}
+	}
+
+	splitsPlacerList := &oaiv1beta1.SplitsPlacerList{}
+	if err := r.List(context.Background(), splitsPlacerList); err != nil {
+		return nil, fmt.Errorf("error listing splits placers: %w", err)
+	}
+
+	for _, placer := range splitsPlacerList.Items {
+		if placer.UID == splitsPlacer.UID {
+			continue
+		}
+
+		placerKey := r.getObjectKey(placer.Name, placer.Namespace)
+		for _, ru := range placer.Spec.RUs {
+			if ru.CUNode == "" || ru.DUNode == "" {
+				continue
+			}
+			// the chains of the topology are accounted by the ledger
+			if _, reserved := reservations.Chains[GetChainKey(placerKey, ru.SplitName)]; reserved {
+				continue
+			}
+
+			for piece, nodeName := range getChainPieces(ru) {
+				if existingPieces.Has(getPieceKey(placer.Namespace, ru.SplitName, string(piece))) {
+					continue
+				}
+
+				usage, exists := nodesUsage[nodeName]
+				if !exists {
+					usage = &utils.RequestedResources{}
+					nodesUsage[nodeName] = usage
+				}
+				usage.AddRequested(getPieceResources(placer.Spec.Performance, piece))
+			}
+		}
+	}
+
+	return nodesUsage, nil
+}
+
+// getChainPieces returns the node of each piece of the chain
+func getChainPieces(ru *oaiv1beta1.ChainPosition) map[SplitPiece]string {
+	pieces := map[SplitPiece]string{CU: ru.CUNode, DU: ru.DUNode, RU: ru.RUNode}
+	if ru.CUUPNode != "" {
+		pieces[CUUP] = ru.CUUPNode
+	}
+
+	return pieces
+}
+
+func getPieceKey(namespace, splitName, piece string) string {
+	return fmt.Sprintf("%s/%s/%s", namespace, splitName, piece)
+}
+
 func (r *SplitsPlacerReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&oaiv1beta1.SplitsPlacer{}).
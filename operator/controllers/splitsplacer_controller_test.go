@@ -0,0 +1,371 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	oaiv1beta1 "github.com/juliorenner/oai-k8s/operator/api/v1beta1"
+	"github.com/juliorenner/oai-k8s/operator/controllers/utils"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	k8sScheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+var _ = Describe("splits placer controller unit tests", func() {
+	DescribeTable("getNodesUsage", func(resources []runtime.Object, chains map[string]*oaiv1beta1.ChainReservation,
+		expectedCPU map[string]string) {
+		splitsPlacer := getTestSplitsPlacer("placer", "testnamespace",
+			&oaiv1beta1.ChainPosition{SplitName: "split1", RUNode: "node3", CUNode: "node1", DUNode: "node2"})
+		fakeClient := getPlacerFakeClient(append(resources, splitsPlacer)...)
+		reconciler := &SplitsPlacerReconciler{Client: fakeClient}
+
+		reservations := &oaiv1beta1.TopologyReservations{Chains: chains}
+		nodesUsage, err := reconciler.getNodesUsage(splitsPlacer, reservations)
+		Expect(err).To(BeNil())
+		cpu := map[string]string{}
+		for nodeName, usage := range nodesUsage {
+			cpu[nodeName] = usage.CPU.String()
+		}
+		Expect(cpu).To(Equal(expectedCPU))
+	},
+		Entry("own split pods are placed again", []runtime.Object{
+			getPlacerPod("testnamespace", "split1", CU, "node1"),
+			getPlacerPod("testnamespace", "split1", DU, "node2"),
+		}, nil, map[string]string{}),
+		Entry("pods of splits with the same name in other namespaces", []runtime.Object{
+			getPlacerPod("othernamespace", "split1", CU, "node1"),
+		}, nil, map[string]string{"node1": "500m"}),
+		Entry("pieces of other splits placers without pods", []runtime.Object{
+			getTestSplitsPlacer("other", "othernamespace",
+				&oaiv1beta1.ChainPosition{SplitName: "split2", RUNode: "node3", CUNode: "node1", DUNode: "node2"}),
+			getPlacerPod("othernamespace", "split2", RU, "node3"),
+		}, nil, map[string]string{"node1": SplitCPURequestValue, "node2": SplitCPURequestValue, "node3": "500m"}),
+		Entry("ledger reservations instead of the pods of their chains", []runtime.Object{
+			getTestSplitsPlacer("other", "othernamespace",
+				&oaiv1beta1.ChainPosition{SplitName: "split2", RUNode: "node3", CUNode: "node1", DUNode: "node2"}),
+			getPlacerPod("othernamespace", "split2", RU, "node3"),
+			getPlacerPod("othernamespace", "split3", CU, "node1"),
+		}, map[string]*oaiv1beta1.ChainReservation{
+			"othernamespace/other/split2": {Core: "node0", Nodes: map[string]*oaiv1beta1.NodeReservation{
+				"node1": {CPU: *utils.NewQuantity("200m")},
+				"node3": {CPU: *utils.NewQuantity("200m")},
+			}},
+			"othernamespace/scheduled/split3": {Core: "node0", Nodes: map[string]*oaiv1beta1.NodeReservation{
+				"node1": {CPU: *utils.NewQuantity("200m")},
+				"node2": {CPU: *utils.NewQuantity("200m")},
+			}},
+			"testnamespace/placer/split1": {Core: "node0", Nodes: map[string]*oaiv1beta1.NodeReservation{
+				"node1": {CPU: *utils.NewQuantity("200m")},
+			}},
+		}, map[string]string{"node1": "400m", "node2": "200m", "node3": "200m"}),
+	)
+
+	It("counts the huge pages and exclusive CPUs reserved by other splits placers", func() {
+		performance := &oaiv1beta1.PerformanceProfiles{DU: &oaiv1beta1.PerformanceProfile{CPUs: 1,
+			HugePages2Mi: utils.NewQuantity("1Gi")}}
+		first := getTestSplitsPlacer("first", "testnamespace",
+			&oaiv1beta1.ChainPosition{SplitName: "split1", RUNode: "node4"})
+		second := getTestSplitsPlacer("second", "testnamespace",
+			&oaiv1beta1.ChainPosition{SplitName: "split2", RUNode: "node4"})
+		resources := getPlacementResources()
+		for _, splitsPlacer := range []*oaiv1beta1.SplitsPlacer{first, second} {
+			splitsPlacer.Spec.TopologyConfig = "topology"
+			splitsPlacer.Spec.Performance = performance
+			resources = append(resources, splitsPlacer)
+		}
+		// only node3 has the huge pages of one DU
+		for _, resource := range resources {
+			if node, ok := resource.(*v1.Node); ok && node.Name == "node3" {
+				node.Status.Allocatable[utils.ResourceHugePages2Mi] = *utils.NewQuantity("1Gi")
+			}
+		}
+		fakeClient := getPlacerFakeClient(resources...)
+		scheme := runtime.NewScheme()
+		Expect(oaiv1beta1.AddToScheme(scheme)).To(BeNil())
+		reconciler := &SplitsPlacerReconciler{Client: fakeClient, Log: zap.New(zap.UseDevMode(true)), Scheme: scheme,
+			Recorder: record.NewFakeRecorder(10)}
+
+		firstKey := types.NamespacedName{Namespace: "testnamespace", Name: "first"}
+		_, err := reconciler.Reconcile(ctrl.Request{NamespacedName: firstKey})
+		Expect(err).To(BeNil())
+		placed := &oaiv1beta1.SplitsPlacer{}
+		Expect(fakeClient.Get(context.Background(), firstKey, placed)).To(BeNil())
+		Expect(placed.Spec.RUs[0].DUNode).To(Equal("node3"))
+		reserved := readTestReservations(fakeClient).Chains["testnamespace/first/split1"].Nodes["node3"]
+		Expect(reserved.ExclusiveCPUs).To(BeEquivalentTo(1))
+		Expect(reserved.HugePages2Mi.String()).To(Equal("1Gi"))
+
+		// the split pods are not running yet, the ledger alone keeps the huge pages of node3 taken
+		secondKey := types.NamespacedName{Namespace: "testnamespace", Name: "second"}
+		_, err = reconciler.Reconcile(ctrl.Request{NamespacedName: secondKey})
+		Expect(err).To(BeNil())
+		unplaced := &oaiv1beta1.SplitsPlacer{}
+		Expect(fakeClient.Get(context.Background(), secondKey, unplaced)).To(BeNil())
+		Expect(unplaced.Spec.RUs[0].DUNode).To(BeEmpty())
+		Expect(unplaced.Status.AllocatedRUs).To(BeZero())
+		Expect(unplaced.Status.Explanations).NotTo(BeEmpty())
+		Expect(readTestReservations(fakeClient).Chains).NotTo(HaveKey("testnamespace/second/split2"))
+	})
+
+	It("plans a dry run without creating splits and applies the plan once it is disabled", func() {
+		splitsPlacer := getTestSplitsPlacer("placer", "testnamespace",
+			&oaiv1beta1.ChainPosition{SplitName: "split1", RUNode: "node4"})
+		splitsPlacer.Spec.TopologyConfig = "topology"
+		splitsPlacer.Spec.DryRun = true
+		fakeClient := getPlacerFakeClient(append(getPlacementResources(), splitsPlacer)...)
+		scheme := runtime.NewScheme()
+		Expect(oaiv1beta1.AddToScheme(scheme)).To(BeNil())
+		reconciler := &SplitsPlacerReconciler{Client: fakeClient, Log: zap.New(zap.UseDevMode(true)), Scheme: scheme,
+			Recorder: record.NewFakeRecorder(10)}
+		placerKey := types.NamespacedName{Namespace: "testnamespace", Name: "placer"}
+
+		_, err := reconciler.Reconcile(ctrl.Request{NamespacedName: placerKey})
+		Expect(err).To(BeNil())
+
+		planned := &oaiv1beta1.SplitsPlacer{}
+		Expect(fakeClient.Get(context.Background(), placerKey, planned)).To(BeNil())
+		Expect(planned.Status.State).To(BeEquivalentTo(oaiv1beta1.PlacerStatePlanned))
+		Expect(planned.Status.Plan.Positions).To(HaveLen(1))
+		Expect(planned.Status.Plan.Positions[0].DUNode).To(Equal("node3"))
+		Expect(planned.Spec.RUs[0].DUNode).To(BeEmpty())
+		Expect(planned.Finalizers).To(BeEmpty())
+		splitList := &oaiv1beta1.SplitList{}
+		Expect(fakeClient.List(context.Background(), splitList)).To(BeNil())
+		Expect(splitList.Items).To(BeEmpty())
+		Expect(readTestReservations(fakeClient).Chains).To(BeEmpty())
+
+		planned.Spec.DryRun = false
+		Expect(fakeClient.Update(context.Background(), planned)).To(BeNil())
+		_, err = reconciler.Reconcile(ctrl.Request{NamespacedName: placerKey})
+		Expect(err).To(BeNil())
+
+		applied := &oaiv1beta1.SplitsPlacer{}
+		Expect(fakeClient.Get(context.Background(), placerKey, applied)).To(BeNil())
+		Expect(applied.Status.State).To(BeEquivalentTo(oaiv1beta1.PlacerStateFinished))
+		Expect(applied.Status.Plan).To(BeNil())
+		Expect(applied.Spec.RUs[0].DUNode).To(Equal("node3"))
+		Expect(applied.Finalizers).To(ContainElement(reservationsFinalizer))
+		Expect(fakeClient.List(context.Background(), splitList)).To(BeNil())
+		Expect(splitList.Items).To(HaveLen(1))
+		Expect(splitList.Items[0].Spec.DUNode).To(Equal("node3"))
+		Expect(readTestReservations(fakeClient).Chains).To(HaveKey("testnamespace/placer/split1"))
+	})
+
+	It("plans again with the dry run enabled when the plan does not fit anymore", func() {
+		splitsPlacer := getTestSplitsPlacer("placer", "testnamespace",
+			&oaiv1beta1.ChainPosition{SplitName: "split1", RUNode: "node4"})
+		splitsPlacer.Spec.TopologyConfig = "topology"
+		splitsPlacer.Spec.DryRun = true
+		fakeClient := getPlacerFakeClient(append(getPlacementResources(), splitsPlacer)...)
+		scheme := runtime.NewScheme()
+		Expect(oaiv1beta1.AddToScheme(scheme)).To(BeNil())
+		recorder := record.NewFakeRecorder(10)
+		reconciler := &SplitsPlacerReconciler{Client: fakeClient, Log: zap.New(zap.UseDevMode(true)), Scheme: scheme,
+			Recorder: recorder}
+		placerKey := types.NamespacedName{Namespace: "testnamespace", Name: "placer"}
+
+		_, err := reconciler.Reconcile(ctrl.Request{NamespacedName: placerKey})
+		Expect(err).To(BeNil())
+		planned := &oaiv1beta1.SplitsPlacer{}
+		Expect(fakeClient.Get(context.Background(), placerKey, planned)).To(BeNil())
+		Expect(planned.Status.Plan.Positions[0].DUNode).To(Equal("node3"))
+
+		// a pod of another namespace takes the node of the planned DU before the plan is applied
+		pod := getPlacerPod("othernamespace", "split2", CU, "node3")
+		pod.Spec.Containers[0].Resources.Requests[v1.ResourceCPU] = *utils.NewQuantity("4")
+		Expect(fakeClient.Create(context.Background(), pod)).To(BeNil())
+		planned.Spec.DryRun = false
+		Expect(fakeClient.Update(context.Background(), planned)).To(BeNil())
+		_, err = reconciler.Reconcile(ctrl.Request{NamespacedName: placerKey})
+		Expect(err).To(BeNil())
+
+		replanned := &oaiv1beta1.SplitsPlacer{}
+		Expect(fakeClient.Get(context.Background(), placerKey, replanned)).To(BeNil())
+		Expect(replanned.Spec.DryRun).To(BeTrue())
+		Expect(replanned.Spec.RUs[0].DUNode).To(BeEmpty())
+		Expect(replanned.Status.State).To(BeEquivalentTo(oaiv1beta1.PlacerStatePlanned))
+		Expect(replanned.Status.Plan.Positions).To(BeEmpty())
+		Expect(replanned.Status.Plan.UnallocatedRUs).To(ConsistOf("split1"))
+		Expect(recorder.Events).To(Receive(ContainSubstring("Planned")))
+		Expect(recorder.Events).To(Receive(ContainSubstring("StalePlan")))
+		splitList := &oaiv1beta1.SplitList{}
+		Expect(fakeClient.List(context.Background(), splitList)).To(BeNil())
+		Expect(splitList.Items).To(BeEmpty())
+		Expect(readTestReservations(fakeClient).Chains).To(BeEmpty())
+	})
+
+	It("places the RUs again once the state is cleared", func() {
+		splitsPlacer := getTestSplitsPlacer("placer", "testnamespace",
+			&oaiv1beta1.ChainPosition{SplitName: "split1", RUNode: "node4"},
+			&oaiv1beta1.ChainPosition{SplitName: "split2", RUNode: "node4"})
+		splitsPlacer.Spec.TopologyConfig = "topology"
+		fakeClient := getPlacerFakeClient(append(getPlacementResources(), splitsPlacer)...)
+		scheme := runtime.NewScheme()
+		Expect(oaiv1beta1.AddToScheme(scheme)).To(BeNil())
+		reconciler := &SplitsPlacerReconciler{Client: fakeClient, Log: zap.New(zap.UseDevMode(true)), Scheme: scheme,
+			Recorder: record.NewFakeRecorder(10)}
+		placerKey := types.NamespacedName{Namespace: "testnamespace", Name: "placer"}
+
+		_, err := reconciler.Reconcile(ctrl.Request{NamespacedName: placerKey})
+		Expect(err).To(BeNil())
+		splitList := &oaiv1beta1.SplitList{}
+		Expect(fakeClient.List(context.Background(), splitList)).To(BeNil())
+		Expect(splitList.Items).To(HaveLen(2))
+
+		// the second RU is removed and the placement is requested again
+		placed := &oaiv1beta1.SplitsPlacer{}
+		Expect(fakeClient.Get(context.Background(), placerKey, placed)).To(BeNil())
+		Expect(placed.Status.State).To(BeEquivalentTo(oaiv1beta1.PlacerStateFinished))
+		placed.Spec.RUs = placed.Spec.RUs[:1]
+		Expect(fakeClient.Update(context.Background(), placed)).To(BeNil())
+		placed.Status.State = ""
+		Expect(fakeClient.Status().Update(context.Background(), placed)).To(BeNil())
+		_, err = reconciler.Reconcile(ctrl.Request{NamespacedName: placerKey})
+		Expect(err).To(BeNil())
+
+		Expect(fakeClient.List(context.Background(), splitList)).To(BeNil())
+		Expect(splitList.Items).To(HaveLen(1))
+		Expect(splitList.Items[0].Name).To(Equal("split1"))
+		Expect(splitList.Items[0].Spec.DUNode).To(Equal("node3"))
+		chains := readTestReservations(fakeClient).Chains
+		Expect(chains).To(HaveKey("testnamespace/placer/split1"))
+		Expect(chains).NotTo(HaveKey("testnamespace/placer/split2"))
+	})
+
+	It("moves the splits of the chains placed again and deletes the ones no longer allocated", func() {
+		scheme := runtime.NewScheme()
+		Expect(oaiv1beta1.AddToScheme(scheme)).To(BeNil())
+		splitsPlacer := getTestSplitsPlacer("placer", "testnamespace",
+			&oaiv1beta1.ChainPosition{SplitName: "moved", RUNode: "node4", CUNode: "node1", DUNode: "node3"},
+			&oaiv1beta1.ChainPosition{SplitName: "unallocated", RUNode: "node4"},
+			&oaiv1beta1.ChainPosition{SplitName: "new", RUNode: "node4", CUNode: "node1", DUNode: "node2"})
+		getOwnedSplit := func(name, cuNode, duNode string) *oaiv1beta1.Split {
+			split := &oaiv1beta1.Split{
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "testnamespace"},
+				Spec:       oaiv1beta1.SplitSpec{RUNode: "node4", CUNode: cuNode, DUNode: duNode},
+			}
+			Expect(ctrl.SetControllerReference(splitsPlacer, split, scheme)).To(BeNil())
+			return split
+		}
+		// the splits not created by the placer are left alone
+		unowned := &oaiv1beta1.Split{ObjectMeta: metav1.ObjectMeta{Name: "unowned", Namespace: "testnamespace"}}
+		fakeClient := getPlacerFakeClient(splitsPlacer, unowned,
+			getOwnedSplit("moved", "node1", "node2"),
+			getOwnedSplit("unallocated", "node1", "node2"),
+			getOwnedSplit("removed", "node1", "node2"))
+		reconciler := &SplitsPlacerReconciler{Client: fakeClient, Log: zap.New(zap.UseDevMode(true)), Scheme: scheme}
+
+		Expect(reconciler.syncSplits(splitsPlacer, reconciler.Log)).To(BeNil())
+
+		splitList := &oaiv1beta1.SplitList{}
+		Expect(fakeClient.List(context.Background(), splitList)).To(BeNil())
+		duNodes := map[string]string{}
+		for _, split := range splitList.Items {
+			duNodes[split.Name] = split.Spec.DUNode
+		}
+		Expect(duNodes).To(Equal(map[string]string{"moved": "node3", "new": "node2", "unowned": ""}))
+	})
+})
+
+// getPlacementResources returns the nodes, the topology and the disaggregations of a line from the core in node1 to
+// the RU in node4
+func getPlacementResources() []runtime.Object {
+	topology := &oaiv1beta1.Topology{
+		Nodes: map[string]*oaiv1beta1.Node{"node1": {Core: true}, "node2": {}, "node3": {}, "node4": {}},
+		Links: map[string]*oaiv1beta1.Link{},
+	}
+	resources := []runtime.Object{}
+	for i := 1; i <= 4; i++ {
+		nodeName := fmt.Sprintf("node%d", i)
+		allocatable := v1.ResourceList{
+			v1.ResourceCPU:    *utils.NewQuantity("4"),
+			v1.ResourceMemory: *utils.NewQuantity("8Gi"),
+		}
+		resources = append(resources, &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: nodeName},
+			Status:     v1.NodeStatus{Capacity: allocatable, Allocatable: allocatable},
+		})
+		if i > 1 {
+			previous := fmt.Sprintf("node%d", i-1)
+			topology.Links[previous+"--"+nodeName] = &oaiv1beta1.Link{LinkCapacity: 1000, LinkDelay: 0.5,
+				Source: oaiv1beta1.Connection{Node: previous}, Destination: oaiv1beta1.Connection{Node: nodeName}}
+		}
+	}
+
+	topologyData, err := json.Marshal(topology)
+	Expect(err).To(BeNil())
+	disaggregations, err := json.Marshal(map[string]*oaiv1beta1.Disaggregation{"1": {
+		Backhaul:  &oaiv1beta1.NetworkRequirements{Bandwidth: 100},
+		Midhaul:   &oaiv1beta1.NetworkRequirements{Latency: 30, Bandwidth: 100},
+		Fronthaul: &oaiv1beta1.NetworkRequirements{Latency: 2, Bandwidth: 150},
+	}})
+	Expect(err).To(BeNil())
+
+	return append(resources,
+		&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "topology", Namespace: "testnamespace"},
+			Data:       map[string]string{topologyKey: string(topologyData)},
+		},
+		&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: DisaggregationConfigMapName, Namespace: operatorNamespace},
+			Data:       map[string]string{DisaggregationKey: string(disaggregations)},
+		},
+	)
+}
+
+func readTestReservations(k8sClient client.Client) *oaiv1beta1.TopologyReservations {
+	cm, err := GetTopologyConfigMap(k8sClient, types.NamespacedName{Namespace: "testnamespace", Name: "topology"})
+	Expect(err).To(BeNil())
+	reservations, err := ReadTopologyReservations(cm)
+	Expect(err).To(BeNil())
+
+	return reservations
+}
+
+func getTestSplitsPlacer(name, namespace string, rus ...*oaiv1beta1.ChainPosition) *oaiv1beta1.SplitsPlacer {
+	return &oaiv1beta1.SplitsPlacer{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, UID: types.UID("uid-" + name)},
+		Spec:       oaiv1beta1.SplitsPlacerSpec{RUs: rus},
+	}
+}
+
+func getPlacerPod(namespace, splitName string, piece SplitPiece, nodeName string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      string(piece) + "-" + splitName,
+			Namespace: namespace,
+			Labels: map[string]string{
+				"split":       string(piece),
+				"split-owner": splitName,
+			},
+		},
+		Spec: v1.PodSpec{
+			NodeName: nodeName,
+			Containers: []v1.Container{{
+				Name:  "dummy",
+				Image: "dummy",
+				Resources: v1.ResourceRequirements{Requests: v1.ResourceList{
+					v1.ResourceCPU: *utils.NewQuantity("500m"),
+				}},
+			}},
+		},
+	}
+}
+
+func getPlacerFakeClient(resources ...runtime.Object) client.Client {
+	scheme := runtime.NewScheme()
+	Expect(k8sScheme.AddToScheme(scheme)).To(BeNil())
+	Expect(oaiv1beta1.AddToScheme(scheme)).To(BeNil())
+	return fake.NewFakeClientWithScheme(scheme, resources...)
+}
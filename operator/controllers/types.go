@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	oaiv1beta1 "github.com/juliorenner/oai-k8s/operator/api/v1beta1"
 	"github.com/juliorenner/oai-k8s/operator/controllers/utils"
 	v1 "k8s.io/api/core/v1"
 )
@@ -9,10 +10,16 @@ const (
 	CU SplitPiece = "cu"
 	DU SplitPiece = "du"
 	RU SplitPiece = "ru"
+	// CUUP is the CU user plane of NR splits, the CU piece runs the CU control plane
+	CUUP SplitPiece = "cuup"
 
 	CUTemplateConfigMapName     = "operator-cu-template"
 	DUTemplateConfigMapName     = "operator-du-template"
 	RUTemplateConfigMapName     = "operator-ru-template"
+	NRCUTemplateConfigMapName   = "operator-nr-cucp-template"
+	NRCUUPTemplateConfigMapName = "operator-nr-cuup-template"
+	NRDUTemplateConfigMapName   = "operator-nr-du-template"
+	NRRUTemplateConfigMapName   = "operator-nr-ru-template"
 	DisaggregationConfigMapName = "operator-disaggregations"
 
 	DisaggregationKey = "disaggregations"
@@ -22,10 +29,31 @@ const (
 	SplitCPULimitValue      = "500m"
 	SplitCPURequestValue    = "150m"
 
+	// TemplateHashAnnotation keeps the hash of the template of the piece in its pod template, so the pods are rolled
+	// when the template changes
+	TemplateHashAnnotation = "oai.unisinos/template-hash"
+	// ValuesChecksumAnnotation keeps the checksum of the values the piece depends on in its pod template, so the
+	// pods are rolled when those values change
+	ValuesChecksumAnnotation = "oai.unisinos/values-checksum"
+	// PodTemplateHashAnnotation keeps the hash of the whole pod template of the piece, so the pods are rolled when
+	// anything else in it changes, such as its node or its security profile
+	PodTemplateHashAnnotation = "oai.unisinos/pod-template-hash"
+
 	operatorNamespace          = "operator-system"
+	templateKey                = "template"
 	cuConfigMapContentTemplate = "upfaddress: %s\nlocaladdress: %s\nsouthaddress: %s\n"
 	duConfigMapContentTemplate = "northaddress: %s\nlocaladdress: %s\nsouthaddress: %s\n"
 	ruConfigMapContentTemplate = "northaddress: %s\nlocaladdress: %s\n"
+
+	nrCUConfigMapContentTemplate   = "amfaddress: %s\nlocaladdress: %s\nsouthaddress: %s\ncuupaddress: %s\n"
+	nrCUUPConfigMapContentTemplate = "upfaddress: %s\nlocaladdress: %s\nsouthaddress: %s\ncucpaddress: %s\n"
+
+	radioConfigMapContentTemplate = "radio:\n  cellid: %d\n  pci: %d\n  tac: %d\n  band: %d\n  bandwidth: %d\n" +
+		"  earfcn: %d\n  txgain: %d\n  plmns:\n"
+	plmnConfigMapContentTemplate = "  - mcc: \"%s\"\n    mnc: \"%s\"\n"
+
+	// maxLTEPCI is the highest physical cell ID of LTE cells
+	maxLTEPCI = 503
 )
 
 type SplitPiece string
@@ -36,6 +64,20 @@ type cuContent struct {
 	SouthAddress string
 }
 
+type nrCUContent struct {
+	AMF          string
+	LocalAddress string
+	SouthAddress string
+	CUUPAddress  string
+}
+
+type nrCUUPContent struct {
+	UPF          string
+	LocalAddress string
+	SouthAddress string
+	CUCPAddress  string
+}
+
 type duContent struct {
 	LocalAddress string
 	NorthAddress string
@@ -64,14 +106,97 @@ var SplitPorts = map[SplitPiece][]Port{
 		{50010, v1.ProtocolUDP}, {58363, v1.ProtocolUDP}},
 }
 
+// NRSplitPorts are the ports of the NR pieces: NGAP, E1 and F1-C for the CU-CP, N3, F1-U and E1 for the CU-UP. NGAP,
+// E1 and F1-C run over SCTP.
+var NRSplitPorts = map[SplitPiece][]Port{
+	CU:   {{38412, v1.ProtocolSCTP}, {38462, v1.ProtocolSCTP}, {38472, v1.ProtocolSCTP}},
+	CUUP: {{2152, v1.ProtocolUDP}, {2153, v1.ProtocolUDP}, {38462, v1.ProtocolSCTP}},
+	DU: {{2152, v1.ProtocolUDP}, {38472, v1.ProtocolSCTP}, {50001, v1.ProtocolUDP},
+		{50011, v1.ProtocolUDP}},
+	RU: {{50000, v1.ProtocolUDP}, {50010, v1.ProtocolUDP}},
+}
+
 var TemplateConfigMaps = map[SplitPiece]string{
 	CU: CUTemplateConfigMapName,
 	DU: DUTemplateConfigMapName,
 	RU: RUTemplateConfigMapName,
 }
 
+var NRTemplateConfigMaps = map[SplitPiece]string{
+	CU:   NRCUTemplateConfigMapName,
+	CUUP: NRCUUPTemplateConfigMapName,
+	DU:   NRDUTemplateConfigMapName,
+	RU:   NRRUTemplateConfigMapName,
+}
+
+// LTERadio is the cell of the LTE splits without radio, it is the cell the templates used to broadcast
+var LTERadio = oaiv1beta1.Radio{
+	PLMNs:     []oaiv1beta1.PLMN{{MCC: "208", MNC: "93"}},
+	CellID:    0xe00,
+	TAC:       1,
+	Band:      7,
+	Bandwidth: 25,
+	EARFCN:    3000,
+	TxGain:    90,
+}
+
+// NRRadio is the cell of the NR splits without radio
+var NRRadio = oaiv1beta1.Radio{
+	PLMNs:     []oaiv1beta1.PLMN{{MCC: "208", MNC: "93"}},
+	CellID:    0xe00,
+	TAC:       1,
+	Band:      78,
+	Bandwidth: 106,
+	EARFCN:    641280,
+}
+
+// splitPiecesOrder is the order the pieces of a chain are rolled, from the core to the RU
+var splitPiecesOrder = []SplitPiece{CU, CUUP, DU, RU}
+
+// checksumExcludedValues are the values not considered in the checksum of each piece: its own address and the
+// addresses of the pieces after it in the chain. They change whenever those pieces roll and the piece accepts the
+// association from the new address, rolling it again would restart the whole chain.
+var checksumExcludedValues = map[SplitPiece][]string{
+	CU:   {"localaddress", "southaddress", "cuupaddress"},
+	CUUP: {"localaddress", "southaddress"},
+	DU:   {"localaddress", "southaddress"},
+	RU:   {"localaddress"},
+}
+
 var Splits = utils.NewStringSet(
 	string(CU),
+	string(CUUP),
 	string(RU),
 	string(DU),
 )
+
+// GetTemplateConfigMaps returns the template config map of each piece deployed by the RAT. The pieces not in the map
+// are not deployed.
+func GetTemplateConfigMaps(rat oaiv1beta1.RAT) map[SplitPiece]string {
+	if rat == oaiv1beta1.RATNR {
+		return NRTemplateConfigMaps
+	}
+
+	return TemplateConfigMaps
+}
+
+func getSplitPorts(rat oaiv1beta1.RAT) map[SplitPiece][]Port {
+	if rat == oaiv1beta1.RATNR {
+		return NRSplitPorts
+	}
+
+	return SplitPorts
+}
+
+// getRadio returns the radio of the split or the default one of its RAT
+func getRadio(split *oaiv1beta1.Split) *oaiv1beta1.Radio {
+	if split.Spec.Radio != nil {
+		return split.Spec.Radio
+	}
+
+	if split.Spec.RAT == oaiv1beta1.RATNR {
+		return &NRRadio
+	}
+
+	return &LTERadio
+}
@@ -62,10 +62,21 @@ func ListNodes(k8sClient client.Client, nodeList *v1.NodeList) error {
 	return nil
 }
 
+// TODO: Use Informer/Cache
+func ListPods(k8sClient client.Client, podList *v1.PodList) error {
+	err := k8sClient.List(context.Background(), podList)
+	if err != nil {
+		return fmt.Errorf("error listing pods: %w", err)
+	}
+
+	return nil
+}
+
 func NodeListToMap(nodeList *v1.NodeList) map[string]*v1.Node {
 	nodeMap := make(map[string]*v1.Node)
-	for _, node := range nodeList.Items {
-		nodeMap[node.Name] = &node
+	for i := range nodeList.Items {
+		node := &nodeList.Items[i]
+		nodeMap[node.Name] = node
 	}
 
 	return nodeMap
@@ -5,6 +5,7 @@ import (
 
 	"github.com/go-logr/logr"
 	oaiv1beta1 "github.com/juliorenner/oai-k8s/operator/api/v1beta1"
+	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 )
 
@@ -38,14 +39,122 @@ func (s StringSet) Has(item string) bool {
 	return contained
 }
 
+// ContainsString returns true if the value is in the slice
+func ContainsString(slice []string, value string) bool {
+	for _, item := range slice {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveString returns a copy of the slice without the value
+func RemoveString(slice []string, value string) []string {
+	var result []string
+	for _, item := range slice {
+		if item != value {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
 func NewQuantity(value string) *resource.Quantity {
 	v := resource.MustParse(value)
 	return &v
 }
 
+const (
+	// ResourceHugePages1Gi and ResourceHugePages2Mi are the huge pages resources advertised by the nodes
+	ResourceHugePages1Gi = v1.ResourceName(v1.ResourceHugePagesPrefix + "1Gi")
+	ResourceHugePages2Mi = v1.ResourceName(v1.ResourceHugePagesPrefix + "2Mi")
+)
+
 type RequestedResources struct {
 	Memory resource.Quantity
-	CPU    resource.Quantity
+	// CPU is shared with the other pods of the node
+	CPU resource.Quantity
+	// ExclusiveCPUs are whole cores pinned to the piece, on top of the shared CPU
+	ExclusiveCPUs int64
+	HugePages1Gi  resource.Quantity
+	HugePages2Mi  resource.Quantity
+}
+
+// Add sums the given resources to the requested ones.
+func (r *RequestedResources) Add(memory, cpu resource.Quantity) {
+	r.Memory.Add(memory)
+	r.CPU.Add(cpu)
+}
+
+// AddRequested sums other requested resources, the exclusive CPUs are added to the CPU in use.
+func (r *RequestedResources) AddRequested(other *RequestedResources) {
+	r.Add(other.Memory, other.TotalCPU())
+	r.HugePages1Gi.Add(other.HugePages1Gi)
+	r.HugePages2Mi.Add(other.HugePages2Mi)
+}
+
+// TotalCPU returns the shared CPU plus the exclusive CPUs
+func (r *RequestedResources) TotalCPU() resource.Quantity {
+	cpu := r.CPU.DeepCopy()
+	cpu.Add(*resource.NewQuantity(r.ExclusiveCPUs, resource.DecimalSI))
+	return cpu
+}
+
+// PodsRequestedResources returns the resources requested by the pods bound to each node. The key is the node name.
+// Pods already finished do not consume resources and are ignored.
+func PodsRequestedResources(pods []v1.Pod) map[string]*RequestedResources {
+	nodesUsage := make(map[string]*RequestedResources)
+	for _, pod := range pods {
+		if pod.Spec.NodeName == "" || pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+			continue
+		}
+
+		usage, exists := nodesUsage[pod.Spec.NodeName]
+		if !exists {
+			usage = &RequestedResources{}
+			nodesUsage[pod.Spec.NodeName] = usage
+		}
+
+		memory, cpu := podRequests(&pod)
+		usage.Add(memory, cpu)
+		for _, container := range pod.Spec.Containers {
+			usage.HugePages1Gi.Add(quantity(container.Resources.Requests, ResourceHugePages1Gi))
+			usage.HugePages2Mi.Add(quantity(container.Resources.Requests, ResourceHugePages2Mi))
+		}
+	}
+
+	return nodesUsage
+}
+
+// podRequests follows the scheduler rule: the pod requests the sum of its containers or the biggest init container,
+// whichever is higher.
+func podRequests(pod *v1.Pod) (resource.Quantity, resource.Quantity) {
+	memory, cpu := resource.Quantity{}, resource.Quantity{}
+	for _, container := range pod.Spec.Containers {
+		memory.Add(*container.Resources.Requests.Memory())
+		cpu.Add(*container.Resources.Requests.Cpu())
+	}
+
+	for _, container := range pod.Spec.InitContainers {
+		if initMemory := container.Resources.Requests.Memory(); initMemory.Cmp(memory) > 0 {
+			memory = *initMemory
+		}
+		if initCPU := container.Resources.Requests.Cpu(); initCPU.Cmp(cpu) > 0 {
+			cpu = *initCPU
+		}
+	}
+
+	return memory, cpu
+}
+
+// quantity returns the quantity of the resource in the list, zero if it is not in the list
+func quantity(resources v1.ResourceList, name v1.ResourceName) resource.Quantity {
+	if value, exists := resources[name]; exists {
+		return value.DeepCopy()
+	}
+
+	return resource.Quantity{}
 }
 
 type Node struct {
@@ -53,34 +162,97 @@ type Node struct {
 	// key is the Node name
 	Links     map[string]*Link
 	Resources *Resources
+	// Labels and Taints of the K8S node, matched against the constraints of the pieces
+	Labels map[string]string
+	Taints []v1.Taint
+}
+
+// HasResources returns true if the node has the memory, the CPU and the huge pages requested. The exclusive CPUs are
+// whole cores, so they must fit in the cores left after the shared CPU.
+func (node *Node) HasResources(requested *RequestedResources) bool {
+	resources := node.Resources
+	if resources.MemoryAvailable.Value() <= requested.Memory.Value() {
+		return false
+	}
+
+	sharedCPU := resources.CPUAvailable.MilliValue() - requested.CPU.MilliValue()
+	if sharedCPU <= 0 || sharedCPU/1000 < requested.ExclusiveCPUs {
+		return false
+	}
+
+	return hasQuantity(resources.HugePages1GiAvailable, requested.HugePages1Gi) &&
+		hasQuantity(resources.HugePages2MiAvailable, requested.HugePages2Mi)
 }
 
-func (node *Node) HasResources(memory, cpu resource.Quantity) bool {
-	return node.Resources.MemoryAvailable.Value() > memory.Value() && node.Resources.CPUAvailable.Value() > cpu.
-		Value()
+func hasQuantity(available *resource.Quantity, requested resource.Quantity) bool {
+	if requested.IsZero() {
+		return true
+	}
+
+	return available != nil && available.Cmp(requested) >= 0
 }
 
-func (node *Node) AllocateResources(memory, cpu resource.Quantity, log logr.Logger) error {
-	node.Resources.MemoryAvailable.Sub(memory)
-	node.Resources.CPUAvailable.Sub(cpu)
+func (node *Node) AllocateResources(requested *RequestedResources, log logr.Logger) error {
+	node.Resources.MemoryAvailable.Sub(requested.Memory)
+	node.Resources.CPUAvailable.Sub(requested.TotalCPU())
+	subQuantity(node.Resources.HugePages1GiAvailable, requested.HugePages1Gi)
+	subQuantity(node.Resources.HugePages2MiAvailable, requested.HugePages2Mi)
 
-	if node.Resources.CPUAvailable.Value() < 0 ||
+	if node.Resources.CPUAvailable.MilliValue() < 0 ||
 		node.Resources.MemoryAvailable.Value() < 0 {
-		return fmt.Errorf("error allocating resources. CPU: %d, Memory: %d", node.Resources.CPUAvailable.Value(),
+		return fmt.Errorf("error allocating resources. CPU: %dm, Memory: %d", node.Resources.CPUAvailable.MilliValue(),
 			node.Resources.MemoryAvailable.Value())
 	}
 
-	log.Info("remaining resources", "cpu", node.Resources.CPUAvailable.Value(), "memory",
+	if !hasRemaining(node.Resources.HugePages1GiAvailable, requested.HugePages1Gi) ||
+		!hasRemaining(node.Resources.HugePages2MiAvailable, requested.HugePages2Mi) {
+		return fmt.Errorf("error allocating resources. Not enough huge pages in node '%s'", node.NodeName)
+	}
+
+	log.Info("remaining resources", "cpu", node.Resources.CPUAvailable.MilliValue(), "memory",
 		node.Resources.MemoryAvailable.Value())
 
 	return nil
 }
 
+// ReleaseResources gives back resources previously allocated in the node.
+func (node *Node) ReleaseResources(requested *RequestedResources) {
+	node.Resources.MemoryAvailable.Add(requested.Memory)
+	node.Resources.CPUAvailable.Add(requested.TotalCPU())
+	addQuantity(node.Resources.HugePages1GiAvailable, requested.HugePages1Gi)
+	addQuantity(node.Resources.HugePages2MiAvailable, requested.HugePages2Mi)
+}
+
+func subQuantity(available *resource.Quantity, value resource.Quantity) {
+	if available != nil {
+		available.Sub(value)
+	}
+}
+
+// hasRemaining returns true if the quantity left after an allocation is not negative. The nodes without the
+// resource only take the pieces not requesting it.
+func hasRemaining(available *resource.Quantity, requested resource.Quantity) bool {
+	if available == nil {
+		return requested.IsZero()
+	}
+
+	return available.Sign() >= 0
+}
+
+func addQuantity(available *resource.Quantity, value resource.Quantity) {
+	if available != nil {
+		available.Add(value)
+	}
+}
+
 type Resources struct {
 	Memory          *resource.Quantity
 	MemoryAvailable *resource.Quantity
 	CPU             *resource.Quantity
 	CPUAvailable    *resource.Quantity
+	// HugePages1GiAvailable and HugePages2MiAvailable are nil in the nodes without huge pages
+	HugePages1GiAvailable *resource.Quantity
+	HugePages2MiAvailable *resource.Quantity
 }
 
 type Link struct {
@@ -100,3 +272,8 @@ func (v *Link) AllocateResources(requiredBandwidth float32) error {
 	}
 	return nil
 }
+
+// ReleaseResources gives back bandwidth previously allocated in the link.
+func (v *Link) ReleaseResources(requiredBandwidth float32) {
+	v.AvailableBandwidth += requiredBandwidth
+}
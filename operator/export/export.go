@@ -0,0 +1,332 @@
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	oaiv1beta1 "github.com/juliorenner/oai-k8s/operator/api/v1beta1"
+)
+
+// Format is the graph description language of the export
+type Format string
+
+const (
+	FormatDOT     Format = "dot"
+	FormatGraphML Format = "graphml"
+)
+
+// Roles of the nodes, in the order they are drawn when a node has more than one
+const (
+	RoleCore = "core"
+	RoleCU   = "cu"
+	RoleCUUP = "cuup"
+	RoleDU   = "du"
+	RoleRU   = "ru"
+)
+
+var (
+	roles = []string{RoleCore, RoleCU, RoleCUUP, RoleDU, RoleRU}
+
+	roleColours = map[string]string{
+		RoleCore: "#9e9e9e",
+		RoleCU:   "#1f77b4",
+		RoleCUUP: "#17becf",
+		RoleDU:   "#2ca02c",
+		RoleRU:   "#ff7f0e",
+	}
+)
+
+// Graph is a topology with the chains placed over it
+type Graph struct {
+	Topology *oaiv1beta1.Topology
+	// Chains are drawn over the topology, the ones not placed are ignored
+	Chains []*oaiv1beta1.ChainPosition
+	// Reserved key is the link name and the value the bandwidth reserved in it. The residual bandwidth is not
+	// annotated if it is nil.
+	Reserved map[string]float32
+}
+
+// Write exports the graph in the format given
+func Write(w io.Writer, format Format, graph *Graph) error {
+	switch format {
+	case FormatDOT:
+		return WriteDOT(w, graph)
+	case FormatGraphML:
+		return WriteGraphML(w, graph)
+	default:
+		return fmt.Errorf("invalid format '%s'", format)
+	}
+}
+
+// WriteDOT exports the graph as an undirected Graphviz graph. The nodes are filled with the colours of their roles
+// and the links used by the chains are drawn bold.
+func WriteDOT(w io.Writer, graph *Graph) error {
+	nodeRoles := getNodeRoles(graph)
+	chainLinks := getChainLinks(graph)
+
+	var b strings.Builder
+	b.WriteString("graph topology {\n")
+	b.WriteString("  node [style=filled, fillcolor=\"#ffffff\", fontname=\"Helvetica\"];\n")
+	b.WriteString("  edge [fontname=\"Helvetica\", fontsize=10];\n")
+
+	for _, nodeName := range nodeNames(graph.Topology) {
+		label := nodeName
+		attributes := ""
+		if roles := nodeRoles[nodeName]; len(roles) > 0 {
+			label += `\n` + strings.ToUpper(strings.Join(roles, ", "))
+			attributes = fmt.Sprintf(", fillcolor=%q", colours(roles))
+			if len(roles) > 1 {
+				attributes += ", style=wedged"
+			}
+		}
+		fmt.Fprintf(&b, "  %q [label=%s%s];\n", nodeName, dotLabel(label), attributes)
+	}
+
+	for _, linkName := range linkNames(graph.Topology) {
+		link := graph.Topology.Links[linkName]
+		label := fmt.Sprintf(`%s\ncapacity %s`, linkName, formatFloat(link.LinkCapacity))
+		if residual, exists := getResidual(graph, linkName); exists {
+			label += `\nresidual ` + formatFloat(residual)
+		}
+		label += fmt.Sprintf(`\n%s ms`, formatFloat(link.LinkDelay))
+
+		attributes := ""
+		if chains := chainLinks[linkName]; chains > 0 {
+			attributes = fmt.Sprintf(", penwidth=%d", 1+2*chains)
+		}
+		fmt.Fprintf(&b, "  %q -- %q [label=%s%s];\n", link.Source.Node, link.Destination.Node, dotLabel(label),
+			attributes)
+	}
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID      string `xml:"id,attr"`
+	For     string `xml:"for,attr"`
+	Name    string `xml:"attr.name,attr"`
+	Type    string `xml:"attr.type,attr"`
+	Default string `xml:"default,omitempty"`
+}
+
+type graphMLGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphMLNode `xml:"node"`
+	Edges       []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	ID     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// WriteGraphML exports the graph as GraphML, with the roles and colour of each node and the capacity, residual
+// bandwidth, latency and chains of each link as data
+func WriteGraphML(w io.Writer, graph *Graph) error {
+	nodeRoles := getNodeRoles(graph)
+	chainLinks := getChainLinks(graph)
+
+	doc := &graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "roles", For: "node", Name: "roles", Type: "string"},
+			{ID: "colour", For: "node", Name: "colour", Type: "string"},
+			{ID: "name", For: "edge", Name: "name", Type: "string"},
+			{ID: "capacity", For: "edge", Name: "capacity", Type: "double"},
+			{ID: "residual", For: "edge", Name: "residual", Type: "double"},
+			{ID: "latency", For: "edge", Name: "latency", Type: "double"},
+			{ID: "chains", For: "edge", Name: "chains", Type: "int", Default: "0"},
+		},
+		Graph: graphMLGraph{ID: "topology", EdgeDefault: "undirected"},
+	}
+
+	for _, nodeName := range nodeNames(graph.Topology) {
+		node := graphMLNode{ID: nodeName}
+		if roles := nodeRoles[nodeName]; len(roles) > 0 {
+			node.Data = []graphMLData{{Key: "roles", Value: strings.Join(roles, ",")},
+				{Key: "colour", Value: roleColours[roles[0]]}}
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, node)
+	}
+
+	for _, linkName := range linkNames(graph.Topology) {
+		link := graph.Topology.Links[linkName]
+		edge := graphMLEdge{ID: linkName, Source: link.Source.Node, Target: link.Destination.Node,
+			Data: []graphMLData{
+				{Key: "name", Value: linkName},
+				{Key: "capacity", Value: formatFloat(link.LinkCapacity)},
+				{Key: "latency", Value: formatFloat(link.LinkDelay)},
+			}}
+		if residual, exists := getResidual(graph, linkName); exists {
+			edge.Data = append(edge.Data, graphMLData{Key: "residual", Value: formatFloat(residual)})
+		}
+		if chains := chainLinks[linkName]; chains > 0 {
+			edge.Data = append(edge.Data, graphMLData{Key: "chains", Value: strconv.Itoa(chains)})
+		}
+		doc.Graph.Edges = append(doc.Graph.Edges, edge)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("error encoding graphml: %w", err)
+	}
+	_, err := io.WriteString(w, "\n")
+
+	return err
+}
+
+// ReservedBandwidth sums the bandwidth every chain of the ledger reserves in each link. The key is the link name.
+func ReservedBandwidth(reservations *oaiv1beta1.TopologyReservations) map[string]float32 {
+	reserved := make(map[string]float32)
+	for _, chain := range reservations.Chains {
+		for linkName, bandwidth := range chain.Links {
+			reserved[linkName] += bandwidth
+		}
+	}
+
+	return reserved
+}
+
+// getNodeRoles returns the roles of each node, sorted as roles. The key is the node name.
+func getNodeRoles(graph *Graph) map[string][]string {
+	nodeRoles := make(map[string]map[string]bool)
+	add := func(nodeName, role string) {
+		if nodeName == "" {
+			return
+		}
+		if _, exists := nodeRoles[nodeName]; !exists {
+			nodeRoles[nodeName] = make(map[string]bool)
+		}
+		nodeRoles[nodeName][role] = true
+	}
+
+	for nodeName, node := range graph.Topology.Nodes {
+		if node.Core {
+			add(nodeName, RoleCore)
+		}
+	}
+	for _, chain := range graph.Chains {
+		add(chain.RUNode, RoleRU)
+		if !placed(chain) {
+			continue
+		}
+		add(chain.CUNode, RoleCU)
+		add(chain.CUUPNode, RoleCUUP)
+		add(chain.DUNode, RoleDU)
+	}
+
+	sortedRoles := make(map[string][]string)
+	for nodeName, nodeRole := range nodeRoles {
+		for _, role := range roles {
+			if nodeRole[role] {
+				sortedRoles[nodeName] = append(sortedRoles[nodeName], role)
+			}
+		}
+	}
+
+	return sortedRoles
+}
+
+// getChainLinks counts the chains whose path goes through each link. The key is the link name.
+func getChainLinks(graph *Graph) map[string]int {
+	linkNames := make(map[string]string)
+	for linkName, link := range graph.Topology.Links {
+		linkNames[link.Source.Node+"/"+link.Destination.Node] = linkName
+		linkNames[link.Destination.Node+"/"+link.Source.Node] = linkName
+	}
+
+	chainLinks := make(map[string]int)
+	for _, chain := range graph.Chains {
+		if !placed(chain) {
+			continue
+		}
+		for i := 0; i+1 < len(chain.Path); i++ {
+			if linkName, exists := linkNames[chain.Path[i]+"/"+chain.Path[i+1]]; exists {
+				chainLinks[linkName]++
+			}
+		}
+	}
+
+	return chainLinks
+}
+
+func getResidual(graph *Graph, linkName string) (float32, bool) {
+	if graph.Reserved == nil {
+		return 0, false
+	}
+
+	return graph.Topology.Links[linkName].LinkCapacity - graph.Reserved[linkName], true
+}
+
+func placed(chain *oaiv1beta1.ChainPosition) bool {
+	return chain.CUNode != "" && chain.DUNode != "" && len(chain.Path) > 0
+}
+
+// colours returns the Graphviz colour list of the roles
+func colours(nodeRoles []string) string {
+	list := make([]string, len(nodeRoles))
+	for i, role := range nodeRoles {
+		list[i] = roleColours[role]
+	}
+
+	return strings.Join(list, ":")
+}
+
+func formatFloat(value float32) string {
+	return strconv.FormatFloat(float64(value), 'f', -1, 32)
+}
+
+// dotLabel quotes the label keeping its line breaks, written as \n
+func dotLabel(label string) string {
+	return `"` + strings.ReplaceAll(label, `"`, `\"`) + `"`
+}
+
+func nodeNames(topology *oaiv1beta1.Topology) []string {
+	var names []string
+	for name := range topology.Nodes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func linkNames(topology *oaiv1beta1.Topology) []string {
+	var names []string
+	for name := range topology.Links {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,127 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	oaiv1beta1 "github.com/juliorenner/oai-k8s/operator/api/v1beta1"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestWriteDOT(t *testing.T) {
+	RegisterTestingT(t)
+
+	graph := generateGraph()
+	graph.Reserved = map[string]float32{"node1--node2": 300, "node2--node3": 152}
+
+	out := &bytes.Buffer{}
+	Expect(Write(out, FormatDOT, graph)).To(Succeed())
+	Expect(out.String()).To(ContainSubstring(`"node1" [label="node1\nCORE, CU", fillcolor="#9e9e9e:#1f77b4", style=wedged];`))
+	Expect(out.String()).To(ContainSubstring(`"node3" [label="node3\nRU", fillcolor="#ff7f0e"];`))
+	Expect(out.String()).To(ContainSubstring(`"node4" [label="node4"];`))
+	Expect(out.String()).To(ContainSubstring(`"node1" -- "node2" [label="node1--node2\ncapacity 1200\nresidual 900\n0.5 ms", penwidth=3];`))
+	Expect(out.String()).To(ContainSubstring(`"node3" -- "node4" [label="node3--node4\ncapacity 300\nresidual 300\n1 ms"];`))
+}
+
+func TestWriteGraphML(t *testing.T) {
+	RegisterTestingT(t)
+
+	out := &bytes.Buffer{}
+	Expect(Write(out, FormatGraphML, generateGraph())).To(Succeed())
+
+	doc := &graphML{}
+	Expect(xml.Unmarshal(out.Bytes(), doc)).To(Succeed())
+	Expect(doc.Keys).To(HaveLen(7))
+	Expect(doc.Graph.Nodes).To(HaveLen(4))
+	Expect(doc.Graph.Nodes[0].Data).To(ConsistOf(graphMLData{Key: "roles", Value: "core,cu"},
+		graphMLData{Key: "colour", Value: "#9e9e9e"}))
+	Expect(doc.Graph.Edges).To(HaveLen(3))
+	Expect(doc.Graph.Edges[0].Data).To(ContainElement(graphMLData{Key: "chains", Value: "1"}))
+	Expect(doc.Graph.Edges[0].Data).To(HaveLen(4))
+}
+
+func TestWriteInvalidFormat(t *testing.T) {
+	RegisterTestingT(t)
+
+	Expect(Write(&bytes.Buffer{}, "svg", generateGraph())).To(MatchError("invalid format 'svg'"))
+}
+
+func TestServer(t *testing.T) {
+	RegisterTestingT(t)
+
+	scheme := runtime.NewScheme()
+	Expect(oaiv1beta1.AddToScheme(scheme)).To(Succeed())
+	Expect(v1.AddToScheme(scheme)).To(Succeed())
+
+	graph := generateGraph()
+	topology, err := json.Marshal(graph.Topology)
+	Expect(err).NotTo(HaveOccurred())
+	cm := &v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "oai", Name: "topology"},
+		Data:       map[string]string{"topology": string(topology)},
+	}
+	splitsPlacer := &oaiv1beta1.SplitsPlacer{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "oai", Name: "placer"},
+		Spec:       oaiv1beta1.SplitsPlacerSpec{TopologyConfig: "topology", RUs: graph.Chains},
+	}
+	other := &oaiv1beta1.SplitsPlacer{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "oai", Name: "other"},
+		Spec: oaiv1beta1.SplitsPlacerSpec{TopologyConfig: "other",
+			RUs: []*oaiv1beta1.ChainPosition{{SplitName: "split2", RUNode: "node4"}}},
+	}
+	server := &Server{Client: fake.NewFakeClientWithScheme(scheme, cm, splitsPlacer, other), Log: ctrllog.Log}
+
+	recorder := httptest.NewRecorder()
+	server.handle(recorder, httptest.NewRequest(http.MethodGet, "/topology/oai/topology", nil))
+	Expect(recorder.Code).To(Equal(http.StatusOK))
+	Expect(recorder.Header().Get("Content-Type")).To(Equal("text/vnd.graphviz"))
+	Expect(recorder.Body.String()).To(ContainSubstring(`"node3" [label="node3\nRU", fillcolor="#ff7f0e"];`))
+	Expect(recorder.Body.String()).To(ContainSubstring(`"node4" [label="node4"];`))
+	Expect(recorder.Body.String()).To(ContainSubstring(`residual 1200`))
+
+	recorder = httptest.NewRecorder()
+	server.handle(recorder, httptest.NewRequest(http.MethodGet, "/topology/oai/topology?format=svg", nil))
+	Expect(recorder.Code).To(Equal(http.StatusBadRequest))
+
+	recorder = httptest.NewRecorder()
+	server.handle(recorder, httptest.NewRequest(http.MethodGet, "/topology/oai", nil))
+	Expect(recorder.Code).To(Equal(http.StatusNotFound))
+
+	recorder = httptest.NewRecorder()
+	server.handle(recorder, httptest.NewRequest(http.MethodGet, "/topology/oai/missing", nil))
+	Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+}
+
+func generateGraph() *Graph {
+	return &Graph{
+		Topology: &oaiv1beta1.Topology{
+			Nodes: map[string]*oaiv1beta1.Node{
+				"node1": {Core: true},
+				"node2": {},
+				"node3": {},
+				"node4": {},
+			},
+			Links: map[string]*oaiv1beta1.Link{
+				"node1--node2": {LinkCapacity: 1200, LinkDelay: 0.5, Source: oaiv1beta1.Connection{Node: "node1"},
+					Destination: oaiv1beta1.Connection{Node: "node2"}},
+				"node2--node3": {LinkCapacity: 300, LinkDelay: 0.25, Source: oaiv1beta1.Connection{Node: "node2"},
+					Destination: oaiv1beta1.Connection{Node: "node3"}},
+				"node3--node4": {LinkCapacity: 300, LinkDelay: 1, Source: oaiv1beta1.Connection{Node: "node3"},
+					Destination: oaiv1beta1.Connection{Node: "node4"}},
+			},
+		},
+		Chains: []*oaiv1beta1.ChainPosition{
+			{SplitName: "split1", RUNode: "node3", CoreNode: "node1", CUNode: "node1", DUNode: "node2",
+				Path: []string{"node1", "node2", "node3"}},
+		},
+	}
+}
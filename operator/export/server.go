@@ -0,0 +1,131 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-logr/logr"
+	oaiv1beta1 "github.com/juliorenner/oai-k8s/operator/api/v1beta1"
+	"github.com/juliorenner/oai-k8s/operator/controllers"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// pathPrefix is followed by <namespace>/<topology config map>
+const pathPrefix = "/topology/"
+
+// Server serves the topologies with the chains of their splits placers. The format query parameter selects dot or
+// graphml, dot by default, and splitsplacer restricts the chains to the ones of a splits placer.
+type Server struct {
+	Client client.Client
+	Addr   string
+	Log    logr.Logger
+}
+
+// Start implements the manager Runnable, serving until the stop channel is closed
+func (s *Server) Start(stop <-chan struct{}) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc(pathPrefix, s.handle)
+	server := &http.Server{Addr: s.Addr, Handler: mux}
+
+	errChan := make(chan error, 1)
+	go func() {
+		s.Log.Info("serving topology exports", "addr", s.Addr)
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			errChan <- err
+		}
+	}()
+
+	select {
+	case <-stop:
+		return server.Shutdown(context.Background())
+	case err := <-errChan:
+		return fmt.Errorf("error serving topology exports: %w", err)
+	}
+}
+
+// NeedLeaderElection allows every replica of the operator to serve the exports
+func (s *Server) NeedLeaderElection() bool {
+	return false
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(r.URL.Path, pathPrefix), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		http.Error(w, "path must be "+pathPrefix+"<namespace>/<topology>", http.StatusNotFound)
+		return
+	}
+
+	format := Format(r.URL.Query().Get("format"))
+	if format == "" {
+		format = FormatDOT
+	}
+
+	graph, err := s.getGraph(types.NamespacedName{Namespace: parts[0], Name: parts[1]},
+		r.URL.Query().Get("splitsplacer"))
+	if err != nil {
+		s.Log.Error(err, "error exporting topology", "path", r.URL.Path)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	switch format {
+	case FormatDOT:
+		w.Header().Set("Content-Type", "text/vnd.graphviz")
+	case FormatGraphML:
+		w.Header().Set("Content-Type", "application/xml")
+	default:
+		http.Error(w, fmt.Sprintf("invalid format '%s'", format), http.StatusBadRequest)
+		return
+	}
+
+	if err := Write(w, format, graph); err != nil {
+		s.Log.Error(err, "error writing topology export", "path", r.URL.Path)
+	}
+}
+
+// getGraph reads the topology, the bandwidth reserved in its ledger and the chains of the splits placers using it
+func (s *Server) getGraph(topologyKey types.NamespacedName, splitsPlacerName string) (*Graph, error) {
+	cm, err := controllers.GetTopologyConfigMap(s.Client, topologyKey)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := &Graph{Topology: &oaiv1beta1.Topology{}}
+	if err := controllers.ReadTopology(s.Client, topologyKey, graph.Topology); err != nil {
+		return nil, err
+	}
+
+	reservations, err := controllers.ReadTopologyReservations(cm)
+	if err != nil {
+		return nil, err
+	}
+	graph.Reserved = ReservedBandwidth(reservations)
+
+	splitsPlacers := &oaiv1beta1.SplitsPlacerList{}
+	if err := s.Client.List(context.Background(), splitsPlacers, client.InNamespace(topologyKey.Namespace)); err != nil {
+		return nil, fmt.Errorf("error listing splits placers: %w", err)
+	}
+
+	for i := range splitsPlacers.Items {
+		splitsPlacer := &splitsPlacers.Items[i]
+		if splitsPlacer.Spec.TopologyConfig != topologyKey.Name ||
+			(splitsPlacerName != "" && splitsPlacer.Name != splitsPlacerName) {
+			continue
+		}
+		graph.Chains = append(graph.Chains, Chains(splitsPlacer)...)
+	}
+
+	return graph, nil
+}
+
+// Chains returns the chains of the splits placer, the planned ones if it only planned the placement
+func Chains(splitsPlacer *oaiv1beta1.SplitsPlacer) []*oaiv1beta1.ChainPosition {
+	if splitsPlacer.Spec.DryRun && splitsPlacer.Status.Plan != nil {
+		return splitsPlacer.Status.Plan.Positions
+	}
+
+	return splitsPlacer.Spec.RUs
+}
@@ -0,0 +1,73 @@
+package generator
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+const (
+	Constant DistributionType = "constant"
+	Uniform  DistributionType = "uniform"
+	Normal   DistributionType = "normal"
+)
+
+type DistributionType string
+
+// Distribution defines how the values of link capacities and delays are drawn. Constant uses A as the value, Uniform
+// draws between A and B and Normal uses A as the mean and B as the standard deviation.
+type Distribution struct {
+	Type DistributionType `json:"type,omitempty"`
+	A    float64          `json:"a,omitempty"`
+	B    float64          `json:"b,omitempty"`
+}
+
+// ParseDistribution parses distributions written as <type>:<a>[:<b>], e.g. constant:300, uniform:300:1200 or
+// normal:2:0.5.
+func ParseDistribution(value string) (*Distribution, error) {
+	fields := strings.Split(value, ":")
+	distribution := &Distribution{Type: DistributionType(fields[0])}
+
+	expectedFields := 3
+	if distribution.Type == Constant {
+		expectedFields = 2
+	} else if distribution.Type != Uniform && distribution.Type != Normal {
+		return nil, fmt.Errorf("invalid distribution type '%s'", fields[0])
+	}
+
+	if len(fields) != expectedFields {
+		return nil, fmt.Errorf("invalid distribution '%s'", value)
+	}
+
+	var err error
+	if distribution.A, err = strconv.ParseFloat(fields[1], 64); err != nil {
+		return nil, fmt.Errorf("invalid distribution '%s': %w", value, err)
+	}
+	if expectedFields == 3 {
+		if distribution.B, err = strconv.ParseFloat(fields[2], 64); err != nil {
+			return nil, fmt.Errorf("invalid distribution '%s': %w", value, err)
+		}
+	}
+
+	return distribution, nil
+}
+
+// Draw returns one value of the distribution. Negative values are truncated to zero.
+func (d *Distribution) Draw(r *rand.Rand) float32 {
+	var value float64
+	switch d.Type {
+	case Uniform:
+		value = d.A + r.Float64()*(d.B-d.A)
+	case Normal:
+		value = r.NormFloat64()*d.B + d.A
+	default:
+		value = d.A
+	}
+
+	if value < 0 {
+		return 0
+	}
+
+	return float32(value)
+}
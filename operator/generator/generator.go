@@ -0,0 +1,250 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"sort"
+
+	oaiv1beta1 "github.com/juliorenner/oai-k8s/operator/api/v1beta1"
+)
+
+const (
+	Tree    Shape = "tree"
+	Ring    Shape = "ring"
+	Mesh    Shape = "mesh"
+	FatTree Shape = "fat-tree"
+
+	// minChainHops is the distance from the core required to place the CU, DU and RU of one chain in different nodes
+	minChainHops = 3
+
+	defaultNodePrefix = "node"
+)
+
+type Shape string
+
+// Options defines the topology to be generated
+type Options struct {
+	Shape Shape
+	// Depth is the number of levels below the core in tree and fat-tree shapes
+	Depth int
+	// FanOut is the number of children of each node in tree and fat-tree shapes
+	FanOut int
+	// Nodes is the number of nodes, including the core, in ring and mesh shapes
+	Nodes int
+	// MeshDegree is the probability of linking each pair of nodes in the mesh shape
+	MeshDegree float64
+	Capacity   *Distribution
+	Delay      *Distribution
+	Seed       int64
+	// NodePrefix is prepended to the index of each node to build its name, "node" if not set
+	NodePrefix string
+}
+
+type builder struct {
+	options    *Options
+	nodePrefix string
+	rand       *rand.Rand
+	topology   *oaiv1beta1.Topology
+	count      int
+}
+
+// Generate creates a topology with the given shape. The same options and seed always generate the same topology.
+func Generate(options *Options) (*oaiv1beta1.Topology, error) {
+	if options.Capacity == nil || options.Delay == nil {
+		return nil, errors.New("capacity and delay distributions are required")
+	}
+
+	b := &builder{
+		options:    options,
+		nodePrefix: options.NodePrefix,
+		rand:       rand.New(rand.NewSource(options.Seed)),
+		topology: &oaiv1beta1.Topology{
+			Nodes: make(map[string]*oaiv1beta1.Node),
+			Links: make(map[string]*oaiv1beta1.Link),
+		},
+	}
+
+	if b.nodePrefix == "" {
+		b.nodePrefix = defaultNodePrefix
+	}
+
+	var err error
+	switch options.Shape {
+	case Tree:
+		err = b.tree(false)
+	case FatTree:
+		err = b.tree(true)
+	case Ring:
+		err = b.ring()
+	case Mesh:
+		err = b.mesh()
+	default:
+		err = fmt.Errorf("invalid shape '%s'", options.Shape)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	b.setHops()
+
+	return b.topology, nil
+}
+
+// EdgeNodes returns the nodes far enough from the core to host the RU of a chain, sorted by name.
+func EdgeNodes(topology *oaiv1beta1.Topology) []string {
+	var nodes []string
+	for nodeName, node := range topology.Nodes {
+		if !node.Core && node.Hops >= minChainHops {
+			nodes = append(nodes, nodeName)
+		}
+	}
+
+	sort.Strings(nodes)
+
+	return nodes
+}
+
+// tree creates a core with FanOut children, each of them with FanOut children until Depth is reached. In the fat-tree
+// each node is also linked to the neighbour of its parent and the link capacity is multiplied by FanOut at each level
+// towards the core.
+func (b *builder) tree(fat bool) error {
+	if b.options.Depth < 1 || b.options.FanOut < 1 {
+		return errors.New("depth and fan-out must be greater than zero")
+	}
+
+	previousLevel := []string{b.addNode(true)}
+	for level := 1; level <= b.options.Depth; level++ {
+		var capacityFactor float32 = 1
+		if fat {
+			for i := level; i < b.options.Depth; i++ {
+				capacityFactor *= float32(b.options.FanOut)
+			}
+		}
+
+		var currentLevel []string
+		for i, parent := range previousLevel {
+			for j := 0; j < b.options.FanOut; j++ {
+				node := b.addNode(false)
+				b.addLink(node, parent, capacityFactor)
+				if fat && len(previousLevel) > 1 {
+					b.addLink(node, previousLevel[(i+1)%len(previousLevel)], capacityFactor)
+				}
+				currentLevel = append(currentLevel, node)
+			}
+		}
+		previousLevel = currentLevel
+	}
+
+	return nil
+}
+
+// ring creates Nodes nodes, the core included, linked in a cycle
+func (b *builder) ring() error {
+	if b.options.Nodes < 3 {
+		return errors.New("ring requires at least 3 nodes")
+	}
+
+	core := b.addNode(true)
+	previous := core
+	for i := 1; i < b.options.Nodes; i++ {
+		node := b.addNode(false)
+		b.addLink(node, previous, 1)
+		previous = node
+	}
+	b.addLink(previous, core, 1)
+
+	return nil
+}
+
+// mesh creates Nodes nodes connected by a random spanning tree, then links each remaining pair of nodes with
+// MeshDegree probability
+func (b *builder) mesh() error {
+	if b.options.Nodes < 2 {
+		return errors.New("mesh requires at least 2 nodes")
+	}
+
+	nodes := []string{b.addNode(true)}
+	for i := 1; i < b.options.Nodes; i++ {
+		node := b.addNode(false)
+		b.addLink(node, nodes[b.rand.Intn(len(nodes))], 1)
+		nodes = append(nodes, node)
+	}
+
+	for i := range nodes {
+		for j := i + 1; j < len(nodes); j++ {
+			if b.linked(nodes[i], nodes[j]) {
+				continue
+			}
+			if b.rand.Float64() < b.options.MeshDegree {
+				b.addLink(nodes[j], nodes[i], 1)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (b *builder) addNode(core bool) string {
+	b.count++
+	name := fmt.Sprintf("%s%d", b.nodePrefix, b.count)
+	b.topology.Nodes[name] = &oaiv1beta1.Node{Core: core}
+
+	return name
+}
+
+// addLink links the source to the destination, the source is expected to be the one farther from the core
+func (b *builder) addLink(source, destination string, capacityFactor float32) {
+	b.topology.Links[fmt.Sprintf("%s--%s", source, destination)] = &oaiv1beta1.Link{
+		LinkCapacity: b.options.Capacity.Draw(b.rand) * capacityFactor,
+		LinkDelay:    b.options.Delay.Draw(b.rand),
+		Source:       oaiv1beta1.Connection{Node: source, Interface: b.addInterface(source)},
+		Destination:  oaiv1beta1.Connection{Node: destination, Interface: b.addInterface(destination)},
+	}
+}
+
+func (b *builder) addInterface(nodeName string) string {
+	node := b.topology.Nodes[nodeName]
+	name := fmt.Sprintf("eth%d", len(node.Interfaces))
+	node.Interfaces = append(node.Interfaces, name)
+
+	return name
+}
+
+func (b *builder) linked(a, c string) bool {
+	_, direct := b.topology.Links[fmt.Sprintf("%s--%s", a, c)]
+	_, reverse := b.topology.Links[fmt.Sprintf("%s--%s", c, a)]
+
+	return direct || reverse
+}
+
+// setHops sets in each node its distance from the core
+func (b *builder) setHops() {
+	neighbours := make(map[string][]string)
+	for _, link := range b.topology.Links {
+		neighbours[link.Source.Node] = append(neighbours[link.Source.Node], link.Destination.Node)
+		neighbours[link.Destination.Node] = append(neighbours[link.Destination.Node], link.Source.Node)
+	}
+
+	var queue []string
+	visited := make(map[string]bool)
+	for nodeName, node := range b.topology.Nodes {
+		if node.Core {
+			queue = append(queue, nodeName)
+			visited[nodeName] = true
+		}
+	}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, neighbour := range neighbours[current] {
+			if visited[neighbour] {
+				continue
+			}
+			visited[neighbour] = true
+			b.topology.Nodes[neighbour].Hops = b.topology.Nodes[current].Hops + 1
+			queue = append(queue, neighbour)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package generator
+
+import (
+	"testing"
+
+	oaiv1beta1 "github.com/juliorenner/oai-k8s/operator/api/v1beta1"
+	. "github.com/onsi/gomega"
+)
+
+func TestGenerate(t *testing.T) {
+	RegisterTestingT(t)
+
+	capacity := &Distribution{Type: Constant, A: 1200}
+	delay := &Distribution{Type: Uniform, A: 0.25, B: 1}
+
+	tests := []struct {
+		options       *Options
+		expectedNodes int
+		expectedLinks int
+	}{
+		{&Options{Shape: Tree, Depth: 3, FanOut: 2}, 15, 14},
+		{&Options{Shape: FatTree, Depth: 3, FanOut: 2}, 15, 2 + 4*2 + 8*2},
+		{&Options{Shape: Ring, Nodes: 10}, 10, 10},
+		{&Options{Shape: Mesh, Nodes: 50, MeshDegree: 0}, 50, 49},
+	}
+
+	for _, test := range tests {
+		test.options.Capacity = capacity
+		test.options.Delay = delay
+
+		topology, err := Generate(test.options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(topology.Nodes).To(HaveLen(test.expectedNodes), string(test.options.Shape))
+		Expect(topology.Links).To(HaveLen(test.expectedLinks), string(test.options.Shape))
+		expectConnected(topology)
+
+		again, err := Generate(test.options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(again).To(Equal(topology))
+	}
+
+	_, err := Generate(&Options{Shape: "star", Capacity: capacity, Delay: delay})
+	Expect(err).To(HaveOccurred())
+}
+
+func TestGenerateFatTreeCapacity(t *testing.T) {
+	RegisterTestingT(t)
+
+	topology, err := Generate(&Options{Shape: FatTree, Depth: 3, FanOut: 2,
+		Capacity: &Distribution{Type: Constant, A: 100}, Delay: &Distribution{Type: Constant, A: 1}})
+	Expect(err).NotTo(HaveOccurred())
+
+	for _, link := range topology.Links {
+		source := topology.Nodes[link.Source.Node]
+		switch source.Hops {
+		case 1:
+			Expect(link.LinkCapacity).To(BeNumerically("==", 400))
+		case 2:
+			Expect(link.LinkCapacity).To(BeNumerically("==", 200))
+		case 3:
+			Expect(link.LinkCapacity).To(BeNumerically("==", 100))
+		}
+	}
+
+	Expect(EdgeNodes(topology)).To(HaveLen(8))
+}
+
+func TestParseDistribution(t *testing.T) {
+	RegisterTestingT(t)
+
+	distribution, err := ParseDistribution("uniform:300:1200")
+	Expect(err).NotTo(HaveOccurred())
+	Expect(distribution).To(Equal(&Distribution{Type: Uniform, A: 300, B: 1200}))
+
+	distribution, err = ParseDistribution("constant:2")
+	Expect(err).NotTo(HaveOccurred())
+	Expect(distribution).To(Equal(&Distribution{Type: Constant, A: 2}))
+
+	for _, invalid := range []string{"constant", "uniform:1", "normal:a:1", "poisson:1"} {
+		_, err := ParseDistribution(invalid)
+		Expect(err).To(HaveOccurred(), invalid)
+	}
+}
+
+// expectConnected checks every node can be reached from the core and that the interfaces match the links
+func expectConnected(topology *oaiv1beta1.Topology) {
+	interfaces := 0
+	for _, node := range topology.Nodes {
+		if !node.Core {
+			Expect(node.Hops).To(BeNumerically(">", 0))
+		}
+		interfaces += len(node.Interfaces)
+	}
+	Expect(interfaces).To(Equal(2 * len(topology.Links)))
+}
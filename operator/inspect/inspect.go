@@ -0,0 +1,202 @@
+package inspect
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	oaiv1beta1 "github.com/juliorenner/oai-k8s/operator/api/v1beta1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Placement is a splits placer with the splits created for its RUs
+type Placement struct {
+	SplitsPlacer *oaiv1beta1.SplitsPlacer
+	// Splits key is the split name, the RUs whose split does not exist are not present
+	Splits map[string]*oaiv1beta1.Split
+}
+
+// GetPlacement reads the splits placer and the splits of its RUs
+func GetPlacement(ctx context.Context, c client.Client, key types.NamespacedName) (*Placement, error) {
+	splitsPlacer := &oaiv1beta1.SplitsPlacer{}
+	if err := c.Get(ctx, key, splitsPlacer); err != nil {
+		return nil, fmt.Errorf("error getting splits placer '%s': %w", key, err)
+	}
+
+	splitList := &oaiv1beta1.SplitList{}
+	if err := c.List(ctx, splitList, client.InNamespace(key.Namespace)); err != nil {
+		return nil, fmt.Errorf("error listing splits: %w", err)
+	}
+
+	placement := &Placement{SplitsPlacer: splitsPlacer, Splits: make(map[string]*oaiv1beta1.Split)}
+	for i := range splitList.Items {
+		placement.Splits[splitList.Items[i].Name] = &splitList.Items[i]
+	}
+
+	return placement, nil
+}
+
+// WriteChains renders the chain of each RU from the RU to the core, with the node and the pod IP of every piece
+func WriteChains(w io.Writer, placement *Placement) error {
+	splitsPlacer := placement.SplitsPlacer
+	fmt.Fprintf(w, "%s/%s %s\n", splitsPlacer.Namespace, splitsPlacer.Name,
+		stateOrPending(string(splitsPlacer.Status.State)))
+
+	for _, ru := range splitsPlacer.Spec.RUs {
+		split := placement.Splits[ru.SplitName]
+		status := &oaiv1beta1.SplitStatus{}
+		splitState := "no split"
+		if split != nil {
+			status = &split.Status
+			splitState = fmt.Sprintf("%s, stage %s", stateOrPending(string(status.State)),
+				stateOrPending(string(status.Stage)))
+		}
+
+		fmt.Fprintf(w, "%s (%s)\n", ru.SplitName, splitState)
+		fmt.Fprintf(w, "└─ RU     %s\n", piece(ru.RUNode, status.RUIP))
+		if ru.CUNode == "" || ru.DUNode == "" {
+			fmt.Fprintf(w, "   └─ not placed, see: kubectl oai why %s %s\n", splitsPlacer.Name, ru.SplitName)
+			continue
+		}
+
+		fmt.Fprintf(w, "   └─ DU     %s\n", piece(ru.DUNode, status.DUIP))
+		if ru.CUUPNode != "" {
+			fmt.Fprintf(w, "      ├─ CU-UP  %s\n", piece(ru.CUUPNode, status.CUUPIP))
+		}
+		fmt.Fprintf(w, "      └─ CU     %s\n", piece(ru.CUNode, status.CUIP))
+		coreIP := splitsPlacer.Spec.CoreIP
+		if split != nil {
+			coreIP = split.Spec.CoreIP
+		}
+		fmt.Fprintf(w, "         └─ core %s\n", piece(ru.CoreNode, coreIP))
+		fmt.Fprintf(w, "   path: %s\n", strings.Join(ru.Path, " → "))
+	}
+
+	return nil
+}
+
+// WriteUtilisation renders the bandwidth of the links, the resources reserved in the nodes and the hops of the
+// chains reported in the splits placer status
+func WriteUtilisation(w io.Writer, splitsPlacer *oaiv1beta1.SplitsPlacer) error {
+	status := &splitsPlacer.Status
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "LINK\tCAPACITY\tUSED\tREMAINING\tUTILISATION")
+	for _, link := range status.Links {
+		utilisation := 0.0
+		if capacity := link.Capacity.MilliValue(); capacity > 0 {
+			utilisation = float64(link.Used.MilliValue()) / float64(capacity) * 100
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%.1f%%\n", link.Name, link.Capacity.String(), link.Used.String(),
+			link.Remaining.String(), utilisation)
+	}
+
+	fmt.Fprintln(tw, "\nNODE\tCPU\tMEMORY")
+	for _, node := range status.Nodes {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", node.Name, node.CPU.String(), node.Memory.String())
+	}
+
+	fmt.Fprintf(tw, "\nallocated RUs: %d of %d\n", status.AllocatedRUs, len(splitsPlacer.Spec.RUs))
+	if status.Hops != nil {
+		fmt.Fprintf(tw, "hops: total %d, max %d, average %s\n", status.Hops.Total, status.Hops.Max,
+			status.Hops.Average.String())
+	}
+
+	return tw.Flush()
+}
+
+// WriteExplanations renders why the RUs were not placed and the chains evicted by others. If the split name is set,
+// only its RU is described.
+func WriteExplanations(w io.Writer, splitsPlacer *oaiv1beta1.SplitsPlacer, splitName string) error {
+	found := false
+	for _, explanation := range splitsPlacer.Status.Explanations {
+		if splitName != "" && explanation.SplitName != splitName {
+			continue
+		}
+		found = true
+
+		fmt.Fprintf(w, "%s (RU node %s) was not placed:\n", explanation.SplitName, explanation.RUNode)
+		if len(explanation.Rejections) == 0 {
+			fmt.Fprintln(w, "  no reason recorded")
+		}
+		for _, rejection := range explanation.Rejections {
+			path := "no path"
+			if len(rejection.Path) > 0 {
+				path = strings.Join(rejection.Path, " → ")
+			}
+			fmt.Fprintf(w, "  %s: %s\n", path, rejection.Reason)
+		}
+	}
+
+	for _, eviction := range splitsPlacer.Status.Evictions {
+		if splitName != "" && eviction.SplitName != splitName {
+			continue
+		}
+		found = true
+
+		outcome := "left unallocated"
+		if eviction.Reallocated {
+			outcome = "placed again"
+		}
+		fmt.Fprintf(w, "%s was evicted by %s and %s\n", eviction.SplitName, eviction.PreemptedBy, outcome)
+	}
+
+	if !found {
+		if splitName != "" {
+			fmt.Fprintf(w, "%s has no rejection or eviction recorded\n", splitName)
+		} else {
+			fmt.Fprintln(w, "every RU was placed")
+		}
+	}
+
+	return nil
+}
+
+// RequestReplacement clears the state of the splits placer so the controller places its RUs again. The chains keep
+// their path and nodes if they still fit, unless fresh is set and their positions are cleared first. The controller
+// then moves the splits of the chains placed in other nodes and deletes the splits of the chains left unallocated.
+// The splits placed by a scheduler are not placed by the controller, so they cannot be placed again.
+func RequestReplacement(ctx context.Context, c client.Client, splitsPlacer *oaiv1beta1.SplitsPlacer,
+	fresh bool) error {
+	if splitsPlacer.Spec.SchedulerName != "" {
+		return fmt.Errorf("splits placer %s is placed by the scheduler %s, its RUs cannot be placed again",
+			splitsPlacer.Name, splitsPlacer.Spec.SchedulerName)
+	}
+
+	if fresh {
+		for _, ru := range splitsPlacer.Spec.RUs {
+			ru.CUNode, ru.CUUPNode, ru.DUNode = "", "", ""
+			ru.Path = nil
+			ru.Disaggregation = ""
+		}
+		if err := c.Update(ctx, splitsPlacer); err != nil {
+			return fmt.Errorf("error clearing the positions of the splits placer: %w", err)
+		}
+	}
+
+	splitsPlacer.Status.State = ""
+	if err := c.Status().Update(ctx, splitsPlacer); err != nil {
+		return fmt.Errorf("error clearing the state of the splits placer: %w", err)
+	}
+
+	return nil
+}
+
+func piece(nodeName, ip string) string {
+	if ip == "" {
+		ip = "-"
+	}
+
+	return fmt.Sprintf("%-10s %s", nodeName, ip)
+}
+
+func stateOrPending(state string) string {
+	if state == "" {
+		return "Pending"
+	}
+
+	return state
+}
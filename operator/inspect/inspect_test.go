@@ -0,0 +1,114 @@
+package inspect
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	oaiv1beta1 "github.com/juliorenner/oai-k8s/operator/api/v1beta1"
+	"github.com/juliorenner/oai-k8s/operator/controllers/utils"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestWriteChains(t *testing.T) {
+	RegisterTestingT(t)
+
+	placement := &Placement{
+		SplitsPlacer: generateSplitsPlacer(),
+		Splits: map[string]*oaiv1beta1.Split{
+			"split1": {
+				Spec: oaiv1beta1.SplitSpec{CoreIP: "10.0.0.1"},
+				Status: oaiv1beta1.SplitStatus{State: oaiv1beta1.SplitStateRunning, Stage: oaiv1beta1.SplitStageReady,
+					CUIP: "10.1.0.2", DUIP: "10.1.0.3", RUIP: "10.1.0.4"},
+			},
+		},
+	}
+
+	out := &bytes.Buffer{}
+	Expect(WriteChains(out, placement)).To(Succeed())
+	Expect(out.String()).To(ContainSubstring("split1 (Running, stage Ready)"))
+	Expect(out.String()).To(ContainSubstring("└─ RU     node4      10.1.0.4"))
+	Expect(out.String()).To(ContainSubstring("└─ CU     node2      10.1.0.2"))
+	Expect(out.String()).To(ContainSubstring("└─ core node1      10.0.0.1"))
+	Expect(out.String()).To(ContainSubstring("path: node1 → node2 → node3 → node4"))
+	Expect(out.String()).To(ContainSubstring("split2 (no split)"))
+	Expect(out.String()).To(ContainSubstring("not placed, see: kubectl oai why placer split2"))
+}
+
+func TestWriteUtilisation(t *testing.T) {
+	RegisterTestingT(t)
+
+	splitsPlacer := generateSplitsPlacer()
+	splitsPlacer.Status.Links = []*oaiv1beta1.LinkUtilisation{{Name: "node1--node2",
+		Capacity: *utils.NewQuantity("1200"), Used: *utils.NewQuantity("300"), Remaining: *utils.NewQuantity("900")}}
+	splitsPlacer.Status.Hops = &oaiv1beta1.HopStatistics{Total: 3, Max: 3, Average: *utils.NewQuantity("3")}
+
+	out := &bytes.Buffer{}
+	Expect(WriteUtilisation(out, splitsPlacer)).To(Succeed())
+	Expect(out.String()).To(MatchRegexp(`node1--node2\s+1200\s+300\s+900\s+25.0%`))
+	Expect(out.String()).To(ContainSubstring("hops: total 3, max 3, average 3"))
+}
+
+func TestWriteExplanations(t *testing.T) {
+	RegisterTestingT(t)
+
+	splitsPlacer := generateSplitsPlacer()
+	splitsPlacer.Status.Explanations = []*oaiv1beta1.RUExplanation{{SplitName: "split2", RUNode: "node4",
+		Rejections: []*oaiv1beta1.PathRejection{{Path: []string{"node1", "node4"}, Reason: "link short of bandwidth"}}}}
+	splitsPlacer.Status.Evictions = []*oaiv1beta1.Eviction{{SplitName: "split2", PreemptedBy: "split1"}}
+
+	out := &bytes.Buffer{}
+	Expect(WriteExplanations(out, splitsPlacer, "split2")).To(Succeed())
+	Expect(out.String()).To(ContainSubstring("node1 → node4: link short of bandwidth"))
+	Expect(out.String()).To(ContainSubstring("split2 was evicted by split1 and left unallocated"))
+
+	out.Reset()
+	Expect(WriteExplanations(out, splitsPlacer, "split1")).To(Succeed())
+	Expect(out.String()).To(Equal("split1 has no rejection or eviction recorded\n"))
+}
+
+func TestRequestReplacement(t *testing.T) {
+	RegisterTestingT(t)
+
+	scheme := runtime.NewScheme()
+	Expect(oaiv1beta1.AddToScheme(scheme)).To(Succeed())
+	splitsPlacer := generateSplitsPlacer()
+	splitsPlacer.Status.State = oaiv1beta1.PlacerStateFinished
+	c := fake.NewFakeClientWithScheme(scheme, splitsPlacer)
+
+	key := types.NamespacedName{Namespace: "oai", Name: "placer"}
+	placement, err := GetPlacement(context.Background(), c, key)
+	Expect(err).NotTo(HaveOccurred())
+	Expect(RequestReplacement(context.Background(), c, placement.SplitsPlacer, true)).To(Succeed())
+
+	stored := &oaiv1beta1.SplitsPlacer{}
+	Expect(c.Get(context.Background(), key, stored)).To(Succeed())
+	Expect(stored.Status.State).To(BeEmpty())
+	Expect(stored.Spec.RUs[0].CUNode).To(BeEmpty())
+	Expect(stored.Spec.RUs[0].Path).To(BeEmpty())
+	Expect(stored.Spec.RUs[0].RUNode).To(Equal("node4"))
+
+	// the scheduler places the splits of its splits placers, not the controller
+	stored.Spec.SchedulerName = "oai-scheduler"
+	stored.Status.State = oaiv1beta1.PlacerStateFinished
+	Expect(RequestReplacement(context.Background(), c, stored, false)).To(MatchError(ContainSubstring(
+		"placed by the scheduler oai-scheduler")))
+	Expect(stored.Status.State).To(BeEquivalentTo(oaiv1beta1.PlacerStateFinished))
+}
+
+func generateSplitsPlacer() *oaiv1beta1.SplitsPlacer {
+	return &oaiv1beta1.SplitsPlacer{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "oai", Name: "placer"},
+		Spec: oaiv1beta1.SplitsPlacerSpec{
+			RUs: []*oaiv1beta1.ChainPosition{
+				{SplitName: "split1", RUNode: "node4", CoreNode: "node1", CUNode: "node2", DUNode: "node3",
+					Path: []string{"node1", "node2", "node3", "node4"}},
+				{SplitName: "split2", RUNode: "node4"},
+			},
+		},
+	}
+}
@@ -28,6 +28,7 @@ import (
 
 	oaiv1beta1 "github.com/juliorenner/oai-k8s/operator/api/v1beta1"
 	"github.com/juliorenner/oai-k8s/operator/controllers"
+	"github.com/juliorenner/oai-k8s/operator/export"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -45,8 +46,11 @@ func init() {
 
 func main() {
 	var metricsAddr string
+	var exportAddr string
 	var enableLeaderElection bool
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080", "The address the metric endpoint binds to.")
+	flag.StringVar(&exportAddr, "export-addr", "127.0.0.1:8082", "The address the topology export endpoint binds "+
+		"to. It has no authentication, so it only listens on the loopback by default. Empty disables it.")
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
@@ -87,6 +91,17 @@ func main() {
 	}
 	// +kubebuilder:scaffold:builder
 
+	if exportAddr != "" {
+		if err := mgr.Add(&export.Server{
+			Client: mgr.GetClient(),
+			Addr:   exportAddr,
+			Log:    ctrl.Log.WithName("export"),
+		}); err != nil {
+			setupLog.Error(err, "unable to add topology export server")
+			os.Exit(1)
+		}
+	}
+
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
 		setupLog.Error(err, "problem running manager")
@@ -0,0 +1,140 @@
+/*
+Copyright 2020 Julio Renner.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/juliorenner/oai-k8s/operator/controllers/utils"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	retryPeriod = 20 * time.Second
+)
+
+// Binder is a custom binder, not a kube-scheduler: it binds the pending pods that requested the RAN scheduler to the
+// node chosen by the RANPlacement filter, score and reserve steps. The default checks of kube-scheduler, such as the
+// node resources fit, taints, affinity and host ports, are not run, and there is no scheduling queue, backoff or
+// preemption other than the reconciler requeues.
+type Binder struct {
+	client.Client
+	Log           logr.Logger
+	Recorder      record.EventRecorder
+	SchedulerName string
+	Placement     *RANPlacement
+}
+
+// +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=pods/binding;bindings,verbs=create
+// +kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=configmaps,verbs=get;update
+// +kubebuilder:rbac:groups=oai.unisinos,resources=splits;splitsplacers,verbs=get;list;watch
+
+func (b *Binder) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	ctx := context.Background()
+	log := b.Log.WithValues("pod", req.NamespacedName)
+
+	pod := &v1.Pod{}
+	if err := b.Get(ctx, req.NamespacedName, pod); err != nil {
+		if apierrors.IsNotFound(err) {
+			b.Placement.Release(req.NamespacedName)
+		}
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// finished pods do not use their nodes anymore, the pod replacing them is reserved again
+	if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
+		b.Placement.Release(req.NamespacedName)
+		return ctrl.Result{}, nil
+	}
+
+	if pod.Spec.SchedulerName != b.SchedulerName || pod.Spec.NodeName != "" || pod.DeletionTimestamp != nil {
+		return ctrl.Result{}, nil
+	}
+
+	log.Info("scheduling pod")
+
+	nodeList := &v1.NodeList{}
+	if err := utils.ListNodes(b.Client, nodeList); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	bestNode, bestScore := "", int64(-1)
+	for _, node := range nodeList.Items {
+		if err := b.Placement.Filter(pod, node.Name); err != nil {
+			log.V(1).Info("node filtered", "node", node.Name, "reason", err.Error())
+			continue
+		}
+
+		score, err := b.Placement.Score(pod, node.Name)
+		if err != nil {
+			log.Error(err, "error scoring node", "node", node.Name)
+			continue
+		}
+
+		if score > bestScore {
+			bestNode, bestScore = node.Name, score
+		}
+	}
+
+	if bestNode == "" {
+		b.Recorder.Event(pod, v1.EventTypeWarning, "FailedScheduling", "no node fits the split piece")
+		return ctrl.Result{RequeueAfter: retryPeriod}, nil
+	}
+
+	if err := b.Placement.Reserve(pod, bestNode); err != nil {
+		b.Recorder.Event(pod, v1.EventTypeWarning, "FailedScheduling", err.Error())
+		return ctrl.Result{RequeueAfter: retryPeriod}, nil
+	}
+
+	binding := &v1.Binding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      pod.Name,
+			Namespace: pod.Namespace,
+			UID:       pod.UID,
+		},
+		Target: v1.ObjectReference{
+			Kind: "Node",
+			Name: bestNode,
+		},
+	}
+
+	if err := b.Create(ctx, binding); err != nil {
+		b.Placement.Unreserve(pod, bestNode)
+		return ctrl.Result{}, fmt.Errorf("error binding pod to node '%s': %w", bestNode, err)
+	}
+
+	b.Recorder.Event(pod, v1.EventTypeNormal, "Scheduled", fmt.Sprintf("assigned to node '%s'", bestNode))
+	log.Info("pod scheduled", "node", bestNode, "score", bestScore)
+
+	return ctrl.Result{}, nil
+}
+
+func (b *Binder) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1.Pod{}).
+		Complete(b)
+}
@@ -0,0 +1,599 @@
+/*
+Copyright 2020 Julio Renner.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/go-logr/logr"
+	oaiv1beta1 "github.com/juliorenner/oai-k8s/operator/api/v1beta1"
+	"github.com/juliorenner/oai-k8s/operator/controllers"
+	"github.com/juliorenner/oai-k8s/operator/controllers/algorithm"
+	"github.com/juliorenner/oai-k8s/operator/controllers/utils"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// Name is the name pods have to use as schedulerName to be placed by the RAN scheduler
+	Name = "oai-ran-scheduler"
+
+	splitLabel      = "split"
+	splitOwnerLabel = "split-owner"
+	// topologyDataKey is the key of the topology config map holding the topology
+	topologyDataKey = "topology"
+
+	maxNodeScore = 100
+)
+
+// topologyPlacement is the placement state of the topology used by one SplitsPlacer
+type topologyPlacement struct {
+	*algorithm.PlacementBFS
+	topologyKey types.NamespacedName
+	// topologyData and generation are the topology and the SplitsPlacer generation the placement was loaded from, the
+	// placement is loaded again once any of them changes
+	topologyData string
+	generation   int64
+	// performance sizes the pieces recorded in the ledger
+	performance *oaiv1beta1.PerformanceProfiles
+	// cores key is the core node
+	cores map[string]*oaiv1beta1.Node
+	// consulted keeps the links reserved by each chain found in the ledger when the placement was loaded
+	consulted map[string]map[string]float32
+}
+
+// chain keeps the nodes reserved for each piece of one Split
+type chain struct {
+	key       string
+	placerKey types.NamespacedName
+	position  *oaiv1beta1.ChainPosition
+	nodes     map[controllers.SplitPiece]string
+	// pods key is the piece and the value the pod holding its reservation
+	pods            map[controllers.SplitPiece]types.NamespacedName
+	networkReserved bool
+}
+
+// RANPlacement places the pieces of a RAN chain according to the disaggregation requirements of the topology
+// referenced by the SplitsPlacer that owns the Split, keeping the node and link reservations in memory. It is not a
+// scheduling framework plugin: its Filter, Score, Reserve and Unreserve steps are only run by the Binder.
+type RANPlacement struct {
+	client.Client
+	Log logr.Logger
+
+	mutex sync.Mutex
+	// key is the SplitsPlacer
+	placements map[types.NamespacedName]*topologyPlacement
+	// key is the Split
+	chains map[types.NamespacedName]*chain
+}
+
+func NewRANPlacement(k8sClient client.Client, log logr.Logger) *RANPlacement {
+	return &RANPlacement{
+		Client:     k8sClient,
+		Log:        log,
+		placements: make(map[types.NamespacedName]*topologyPlacement),
+		chains:     make(map[types.NamespacedName]*chain),
+	}
+}
+
+// Filter returns an error if the node can not host the split piece of the pod
+func (p *RANPlacement) Filter(pod *v1.Pod, nodeName string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	placement, splitChain, piece, err := p.getChain(pod)
+	if err != nil {
+		return err
+	}
+
+	if rank := p.rank(placement, splitChain, piece, nodeName); rank < 0 {
+		return fmt.Errorf("node '%s' can not host the %s of split '%s'", nodeName, piece,
+			splitChain.position.SplitName)
+	}
+
+	return nil
+}
+
+// Score prefers the nodes of the positions the placement algorithm would choose first
+func (p *RANPlacement) Score(pod *v1.Pod, nodeName string) (int64, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	placement, splitChain, piece, err := p.getChain(pod)
+	if err != nil {
+		return 0, err
+	}
+
+	rank := p.rank(placement, splitChain, piece, nodeName)
+	if rank < 0 {
+		return 0, nil
+	}
+
+	return int64(maxNodeScore / (rank + 1)), nil
+}
+
+// Reserve allocates the node resources for the split piece. Once the CU and DU of the chain, and the CU-UP of NR
+// chains, are reserved, the bandwidth along the chain path is reserved as well.
+func (p *RANPlacement) Reserve(pod *v1.Pod, nodeName string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	placement, splitChain, piece, err := p.getChain(pod)
+	if err != nil {
+		return err
+	}
+
+	podKey := types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}
+	if reservedNode, reserved := splitChain.nodes[piece]; reserved {
+		if reservedNode != nodeName {
+			return fmt.Errorf("%s of split '%s' already reserved in node '%s'", piece,
+				splitChain.position.SplitName, reservedNode)
+		}
+		// a new pod of the piece, such as the one replacing a deleted pod, takes over the reservation
+		splitChain.pods[piece] = podKey
+		return nil
+	}
+
+	if err := placement.ReserveNode(string(piece), nodeName); err != nil {
+		placement.ReleaseNode(string(piece), nodeName)
+		return fmt.Errorf("error reserving node '%s': %w", nodeName, err)
+	}
+	splitChain.nodes[piece] = nodeName
+	splitChain.pods[piece] = podKey
+
+	switch piece {
+	case controllers.CU:
+		splitChain.position.CUNode = nodeName
+	case controllers.CUUP:
+		splitChain.position.CUUPNode = nodeName
+	case controllers.DU:
+		splitChain.position.DUNode = nodeName
+	}
+
+	if !placement.Placed(splitChain.position) || splitChain.networkReserved {
+		return nil
+	}
+
+	candidates := placement.Candidates(splitChain.position)
+	if len(candidates) == 0 {
+		p.unreserve(placement, splitChain, piece)
+		return fmt.Errorf("no path available for split '%s'", splitChain.position.SplitName)
+	}
+
+	// the chain was already accounted from the ledger when the placement was loaded
+	if links, exists := placement.consulted[splitChain.key]; exists {
+		placement.ReleaseLinks(links)
+		delete(placement.consulted, splitChain.key)
+	}
+
+	splitChain.position = candidates[0]
+	if err := placement.ReserveNetwork(splitChain.position); err != nil {
+		p.unreserve(placement, splitChain, piece)
+		return fmt.Errorf("error reserving network of split '%s': %w", splitChain.position.SplitName, err)
+	}
+	splitChain.networkReserved = true
+
+	links := placement.ChainLinks(splitChain.position)
+	if err := p.updateLedger(placement, func(reservations *oaiv1beta1.TopologyReservations) {
+		reservations.Chains[splitChain.key] = controllers.GetChainReservation(splitChain.position, links,
+			placement.performance)
+	}); err != nil {
+		p.unreserve(placement, splitChain, piece)
+		return fmt.Errorf("error recording reservation of split '%s': %w", splitChain.position.SplitName, err)
+	}
+
+	if err := p.updateSplitCore(placement, splitChain); err != nil {
+		p.unreserve(placement, splitChain, piece)
+		return err
+	}
+
+	p.Log.Info("chain reserved", "split", splitChain.position.SplitName, "path", splitChain.position.Path)
+
+	return nil
+}
+
+// Unreserve gives back the resources reserved for the split piece, including the chain bandwidth
+func (p *RANPlacement) Unreserve(pod *v1.Pod, nodeName string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	placement, splitChain, piece, err := p.getChain(pod)
+	if err != nil {
+		p.Log.Error(err, "error unreserving pod", "pod", pod.Name)
+		return
+	}
+
+	if splitChain.nodes[piece] != nodeName {
+		return
+	}
+
+	p.unreserve(placement, splitChain, piece)
+}
+
+// Release gives back the resources reserved for the pod once it is deleted or finished. The chain of the Split is
+// forgotten once none of its pieces is reserved, so the chains of deleted Splits do not keep their reservations.
+func (p *RANPlacement) Release(podKey types.NamespacedName) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	for splitKey, splitChain := range p.chains {
+		for piece, reservedPod := range splitChain.pods {
+			if reservedPod != podKey {
+				continue
+			}
+
+			if placement, exists := p.placements[splitChain.placerKey]; exists {
+				p.unreserve(placement, splitChain, piece)
+			}
+			p.Log.Info("pod reservation released", "pod", podKey, "piece", piece)
+		}
+
+		if len(splitChain.pods) == 0 {
+			delete(p.chains, splitKey)
+		}
+	}
+
+	// the placements without chains are loaded again for the next pod, also dropping the ones of deleted placers
+	for placerKey := range p.placements {
+		inUse := false
+		for _, splitChain := range p.chains {
+			inUse = inUse || splitChain.placerKey == placerKey
+		}
+		if !inUse {
+			delete(p.placements, placerKey)
+		}
+	}
+}
+
+func (p *RANPlacement) unreserve(placement *topologyPlacement, splitChain *chain,
+	piece controllers.SplitPiece) {
+	if splitChain.networkReserved && piece != controllers.RU {
+		if links, exists := placement.consulted[splitChain.key]; exists {
+			// reserved before the placement was loaded again, its bandwidth was deducted from the ledger
+			placement.ReleaseLinks(links)
+			delete(placement.consulted, splitChain.key)
+		} else {
+			placement.ReleaseNetwork(splitChain.position)
+		}
+		splitChain.networkReserved = false
+		splitChain.position.Path = nil
+
+		if err := p.updateLedger(placement, func(reservations *oaiv1beta1.TopologyReservations) {
+			delete(reservations.Chains, splitChain.key)
+		}); err != nil {
+			p.Log.Error(err, "error removing reservation from ledger", "split", splitChain.position.SplitName)
+		}
+	}
+
+	placement.ReleaseNode(string(piece), splitChain.nodes[piece])
+	delete(splitChain.nodes, piece)
+	delete(splitChain.pods, piece)
+
+	switch piece {
+	case controllers.CU:
+		splitChain.position.CUNode = ""
+	case controllers.CUUP:
+		splitChain.position.CUUPNode = ""
+	case controllers.DU:
+		splitChain.position.DUNode = ""
+	}
+}
+
+// rank returns the position of the node in the list of candidates for the split piece, or -1 if the node is not a
+// candidate
+func (p *RANPlacement) rank(placement *topologyPlacement, splitChain *chain, piece controllers.SplitPiece,
+	nodeName string) int {
+	if reservedNode, reserved := splitChain.nodes[piece]; reserved {
+		if reservedNode == nodeName {
+			return 0
+		}
+		return -1
+	}
+
+	if piece == controllers.RU {
+		if nodeName == splitChain.position.RUNode {
+			return 0
+		}
+		return -1
+	}
+
+	for i, candidate := range placement.Candidates(splitChain.position) {
+		if (piece == controllers.CU && candidate.CUNode == nodeName) ||
+			(piece == controllers.CUUP && candidate.CUUPNode == nodeName) ||
+			(piece == controllers.DU && candidate.DUNode == nodeName) {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// getChain returns the placement of the topology where the pod split is placed, the chain of the split and the
+// piece the pod runs
+func (p *RANPlacement) getChain(pod *v1.Pod) (*topologyPlacement, *chain, controllers.SplitPiece, error) {
+	piece := controllers.SplitPiece(pod.Labels[splitLabel])
+	if !controllers.Splits.Has(string(piece)) {
+		return nil, nil, "", fmt.Errorf("pod '%s' does not have a valid '%s' label", pod.Name, splitLabel)
+	}
+
+	splitKey := types.NamespacedName{Namespace: pod.Namespace, Name: pod.Labels[splitOwnerLabel]}
+	split := &oaiv1beta1.Split{}
+	if exists, err := utils.GetSplit(p.Client, splitKey, split); err != nil {
+		return nil, nil, "", fmt.Errorf("error getting split of pod '%s': %w", pod.Name, err)
+	} else if !exists {
+		return nil, nil, "", fmt.Errorf("split '%s' does not exist", splitKey.String())
+	}
+
+	owner := metav1.GetControllerOf(split)
+	if owner == nil || owner.Kind != "SplitsPlacer" {
+		return nil, nil, "", fmt.Errorf("split '%s' is not owned by a splits placer", splitKey.String())
+	}
+
+	placerKey := types.NamespacedName{Namespace: split.Namespace, Name: owner.Name}
+	placement, err := p.getPlacement(placerKey)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("error loading placement of splits placer '%s': %w", placerKey.String(), err)
+	}
+
+	splitChain, exists := p.chains[splitKey]
+	if !exists {
+		splitChain = &chain{
+			key:       controllers.GetChainKey(placerKey, split.Name),
+			placerKey: placerKey,
+			position: &oaiv1beta1.ChainPosition{
+				SplitName: split.Name,
+				RUNode:    split.Spec.RUNode,
+				CoreNode:  split.Spec.CoreNode,
+			},
+			nodes: make(map[controllers.SplitPiece]string),
+			pods:  make(map[controllers.SplitPiece]types.NamespacedName),
+		}
+		p.chains[splitKey] = splitChain
+	}
+
+	return placement, splitChain, piece, nil
+}
+
+// getPlacement returns the placement of the splits placer. It is loaded again when the splits placer or its topology
+// changed since it was loaded, so the edits are taken into account by the next pods scheduled.
+func (p *RANPlacement) getPlacement(placerKey types.NamespacedName) (*topologyPlacement, error) {
+	splitsPlacer := &oaiv1beta1.SplitsPlacer{}
+	if err := p.Get(context.Background(), placerKey, splitsPlacer); err != nil {
+		return nil, fmt.Errorf("error getting splits placer: %w", err)
+	}
+
+	if splitsPlacer.Spec.TopologyConfig == "" {
+		return nil, errors.New("splits placer does not reference a topology")
+	}
+
+	topologyKey := types.NamespacedName{Namespace: placerKey.Namespace, Name: splitsPlacer.Spec.TopologyConfig}
+	topologyCM, err := controllers.GetTopologyConfigMap(p.Client, topologyKey)
+	if err != nil {
+		return nil, fmt.Errorf("error reading topology: %w", err)
+	}
+
+	placement, exists := p.placements[placerKey]
+	if exists && placement.topologyKey == topologyKey && placement.generation == splitsPlacer.Generation &&
+		placement.topologyData == topologyCM.Data[topologyDataKey] {
+		return placement, nil
+	}
+
+	if placement, err = p.newPlacement(placerKey, splitsPlacer, topologyCM); err != nil {
+		return nil, err
+	}
+	if exists {
+		p.Log.Info("splits placer or topology changed, placement loaded again", "splitsplacer", placerKey)
+	}
+	p.placements[placerKey] = placement
+
+	return placement, nil
+}
+
+// newPlacement loads the placement of the splits placer from its topology, the ledger and the pods bound to the
+// nodes. The chains of the splits placer already reserved by this scheduler are reserved again in the new placement.
+func (p *RANPlacement) newPlacement(placerKey types.NamespacedName, splitsPlacer *oaiv1beta1.SplitsPlacer,
+	topologyCM *v1.ConfigMap) (*topologyPlacement, error) {
+	topologyKey := types.NamespacedName{Namespace: topologyCM.Namespace, Name: topologyCM.Name}
+	topology := &oaiv1beta1.Topology{}
+	if err := json.Unmarshal([]byte(topologyCM.Data[topologyDataKey]), topology); err != nil {
+		return nil, fmt.Errorf("error unmarshaling topology: %w", err)
+	}
+
+	reservations, err := controllers.ReadTopologyReservations(topologyCM)
+	if err != nil {
+		return nil, fmt.Errorf("error reading topology reservations: %w", err)
+	}
+
+	disaggregations := map[string]*oaiv1beta1.Disaggregation{}
+	if err := controllers.ReadDisaggregationsMetadata(p.Client, disaggregations); err != nil {
+		return nil, fmt.Errorf("error reading disaggregation metadata: %w", err)
+	}
+
+	nodeList := &v1.NodeList{}
+	if err := utils.ListNodes(p.Client, nodeList); err != nil {
+		return nil, fmt.Errorf("error listing K8S nodes: %w", err)
+	}
+
+	k8sNodeMap := utils.NodeListToMap(nodeList)
+	for nodeName := range topology.Nodes {
+		if _, exists := k8sNodeMap[nodeName]; !exists {
+			return nil, fmt.Errorf("node '%s' does not exist", nodeName)
+		}
+	}
+
+	requestedResources := &utils.RequestedResources{
+		Memory: *utils.NewQuantity(controllers.SplitMemoryRequestValue),
+		CPU:    *utils.NewQuantity(controllers.SplitCPURequestValue),
+	}
+
+	podList := &v1.PodList{}
+	if err := utils.ListPods(p.Client, podList); err != nil {
+		return nil, fmt.Errorf("error listing K8S pods: %w", err)
+	}
+
+	// the pods placed by this scheduler from now on are accounted by the reservations, as the pods of the chains
+	// already reserved, which are reserved again below
+	var chains []*chain
+	reservedPods := make(map[types.NamespacedName]bool)
+	for _, splitChain := range p.chains {
+		if splitChain.placerKey != placerKey {
+			continue
+		}
+		chains = append(chains, splitChain)
+		for _, podKey := range splitChain.pods {
+			reservedPods[podKey] = true
+		}
+	}
+
+	var pods []v1.Pod
+	for _, pod := range podList.Items {
+		if !reservedPods[types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}] {
+			pods = append(pods, pod)
+		}
+	}
+	nodesUsage := controllers.NodesUsage(pods, reservations, placerKey)
+
+	placement := &topologyPlacement{
+		PlacementBFS: algorithm.NewPlacementBFS(topology, disaggregations, nodeList, nodesUsage, requestedResources,
+			p.Log.WithValues("splitsplacer", placerKey)),
+		topologyKey:  topologyKey,
+		topologyData: topologyCM.Data[topologyDataKey],
+		generation:   splitsPlacer.Generation,
+		performance:  splitsPlacer.Spec.Performance,
+		cores:        make(map[string]*oaiv1beta1.Node),
+		consulted:    make(map[string]map[string]float32),
+	}
+	for nodeName, node := range topology.Nodes {
+		if node.Core {
+			placement.cores[nodeName] = node
+		}
+	}
+	placement.SetRAT(splitsPlacer.Spec.RAT)
+	for piece, pieceResources := range controllers.GetPiecesResources(splitsPlacer.Spec.Performance) {
+		placement.SetPieceResources(string(piece), pieceResources)
+	}
+	placement.SetCoreSelection(splitsPlacer.Spec.CoreSelection)
+	placement.SetConstraints(splitsPlacer.Spec.Constraints)
+
+	// the chains of the splits placer whose split was deleted while the scheduler was not running are released
+	staleChains, err := p.getStaleChains(placerKey, reservations)
+	if err != nil {
+		return nil, err
+	}
+	if len(staleChains) > 0 {
+		if err := p.updateLedger(placement, func(reservations *oaiv1beta1.TopologyReservations) {
+			for _, chainKey := range staleChains {
+				delete(reservations.Chains, chainKey)
+			}
+		}); err != nil {
+			return nil, fmt.Errorf("error releasing chains of deleted splits: %w", err)
+		}
+		for _, chainKey := range staleChains {
+			delete(reservations.Chains, chainKey)
+		}
+		p.Log.Info("chains of deleted splits released", "chains", staleChains)
+	}
+
+	// every chain in the ledger is accounted, including the ones this scheduler reserved before restarting
+	for chainKey, chainReservation := range reservations.Chains {
+		placement.ReserveLinks(chainReservation.Links)
+		placement.ReserveCores(map[string]int{chainReservation.Core: 1})
+		placement.consulted[chainKey] = chainReservation.Links
+	}
+
+	for _, splitChain := range chains {
+		for piece, nodeName := range splitChain.nodes {
+			if err := placement.ReserveNode(string(piece), nodeName); err != nil {
+				p.Log.Info("node overcommitted after loading the placement again", "node", nodeName,
+					"split", splitChain.position.SplitName, "reason", err.Error())
+			}
+		}
+	}
+
+	return placement, nil
+}
+
+// updateSplitCore points the split to the core chosen for its chain. The split is created before its pods are
+// scheduled, so it only knows the core the RU pins; the split controller reconfigures the pieces once it changes.
+func (p *RANPlacement) updateSplitCore(placement *topologyPlacement, splitChain *chain) error {
+	splitKey := types.NamespacedName{Namespace: splitChain.placerKey.Namespace, Name: splitChain.position.SplitName}
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		split := &oaiv1beta1.Split{}
+		if err := p.Get(context.Background(), splitKey, split); err != nil {
+			return err
+		}
+
+		updated := split.DeepCopy()
+		controllers.SetSplitCore(updated, splitChain.position.CoreNode, placement.cores[splitChain.position.CoreNode])
+		if reflect.DeepEqual(updated.Spec, split.Spec) {
+			return nil
+		}
+
+		return p.Update(context.Background(), updated)
+	})
+	if err != nil {
+		return fmt.Errorf("error updating core of split '%s': %w", splitKey.String(), err)
+	}
+
+	return nil
+}
+
+// getStaleChains returns the chains of the splits placer in the ledger whose split does not exist anymore
+func (p *RANPlacement) getStaleChains(placerKey types.NamespacedName,
+	reservations *oaiv1beta1.TopologyReservations) ([]string, error) {
+	var staleChains []string
+	for chainKey := range reservations.Chains {
+		if !controllers.IsChainOwnedBy(chainKey, placerKey) {
+			continue
+		}
+
+		splitKey := types.NamespacedName{Namespace: placerKey.Namespace,
+			Name: strings.TrimPrefix(chainKey, placerKey.String()+"/")}
+		if exists, err := utils.GetSplit(p.Client, splitKey, &oaiv1beta1.Split{}); err != nil {
+			return nil, fmt.Errorf("error getting split '%s': %w", splitKey.String(), err)
+		} else if !exists {
+			staleChains = append(staleChains, chainKey)
+		}
+	}
+	sort.Strings(staleChains)
+
+	return staleChains, nil
+}
+
+// updateLedger applies the update to the reservations ledger of the placement topology, retrying on conflicts with
+// other placements
+func (p *RANPlacement) updateLedger(placement *topologyPlacement,
+	update func(reservations *oaiv1beta1.TopologyReservations)) error {
+	return retry.OnError(retry.DefaultRetry, controllers.IsConflict, func() error {
+		topologyCM, err := controllers.GetTopologyConfigMap(p.Client, placement.topologyKey)
+		if err != nil {
+			return err
+		}
+
+		return controllers.UpdateTopologyReservations(p.Client, topologyCM, update)
+	})
+}
@@ -0,0 +1,272 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	oaiv1beta1 "github.com/juliorenner/oai-k8s/operator/api/v1beta1"
+	"github.com/juliorenner/oai-k8s/operator/controllers"
+	"github.com/juliorenner/oai-k8s/operator/controllers/utils"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	ctrllog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	namespace = "oai"
+	chainKey  = "oai/placer/split1"
+)
+
+// the RU of split1 is in node5, its CU can go to node2 or node3 and its DU only to node4
+var testTopology = &oaiv1beta1.Topology{
+	Nodes: map[string]*oaiv1beta1.Node{
+		"node1": {Core: true, CoreIP: "10.0.0.1", AMFIP: "10.0.0.2"},
+		"node2": {},
+		"node3": {},
+		"node4": {},
+		"node5": {},
+	},
+	Links: map[string]*oaiv1beta1.Link{
+		"node1--node2": generateLink("node1", "node2", 1000, 1),
+		"node1--node3": generateLink("node1", "node3", 1000, 1),
+		"node2--node4": generateLink("node2", "node4", 1000, 1),
+		"node3--node4": generateLink("node3", "node4", 1000, 1),
+		"node4--node5": generateLink("node4", "node5", 250, 0.5),
+	},
+}
+
+var testDisaggregations = map[string]*oaiv1beta1.Disaggregation{
+	"1": {
+		Backhaul:  &oaiv1beta1.NetworkRequirements{Bandwidth: 100},
+		Midhaul:   &oaiv1beta1.NetworkRequirements{Latency: 30, Bandwidth: 100},
+		Fronthaul: &oaiv1beta1.NetworkRequirements{Latency: 2, Bandwidth: 150},
+	},
+}
+
+func TestFilterAndScore(t *testing.T) {
+	RegisterTestingT(t)
+
+	ranPlacement, _ := newTestRANPlacement(t, nil)
+	cu, ru := generatePod("cu", controllers.CU), generatePod("ru", controllers.RU)
+
+	Expect(ranPlacement.Filter(cu, "node2")).To(Succeed())
+	Expect(ranPlacement.Filter(cu, "node3")).To(Succeed())
+	Expect(ranPlacement.Filter(cu, "node5")).To(MatchError("node 'node5' can not host the cu of split 'split1'"))
+	Expect(ranPlacement.Filter(ru, "node5")).To(Succeed())
+	Expect(ranPlacement.Filter(ru, "node4")).NotTo(Succeed())
+
+	score, err := ranPlacement.Score(cu, "node2")
+	Expect(err).NotTo(HaveOccurred())
+	Expect(score).To(BeNumerically(">", 0))
+	score, err = ranPlacement.Score(cu, "node5")
+	Expect(err).NotTo(HaveOccurred())
+	Expect(score).To(BeZero())
+
+	// pods of pieces that are not part of a split are not placed
+	Expect(ranPlacement.Filter(generatePod("other", "core"), "node2")).NotTo(Succeed())
+}
+
+func TestReserveAndUnreserve(t *testing.T) {
+	RegisterTestingT(t)
+
+	ranPlacement, c := newTestRANPlacement(t, nil)
+	cu, du := generatePod("cu", controllers.CU), generatePod("du", controllers.DU)
+
+	Expect(ranPlacement.Reserve(cu, "node2")).To(Succeed())
+	Expect(ranPlacement.Filter(cu, "node3")).NotTo(Succeed())
+	Expect(readLedger(c).Chains).To(BeEmpty())
+
+	// the network is reserved once the CU and the DU have nodes
+	Expect(ranPlacement.Reserve(du, "node4")).To(Succeed())
+	Expect(ranPlacement.Reserve(du, "node3")).To(MatchError("du of split 'split1' already reserved in node 'node4'"))
+	ledger := readLedger(c)
+	Expect(ledger.Chains).To(HaveKey(chainKey))
+	Expect(ledger.Chains[chainKey].Core).To(Equal("node1"))
+	Expect(ledger.Chains[chainKey].Links).To(Equal(map[string]float32{"node1--node2": 100, "node2--node4": 100,
+		"node4--node5": 150}))
+	Expect(ledger.Chains[chainKey].Nodes).To(HaveLen(3))
+	Expect(ledger.Chains[chainKey].Nodes).To(HaveKey("node2"))
+	Expect(ledger.Chains[chainKey].Nodes["node4"].CPU.String()).To(Equal(controllers.SplitCPURequestValue))
+
+	// the split is pointed to the core chosen for the chain
+	split := &oaiv1beta1.Split{}
+	Expect(c.Get(context.Background(), types.NamespacedName{Namespace: namespace, Name: "split1"}, split)).
+		To(Succeed())
+	Expect(split.Spec.CoreNode).To(Equal("node1"))
+	Expect(split.Spec.CoreIP).To(Equal("10.0.0.1"))
+	Expect(split.Spec.AMFIP).To(Equal("10.0.0.2"))
+
+	// a second chain does not fit the fronthaul left in node4--node5
+	Expect(c.Create(context.Background(), generateSplit("split2"))).To(Succeed())
+	cu2, du2 := generatePod("cu2", controllers.CU), generatePod("du2", controllers.DU)
+	cu2.Labels[splitOwnerLabel], du2.Labels[splitOwnerLabel] = "split2", "split2"
+	Expect(ranPlacement.Reserve(cu2, "node3")).To(Succeed())
+	Expect(ranPlacement.Reserve(du2, "node4")).To(MatchError("no path available for split 'split2'"))
+
+	// unreserving the DU gives back the chain bandwidth, the CU keeps its node
+	ranPlacement.Unreserve(du, "node4")
+	Expect(readLedger(c).Chains).To(BeEmpty())
+	Expect(ranPlacement.Filter(cu, "node3")).NotTo(Succeed())
+	Expect(ranPlacement.Reserve(du2, "node4")).To(Succeed())
+	Expect(readLedger(c).Chains).To(HaveKey("oai/placer/split2"))
+}
+
+func TestRelease(t *testing.T) {
+	RegisterTestingT(t)
+
+	ranPlacement, c := newTestRANPlacement(t, nil)
+	cu, du, ru := generatePod("cu", controllers.CU), generatePod("du", controllers.DU),
+		generatePod("ru", controllers.RU)
+	Expect(ranPlacement.Reserve(cu, "node2")).To(Succeed())
+	Expect(ranPlacement.Reserve(du, "node4")).To(Succeed())
+	Expect(ranPlacement.Reserve(ru, "node5")).To(Succeed())
+
+	// the pod replacing the CU keeps the reservation, the deleted one does not release it
+	newCU := generatePod("cu-new", controllers.CU)
+	Expect(ranPlacement.Filter(newCU, "node3")).NotTo(Succeed())
+	Expect(ranPlacement.Reserve(newCU, "node2")).To(Succeed())
+	ranPlacement.Release(podKey(cu))
+	Expect(ranPlacement.Filter(newCU, "node3")).NotTo(Succeed())
+
+	// deleting the DU pod gives back the chain bandwidth
+	ranPlacement.Release(podKey(du))
+	Expect(readLedger(c).Chains).To(BeEmpty())
+
+	// the chain is forgotten with its last pod
+	ranPlacement.Release(podKey(newCU))
+	ranPlacement.Release(podKey(ru))
+	Expect(ranPlacement.chains).To(BeEmpty())
+	Expect(ranPlacement.placements).To(BeEmpty())
+}
+
+func TestPlacementReload(t *testing.T) {
+	RegisterTestingT(t)
+
+	// split0 was deleted while the scheduler was not running
+	ranPlacement, c := newTestRANPlacement(t, map[string]*oaiv1beta1.ChainReservation{
+		"oai/placer/split0": {Core: "node1", Links: map[string]float32{"node4--node5": 150}},
+		"oai/other/split0":  {Core: "node1", Links: map[string]float32{"node1--node2": 100}},
+	})
+	cu := generatePod("cu", controllers.CU)
+	Expect(ranPlacement.Filter(cu, "node2")).To(Succeed())
+	Expect(readLedger(c).Chains).To(HaveLen(1))
+	Expect(readLedger(c).Chains).To(HaveKey("oai/other/split0"))
+
+	// the topology edits are used by the next pods
+	cm := &v1.ConfigMap{}
+	Expect(c.Get(context.Background(), types.NamespacedName{Namespace: namespace, Name: "topology"}, cm)).
+		To(Succeed())
+	topology := testTopology.DeepCopy()
+	delete(topology.Links, "node2--node4")
+	cm.Data[topologyDataKey] = marshal(topology)
+	Expect(c.Update(context.Background(), cm)).To(Succeed())
+
+	Expect(ranPlacement.Filter(cu, "node2")).NotTo(Succeed())
+	Expect(ranPlacement.Filter(cu, "node3")).To(Succeed())
+}
+
+// newTestRANPlacement returns the ranPlacement over a cluster with the test topology, the splits placer and split1. The ledger
+// starts with the chains given.
+func newTestRANPlacement(t *testing.T, chains map[string]*oaiv1beta1.ChainReservation) (*RANPlacement, client.Client) {
+	scheme := runtime.NewScheme()
+	if err := oaiv1beta1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := v1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	objects := []runtime.Object{
+		&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "topology"},
+			Data: map[string]string{
+				topologyDataKey: marshal(testTopology),
+				"reservations":  marshal(&oaiv1beta1.TopologyReservations{Chains: chains}),
+			},
+		},
+		&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "operator-system", Name: controllers.DisaggregationConfigMapName},
+			Data:       map[string]string{controllers.DisaggregationKey: marshal(testDisaggregations)},
+		},
+		&oaiv1beta1.SplitsPlacer{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "placer", UID: "placer-uid"},
+			Spec:       oaiv1beta1.SplitsPlacerSpec{TopologyConfig: "topology"},
+		},
+		generateSplit("split1"),
+	}
+	for nodeName := range testTopology.Nodes {
+		resources := v1.ResourceList{
+			v1.ResourceCPU:    *utils.NewQuantity("4"),
+			v1.ResourceMemory: *utils.NewQuantity("8Gi"),
+		}
+		objects = append(objects, &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: nodeName},
+			Status:     v1.NodeStatus{Capacity: resources, Allocatable: resources},
+		})
+	}
+
+	c := fake.NewFakeClientWithScheme(scheme, objects...)
+	return NewRANPlacement(c, ctrllog.NullLogger{}), c
+}
+
+func generateSplit(name string) *oaiv1beta1.Split {
+	controller := true
+	return &oaiv1beta1.Split{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: oaiv1beta1.GroupVersion.String(),
+				Kind: "SplitsPlacer", Name: "placer", UID: "placer-uid", Controller: &controller}},
+		},
+		Spec: oaiv1beta1.SplitSpec{RUNode: "node5"},
+	}
+}
+
+func generatePod(name string, piece controllers.SplitPiece) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: namespace,
+			Name:      name,
+			Labels:    map[string]string{splitLabel: string(piece), splitOwnerLabel: "split1"},
+		},
+		Spec: v1.PodSpec{SchedulerName: Name},
+	}
+}
+
+func generateLink(source, destination string, capacity, delay float32) *oaiv1beta1.Link {
+	return &oaiv1beta1.Link{
+		LinkCapacity: capacity,
+		LinkDelay:    delay,
+		Source:       oaiv1beta1.Connection{Node: source},
+		Destination:  oaiv1beta1.Connection{Node: destination},
+	}
+}
+
+func readLedger(c client.Client) *oaiv1beta1.TopologyReservations {
+	cm, err := controllers.GetTopologyConfigMap(c, types.NamespacedName{Namespace: namespace, Name: "topology"})
+	Expect(err).NotTo(HaveOccurred())
+	reservations, err := controllers.ReadTopologyReservations(cm)
+	Expect(err).NotTo(HaveOccurred())
+
+	return reservations
+}
+
+func podKey(pod *v1.Pod) types.NamespacedName {
+	return types.NamespacedName{Namespace: pod.Namespace, Name: pod.Name}
+}
+
+func marshal(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		panic(fmt.Sprintf("error marshaling test fixture: %s", err))
+	}
+
+	return string(data)
+}
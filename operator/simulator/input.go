@@ -0,0 +1,197 @@
+package simulator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	oaiv1beta1 "github.com/juliorenner/oai-k8s/operator/api/v1beta1"
+	"github.com/juliorenner/oai-k8s/operator/controllers"
+	"github.com/juliorenner/oai-k8s/operator/controllers/utils"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+const (
+	topologyKey        = "topology"
+	disaggregationsKey = "disaggregations"
+)
+
+// NodeCapacity defines the synthetic resources of one node
+type NodeCapacity struct {
+	CPU    string `json:"cpu,omitempty"`
+	Memory string `json:"memory,omitempty"`
+}
+
+// Input has everything required to simulate one placement
+type Input struct {
+	Topology        *oaiv1beta1.Topology
+	Disaggregations map[string]*oaiv1beta1.Disaggregation
+	RUs             []*oaiv1beta1.ChainPosition
+	// Nodes key is the node name
+	Nodes              map[string]*NodeCapacity
+	RequestedResources *utils.RequestedResources
+}
+
+// ReadTopology reads the topology from a JSON file or from a config map manifest like the ones used by the operator.
+func ReadTopology(path string) (*oaiv1beta1.Topology, error) {
+	data, err := readConfigData(path, topologyKey)
+	if err != nil {
+		return nil, err
+	}
+
+	topology := &oaiv1beta1.Topology{}
+	if err := json.Unmarshal(data, topology); err != nil {
+		return nil, fmt.Errorf("error unmarshaling topology: %w", err)
+	}
+
+	return topology, nil
+}
+
+// ReadReservations reads the reservations ledger of a topology config map manifest. Nil is returned if the file is
+// not a config map, as the JSON topologies have no ledger.
+func ReadReservations(path string) (*oaiv1beta1.TopologyReservations, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file '%s': %w", path, err)
+	}
+
+	cm := &v1.ConfigMap{}
+	if err := unmarshal(data, cm); err != nil || cm.Kind != "ConfigMap" {
+		return nil, nil
+	}
+
+	return controllers.ReadTopologyReservations(cm)
+}
+
+// ReadDisaggregations reads the disaggregation catalog from a JSON file or from a config map manifest like the ones
+// used by the operator.
+func ReadDisaggregations(path string) (map[string]*oaiv1beta1.Disaggregation, error) {
+	data, err := readConfigData(path, disaggregationsKey)
+	if err != nil {
+		return nil, err
+	}
+
+	disaggregations := map[string]*oaiv1beta1.Disaggregation{}
+	if err := json.Unmarshal(data, &disaggregations); err != nil {
+		return nil, fmt.Errorf("error unmarshaling disaggregations: %w", err)
+	}
+
+	return disaggregations, nil
+}
+
+// ReadRUs reads the RU list from a JSON list of chain positions or from a SplitsPlacer manifest.
+func ReadRUs(path string) ([]*oaiv1beta1.ChainPosition, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading RUs file: %w", err)
+	}
+
+	var rus []*oaiv1beta1.ChainPosition
+	if err := unmarshal(data, &rus); err == nil {
+		return rus, nil
+	}
+
+	splitsPlacer := &oaiv1beta1.SplitsPlacer{}
+	if err := unmarshal(data, splitsPlacer); err != nil {
+		return nil, fmt.Errorf("error unmarshaling RUs: %w", err)
+	}
+
+	return splitsPlacer.Spec.RUs, nil
+}
+
+// ReadNodes reads the capacity of each node from a JSON file, the key is the node name.
+func ReadNodes(path string) (map[string]*NodeCapacity, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading nodes file: %w", err)
+	}
+
+	nodes := map[string]*NodeCapacity{}
+	if err := unmarshal(data, &nodes); err != nil {
+		return nil, fmt.Errorf("error unmarshaling nodes: %w", err)
+	}
+
+	return nodes, nil
+}
+
+// SyntheticNodes gives the same capacity to every node of the topology that is not described in nodes.
+func SyntheticNodes(topology *oaiv1beta1.Topology, nodes map[string]*NodeCapacity,
+	capacity *NodeCapacity) map[string]*NodeCapacity {
+	synthetic := make(map[string]*NodeCapacity)
+	for nodeName := range topology.Nodes {
+		if node, exists := nodes[nodeName]; exists {
+			synthetic[nodeName] = node
+			continue
+		}
+		synthetic[nodeName] = capacity
+	}
+
+	return synthetic
+}
+
+// nodeList builds the K8S nodes the placement algorithms expect from the synthetic capacities
+func (in *Input) nodeList() (*v1.NodeList, error) {
+	nodeList := &v1.NodeList{}
+	for nodeName, capacity := range in.Nodes {
+		resources, err := capacity.resourceList()
+		if err != nil {
+			return nil, fmt.Errorf("invalid capacity of node '%s': %w", nodeName, err)
+		}
+
+		nodeList.Items = append(nodeList.Items, v1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: nodeName},
+			Status: v1.NodeStatus{
+				Capacity:    resources,
+				Allocatable: resources.DeepCopy(),
+			},
+		})
+	}
+
+	return nodeList, nil
+}
+
+func (c *NodeCapacity) resourceList() (v1.ResourceList, error) {
+	cpu, err := resource.ParseQuantity(c.CPU)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cpu: %w", err)
+	}
+
+	memory, err := resource.ParseQuantity(c.Memory)
+	if err != nil {
+		return nil, fmt.Errorf("invalid memory: %w", err)
+	}
+
+	return v1.ResourceList{v1.ResourceCPU: cpu, v1.ResourceMemory: memory}, nil
+}
+
+// readConfigData returns the content of the key when the file is a config map manifest, otherwise the whole file
+func readConfigData(path string, key string) ([]byte, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading file '%s': %w", path, err)
+	}
+
+	cm := &v1.ConfigMap{}
+	if err := unmarshal(data, cm); err == nil && cm.Kind == "ConfigMap" {
+		content, exists := cm.Data[key]
+		if !exists {
+			return nil, fmt.Errorf("invalid config map '%s'. Key '%s' does not exist", path, key)
+		}
+		return []byte(content), nil
+	}
+
+	return data, nil
+}
+
+// unmarshal decodes YAML or JSON data
+func unmarshal(data []byte, into interface{}) error {
+	jsonData, err := yaml.ToJSON(data)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(jsonData, into)
+}
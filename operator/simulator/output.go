@@ -0,0 +1,81 @@
+package simulator
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+var csvHeader = []string{"execution", "strategy", "state", "requested_rus", "allocated_rus", "allocated_percentage",
+	"hops_count", "average_hops", "runtime"}
+
+// WriteJSON writes the results as a JSON list.
+func WriteJSON(w io.Writer, results []*Result) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(results); err != nil {
+		return fmt.Errorf("error encoding results: %w", err)
+	}
+
+	return nil
+}
+
+// WriteCSV writes one line per result, separated by ';' like the tests harness output. The utilisation of each link
+// follows the summary columns, one column per link.
+func WriteCSV(w io.Writer, results []*Result) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = ';'
+
+	var linkNames []string
+	for _, result := range results {
+		if len(result.Links) > 0 {
+			for _, link := range result.Links {
+				linkNames = append(linkNames, link.Name)
+			}
+			break
+		}
+	}
+
+	if err := writer.Write(append(append([]string{}, csvHeader...), linkNames...)); err != nil {
+		return fmt.Errorf("error writing csv header: %w", err)
+	}
+
+	for _, result := range results {
+		line := []string{
+			strconv.Itoa(result.Execution),
+			result.Strategy,
+			result.State,
+			strconv.Itoa(result.RequestedRUs),
+			strconv.Itoa(result.AllocatedRUs),
+			formatFloat(result.AllocatedPercentage),
+			strconv.Itoa(result.HopsCount),
+			formatFloat(result.AverageHops),
+			formatFloat(result.Runtime),
+		}
+
+		utilisation := make(map[string]float64)
+		for _, link := range result.Links {
+			utilisation[link.Name] = link.Utilisation
+		}
+		for _, linkName := range linkNames {
+			value := ""
+			if v, exists := utilisation[linkName]; exists {
+				value = formatFloat(v)
+			}
+			line = append(line, value)
+		}
+
+		if err := writer.Write(line); err != nil {
+			return fmt.Errorf("error writing csv line: %w", err)
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', 6, 64)
+}
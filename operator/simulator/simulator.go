@@ -0,0 +1,172 @@
+package simulator
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	oaiv1beta1 "github.com/juliorenner/oai-k8s/operator/api/v1beta1"
+	"github.com/juliorenner/oai-k8s/operator/controllers/algorithm"
+	"github.com/juliorenner/oai-k8s/operator/controllers/utils"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	StateFinished = "Finished"
+	StateError    = "Error"
+)
+
+// Placer is a placement strategy that can be simulated
+type Placer interface {
+	utils.Placement
+	// GetRemainingBandwidth key is the link name
+	GetRemainingBandwidth() map[string]*utils.Link
+}
+
+// Explainer is implemented by the placers able to tell why the RUs not allocated were rejected
+type Explainer interface {
+	// GetRejections key is the split name
+	GetRejections() map[string][]*oaiv1beta1.PathRejection
+}
+
+// Strategy creates the placer of one execution
+type Strategy func(in *Input, nodes *v1.NodeList, log logr.Logger) Placer
+
+// Strategies available to the simulator, the key is the name used to select it
+var Strategies = map[string]Strategy{
+	"bfs": func(in *Input, nodes *v1.NodeList, log logr.Logger) Placer {
+		return algorithm.NewPlacementBFS(in.Topology, in.Disaggregations, nodes, nil, in.RequestedResources, log)
+	},
+}
+
+// Result is the outcome of one execution of a strategy
+type Result struct {
+	Execution           int           `json:"execution"`
+	Strategy            string        `json:"strategy"`
+	State               string        `json:"state"`
+	Error               string        `json:"error,omitempty"`
+	RequestedRUs        int           `json:"requestedRUs"`
+	AllocatedRUs        int           `json:"allocatedRUs"`
+	AllocatedPercentage float64       `json:"allocatedPercentage"`
+	HopsCount           int           `json:"hopsCount"`
+	AverageHops         float64       `json:"averageHops"`
+	Runtime             float64       `json:"runtime"`
+	RUs                 []*RUResult   `json:"rus,omitempty"`
+	Links               []*LinkResult `json:"links,omitempty"`
+}
+
+// RUResult is the position found for one RU, the nodes are empty if it was not allocated
+type RUResult struct {
+	SplitName  string                      `json:"splitName"`
+	RUNode     string                      `json:"ruNode"`
+	CoreNode   string                      `json:"coreNode,omitempty"`
+	CUNode     string                      `json:"cuNode,omitempty"`
+	CUUPNode   string                      `json:"cuupNode,omitempty"`
+	DUNode     string                      `json:"duNode,omitempty"`
+	Path       []string                    `json:"path,omitempty"`
+	Hops       int                         `json:"hops"`
+	Rejections []*oaiv1beta1.PathRejection `json:"rejections,omitempty"`
+}
+
+// LinkResult is the bandwidth usage of one link after the placement
+type LinkResult struct {
+	Name        string  `json:"name"`
+	Capacity    float32 `json:"capacity"`
+	Remaining   float32 `json:"remaining"`
+	Utilisation float64 `json:"utilisation"`
+}
+
+// Run executes the strategy over a copy of the input RUs, so the same input can be used in several executions.
+func Run(strategyName string, execution int, in *Input, log logr.Logger) (*Result, error) {
+	strategy, exists := Strategies[strategyName]
+	if !exists {
+		return nil, fmt.Errorf("strategy '%s' does not exist", strategyName)
+	}
+
+	nodes, err := in.nodeList()
+	if err != nil {
+		return nil, err
+	}
+
+	rus := make([]*oaiv1beta1.ChainPosition, len(in.RUs))
+	for i, ru := range in.RUs {
+		rus[i] = ru.DeepCopy()
+	}
+
+	result := &Result{
+		Execution:    execution,
+		Strategy:     strategyName,
+		State:        StateFinished,
+		RequestedRUs: len(rus),
+	}
+
+	placer := strategy(in, nodes, log)
+
+	initialTime := time.Now()
+	_, err = placer.Place(rus)
+	result.Runtime = time.Since(initialTime).Seconds()
+
+	if err != nil {
+		result.State = StateError
+		result.Error = err.Error()
+		return result, nil
+	}
+
+	rejections := map[string][]*oaiv1beta1.PathRejection{}
+	if explainer, ok := placer.(Explainer); ok {
+		rejections = explainer.GetRejections()
+	}
+
+	for _, ru := range rus {
+		ruResult := &RUResult{
+			SplitName: ru.SplitName,
+			RUNode:    ru.RUNode,
+			CoreNode:  ru.CoreNode,
+			CUNode:    ru.CUNode,
+			CUUPNode:  ru.CUUPNode,
+			DUNode:    ru.DUNode,
+			Path:      ru.Path,
+		}
+		if ru.CUNode != "" && ru.DUNode != "" && len(ru.Path) > 0 {
+			ruResult.Hops = len(ru.Path) - 1
+			result.AllocatedRUs++
+			result.HopsCount += ruResult.Hops
+		} else {
+			ruResult.Rejections = rejections[ru.SplitName]
+		}
+		result.RUs = append(result.RUs, ruResult)
+	}
+
+	if result.RequestedRUs > 0 {
+		result.AllocatedPercentage = float64(result.AllocatedRUs) / float64(result.RequestedRUs) * 100
+	}
+	if result.AllocatedRUs > 0 {
+		result.AverageHops = float64(result.HopsCount) / float64(result.AllocatedRUs)
+	}
+
+	result.Links = getLinksResult(in.Topology, placer.GetRemainingBandwidth())
+
+	return result, nil
+}
+
+// getLinksResult returns the usage of every link sorted by name
+func getLinksResult(topology *oaiv1beta1.Topology, remainingBandwidth map[string]*utils.Link) []*LinkResult {
+	var links []*LinkResult
+	for linkName, link := range topology.Links {
+		linkResult := &LinkResult{Name: linkName, Capacity: link.LinkCapacity, Remaining: link.LinkCapacity}
+		if remaining, exists := remainingBandwidth[linkName]; exists {
+			linkResult.Remaining = remaining.AvailableBandwidth
+		}
+		if link.LinkCapacity > 0 {
+			linkResult.Utilisation = float64(link.LinkCapacity-linkResult.Remaining) / float64(link.LinkCapacity)
+		}
+		links = append(links, linkResult)
+	}
+
+	sort.Slice(links, func(i, j int) bool {
+		return links[i].Name < links[j].Name
+	})
+
+	return links
+}
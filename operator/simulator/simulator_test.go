@@ -0,0 +1,65 @@
+package simulator
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+
+	"github.com/juliorenner/oai-k8s/operator/controllers/utils"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+func TestSimulatorRun(t *testing.T) {
+	RegisterTestingT(t)
+
+	topology, err := ReadTopology("../config/samples/bw-min-link-delay.yaml")
+	Expect(err).NotTo(HaveOccurred())
+
+	disaggregations, err := ReadDisaggregations("../config/oai/disaggregations.yaml")
+	Expect(err).NotTo(HaveOccurred())
+
+	rus, err := ReadRUs("../config/samples/oai_v1beta1_splitsplacer.yaml")
+	Expect(err).NotTo(HaveOccurred())
+	Expect(rus).To(HaveLen(8))
+
+	in := &Input{
+		Topology:        topology,
+		Disaggregations: disaggregations,
+		RUs:             rus,
+		Nodes:           SyntheticNodes(topology, nil, &NodeCapacity{CPU: "4", Memory: "8Gi"}),
+		RequestedResources: &utils.RequestedResources{
+			Memory: *utils.NewQuantity("256Mi"),
+			CPU:    *utils.NewQuantity("150m"),
+		},
+	}
+
+	result, err := Run("bfs", 0, in, zap.New(zap.UseDevMode(true)))
+	Expect(err).NotTo(HaveOccurred())
+	Expect(result.State).To(Equal(StateFinished))
+	Expect(result.RequestedRUs).To(Equal(8))
+	Expect(result.AllocatedRUs).To(BeNumerically(">", 0))
+	Expect(result.Links).To(HaveLen(len(topology.Links)))
+	for _, ru := range result.RUs {
+		if ru.CUNode != "" {
+			Expect(ru.Hops).To(Equal(len(ru.Path) - 1))
+		}
+	}
+
+	// the input is kept untouched so it can be reused
+	for _, ru := range in.RUs {
+		Expect(ru.CUNode).To(BeEmpty())
+	}
+
+	_, err = Run("unknown", 0, in, zap.New(zap.UseDevMode(true)))
+	Expect(err).To(HaveOccurred())
+
+	output := &bytes.Buffer{}
+	Expect(WriteCSV(output, []*Result{result})).To(Succeed())
+	reader := csv.NewReader(output)
+	reader.Comma = ';'
+	lines, err := reader.ReadAll()
+	Expect(err).NotTo(HaveOccurred())
+	Expect(lines).To(HaveLen(2))
+	Expect(lines[0]).To(HaveLen(len(csvHeader) + len(topology.Links)))
+}
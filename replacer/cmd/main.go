@@ -17,6 +17,12 @@ const (
 	resultFilePath   = "/config/config.conf"
 
 	splitPieceEnvVar = "SplitPiece"
+	ratEnvVar        = "RAT"
+
+	ratNR = "NR"
+
+	// anyAddress is used for the peers south of the piece not started yet, they associate to the piece
+	anyAddress = "0.0.0.0"
 )
 
 type values struct {
@@ -24,15 +30,31 @@ type values struct {
 	SouthAddress string `yaml:"southaddress,omitempty"`
 	NorthAddress string `yaml:"northaddress,omitempty"`
 	UPFAddress   string `yaml:"upfaddress,omitempty"`
+	AMFAddress   string `yaml:"amfaddress,omitempty"`
+	CUUPAddress  string `yaml:"cuupaddress,omitempty"`
+	CUCPAddress  string `yaml:"cucpaddress,omitempty"`
+	Radio        radio  `yaml:"radio,omitempty"`
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "probe" {
+		probe()
+		return
+	}
+
 	logrus.Info("Starting replacer")
 	v := &values{}
 	splitPiece := os.Getenv(splitPieceEnvVar)
+	rat := os.Getenv(ratEnvVar)
 	switch splitPiece {
 	case "cu":
-		getCUContent(v)
+		if rat == ratNR {
+			getNRCUContent(v)
+		} else {
+			getCUContent(v)
+		}
+	case "cuup":
+		getCUUPContent(v)
 	case "du":
 		getDUContent(v)
 	case "ru":
@@ -41,7 +63,9 @@ func main() {
 		logrus.Fatalf("Split '%s' is not valid", splitPiece)
 	}
 
-	logrus.Infof("split piece is '%s'", splitPiece)
+	logrus.Infof("split piece is '%s', rat is '%s'", splitPiece, rat)
+
+	setSouthAddresses(v)
 
 	if err := replacer(v); err != nil {
 		logrus.Fatalf("error replacing values: %s", err)
@@ -51,15 +75,32 @@ func main() {
 }
 
 func getCUContent(cu *values) {
-	for cu.LocalAddress == "" || cu.UPFAddress == "" || cu.SouthAddress == "" {
+	for cu.LocalAddress == "" || cu.UPFAddress == "" {
 		if err := loadFile(cu); err != nil {
 			logrus.Fatalf("error loading file for CU split: %s", err)
 		}
 	}
 }
 
+// getNRCUContent waits for the values of the 5G CU-CP, which talks to the AMF
+func getNRCUContent(cu *values) {
+	for cu.LocalAddress == "" || cu.AMFAddress == "" {
+		if err := loadFile(cu); err != nil {
+			logrus.Fatalf("error loading file for CU-CP split: %s", err)
+		}
+	}
+}
+
+func getCUUPContent(cuup *values) {
+	for cuup.LocalAddress == "" || cuup.UPFAddress == "" || cuup.CUCPAddress == "" {
+		if err := loadFile(cuup); err != nil {
+			logrus.Fatalf("error loading file for CU-UP split: %s", err)
+		}
+	}
+}
+
 func getDUContent(du *values) {
-	for du.LocalAddress == "" || du.NorthAddress == "" || du.SouthAddress == "" {
+	for du.LocalAddress == "" || du.NorthAddress == "" {
 		if err := loadFile(du); err != nil {
 			logrus.Fatalf("error loading file for DU split: %s", err)
 		}
@@ -74,6 +115,17 @@ func getRUContent(ru *values) {
 	}
 }
 
+// setSouthAddresses sets the addresses of the peers not started yet. The chain is started from the CU to the RU, so
+// the piece does not wait for the peers south of it.
+func setSouthAddresses(v *values) {
+	if v.SouthAddress == "" {
+		v.SouthAddress = anyAddress
+	}
+	if v.CUUPAddress == "" {
+		v.CUUPAddress = anyAddress
+	}
+}
+
 func loadFile(v *values) error {
 	yamlFile, err := ioutil.ReadFile(configValuesPath)
 	if err != nil {
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	probePortEnvVar    = "ProbePort"
+	probeProcessEnvVar = "ProbeProcess"
+	probeSocketsEnvVar = "ProbeSockets"
+
+	defaultProbePort = "8086"
+	// commLength is the length of the process names kept by the kernel in /proc/<pid>/comm
+	commLength = 15
+
+	socketBound      = "bound"
+	socketListening  = "listening"
+	socketAssociated = "associated"
+)
+
+// socketTables are the /proc/net files listing the sockets and the columns of their local and remote ports. The
+// ports of the udp and tcp files are the hexadecimal suffix of the address columns.
+var socketTables = []struct {
+	path        string
+	localPort   int
+	remotePort  int
+	hexadecimal bool
+}{
+	{"/proc/net/udp", 1, 2, true},
+	{"/proc/net/udp6", 1, 2, true},
+	{"/proc/net/tcp", 1, 2, true},
+	{"/proc/net/tcp6", 1, 2, true},
+	{"/proc/net/sctp/eps", 5, -1, false},
+	{"/proc/net/sctp/assocs", 11, 12, false},
+}
+
+type socketCheck struct {
+	state string
+	port  int
+}
+
+// probe serves the liveness and readiness endpoints of the piece, it runs as a sidecar sharing the process and network
+// namespaces of the softmodem
+func probe() {
+	port := os.Getenv(probePortEnvVar)
+	if port == "" {
+		port = defaultProbePort
+	}
+	process := os.Getenv(probeProcessEnvVar)
+	checks, err := parseSocketChecks(os.Getenv(probeSocketsEnvVar))
+	if err != nil {
+		logrus.Fatalf("error parsing sockets: %s", err)
+	}
+
+	http.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		respond(w, processAlive(process))
+	})
+	http.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if err := processAlive(process); err != nil {
+			respond(w, err)
+			return
+		}
+		respond(w, socketsReady(checks))
+	})
+
+	logrus.Infof("serving probes of process '%s' in port %s", process, port)
+	logrus.Fatal(http.ListenAndServe(":"+port, nil))
+}
+
+func respond(w http.ResponseWriter, err error) {
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	fmt.Fprintln(w, "ok")
+}
+
+// parseSocketChecks parses the comma separated list of <state>:<port>
+func parseSocketChecks(value string) ([]socketCheck, error) {
+	var checks []socketCheck
+	for _, item := range strings.Split(value, ",") {
+		if item == "" {
+			continue
+		}
+
+		parts := strings.Split(item, ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid socket '%s'", item)
+		}
+		port, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid port in socket '%s': %w", item, err)
+		}
+		switch parts[0] {
+		case socketBound, socketListening, socketAssociated:
+		default:
+			return nil, fmt.Errorf("invalid state in socket '%s'", item)
+		}
+
+		checks = append(checks, socketCheck{state: parts[0], port: port})
+	}
+
+	return checks, nil
+}
+
+// processAlive checks a process with the name is running
+func processAlive(name string) error {
+	if len(name) > commLength {
+		name = name[:commLength]
+	}
+
+	comms, err := filepath.Glob("/proc/[0-9]*/comm")
+	if err != nil {
+		return fmt.Errorf("error listing processes: %w", err)
+	}
+
+	for _, comm := range comms {
+		content, err := ioutil.ReadFile(comm)
+		if err != nil {
+			// the process finished
+			continue
+		}
+		if strings.TrimSpace(string(content)) == name {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("process '%s' is not running", name)
+}
+
+// socketsReady checks every socket is in the expected state
+func socketsReady(checks []socketCheck) error {
+	local, remote, listening, err := readSockets()
+	if err != nil {
+		return err
+	}
+
+	for _, check := range checks {
+		ready := false
+		switch check.state {
+		case socketBound:
+			ready = local[check.port]
+		case socketListening:
+			ready = listening[check.port]
+		case socketAssociated:
+			ready = remote[check.port]
+		}
+
+		if !ready {
+			return fmt.Errorf("socket %s:%d not ready", check.state, check.port)
+		}
+	}
+
+	return nil
+}
+
+// readSockets returns the local ports bound, the remote ports associated through SCTP and the local ports listening
+// to SCTP associations
+func readSockets() (local, remote, listening map[int]bool, err error) {
+	local, remote, listening = map[int]bool{}, map[int]bool{}, map[int]bool{}
+	for _, table := range socketTables {
+		file, err := os.Open(table.path)
+		if os.IsNotExist(err) {
+			// the protocol is not loaded
+			continue
+		}
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("error opening %s: %w", table.path, err)
+		}
+
+		scanner := bufio.NewScanner(file)
+		// skip the header
+		scanner.Scan()
+		for scanner.Scan() {
+			fields := strings.Fields(scanner.Text())
+			if port, ok := parsePort(fields, table.localPort, table.hexadecimal); ok {
+				local[port] = true
+				if strings.HasSuffix(table.path, "eps") {
+					listening[port] = true
+				}
+			}
+			if port, ok := parsePort(fields, table.remotePort, table.hexadecimal); ok &&
+				strings.HasSuffix(table.path, "assocs") {
+				remote[port] = true
+			}
+		}
+		file.Close()
+
+		if err := scanner.Err(); err != nil {
+			return nil, nil, nil, fmt.Errorf("error reading %s: %w", table.path, err)
+		}
+	}
+
+	return local, remote, listening, nil
+}
+
+func parsePort(fields []string, column int, hexadecimal bool) (int, bool) {
+	if column < 0 || column >= len(fields) {
+		return 0, false
+	}
+
+	value := fields[column]
+	base := 10
+	if hexadecimal {
+		value = value[strings.LastIndex(value, ":")+1:]
+		base = 16
+	}
+
+	port, err := strconv.ParseInt(value, base, 32)
+	if err != nil {
+		return 0, false
+	}
+
+	return int(port), true
+}
@@ -0,0 +1,81 @@
+package main
+
+import "fmt"
+
+// nrBWPSize is the maximum number of resource blocks of a NR bandwidth part
+const nrBWPSize = 275
+
+type plmn struct {
+	MCC string `yaml:"mcc"`
+	MNC string `yaml:"mnc"`
+}
+
+type radio struct {
+	PLMNs     []plmn `yaml:"plmns"`
+	CellID    int64  `yaml:"cellid"`
+	PCI       int32  `yaml:"pci"`
+	TAC       int32  `yaml:"tac"`
+	Band      int32  `yaml:"band"`
+	Bandwidth int32  `yaml:"bandwidth"`
+	EARFCN    int64  `yaml:"earfcn"`
+	TxGain    int32  `yaml:"txgain"`
+}
+
+// lteBand keeps the values of TS 36.101 table 5.7.3-1 used to get the carrier frequencies from the EARFCN
+type lteBand struct {
+	// downlinkLow is the lowest downlink frequency of the band in Hz
+	downlinkLow int64
+	// downlinkOffset is the EARFCN of the lowest downlink frequency
+	downlinkOffset int64
+	// uplinkOffset is the uplink frequency minus the downlink frequency in Hz, 0 in TDD bands
+	uplinkOffset int64
+}
+
+var lteBands = map[int32]lteBand{
+	1:  {2110000000, 0, -190000000},
+	3:  {1805000000, 1200, -95000000},
+	7:  {2620000000, 2750, -120000000},
+	8:  {925000000, 3450, -45000000},
+	20: {791000000, 6150, 41000000},
+	28: {758000000, 9210, -55000000},
+	38: {2570000000, 37750, 0},
+	40: {2300000000, 38650, 0},
+	41: {2496000000, 39650, 0},
+	42: {3400000000, 41590, 0},
+	43: {3600000000, 43590, 0},
+}
+
+// DownlinkFrequency returns the LTE downlink carrier frequency in Hz
+func (r radio) DownlinkFrequency() (int64, error) {
+	band, exists := lteBands[r.Band]
+	if !exists {
+		return 0, fmt.Errorf("band %d is not supported", r.Band)
+	}
+
+	return band.downlinkLow + 100000*(r.EARFCN-band.downlinkOffset), nil
+}
+
+// UplinkFrequencyOffset returns the LTE uplink carrier frequency minus the downlink one in Hz
+func (r radio) UplinkFrequencyOffset() (int64, error) {
+	band, exists := lteBands[r.Band]
+	if !exists {
+		return 0, fmt.Errorf("band %d is not supported", r.Band)
+	}
+
+	return band.uplinkOffset, nil
+}
+
+// PointA returns the NR-ARFCN of the lowest subcarrier of the NR carrier. The SSB is in the middle of the carrier, with
+// 30 kHz subcarriers and the 15 kHz raster of the FR1 bands above 3 GHz.
+func (r radio) PointA() int64 {
+	return r.EARFCN - int64(r.Bandwidth)*12
+}
+
+// LocationAndBandwidth returns the RIV of the NR initial bandwidth part using the whole carrier
+func (r radio) LocationAndBandwidth() int32 {
+	if r.Bandwidth-1 <= nrBWPSize/2 {
+		return nrBWPSize * (r.Bandwidth - 1)
+	}
+
+	return nrBWPSize*(nrBWPSize-r.Bandwidth+1) + nrBWPSize - 1
+}
@@ -127,12 +127,15 @@ class SplitsPlacer:
         links_bandwidth = splitsplacer["status"]["remainingBandwidth"]
         creation_timestamp = splitsplacer["metadata"]["creationTimestamp"]
 
-        hops_count = {}
-        for ru in splitsplacer["spec"]["rus"]:
-            if "path" not in ru or len(ru["path"]) == 0:
-                continue
-            hops = len(ru["path"])-1
-            hops_count[ru["splitName"]] = hops
+        # operators reporting the chains in the status already count the hops
+        hops_count = {chain["splitName"]: chain["hops"]
+                      for chain in splitsplacer["status"].get("chains", [])}
+        if not hops_count:
+            for ru in splitsplacer["spec"]["rus"]:
+                if "path" not in ru or len(ru["path"]) == 0:
+                    continue
+                hops = len(ru["path"])-1
+                hops_count[ru["splitName"]] = hops
 
         average_hops = sum(hops_count.values())/len(hops_count)
 